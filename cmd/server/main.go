@@ -1,12 +1,26 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"noteme/internal/ai"
 	"noteme/internal/api"
+	"noteme/internal/audio"
 	"noteme/internal/config"
 	"noteme/internal/db"
+	"noteme/internal/events"
+	"noteme/internal/export"
+	"noteme/internal/metrics"
 	"noteme/internal/repository"
+	"noteme/internal/storage"
+	"noteme/internal/stt"
+	"noteme/internal/tracing"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -23,6 +37,48 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	if os.Getenv("STT_PROVIDER") == "" {
+		log.Printf("STT_PROVIDER not set, using %s (first configured provider in STT_PROVIDER_ORDER=%s)",
+			cfg.STTProvider, strings.Join(cfg.STTProviderOrder, ","))
+	}
+
+	// Hand the validated settings to the packages that use them
+	ai.Configure(cfg)
+	stt.Configure(cfg)
+	api.Configure(cfg)
+	audio.SetMinBytes(cfg.MinAudioBytes)
+	storage.SetLimits(cfg.StorageMaxEntries, cfg.ResumableUploadTTL)
+	events.SetWebhookSecret(cfg.WebhookSecret)
+	export.SetPDFFontPath(cfg.PDFFontPath)
+
+	// Uploads are written to UPLOAD_DIR; a missing or read-only directory must not serve traffic
+	if err := storage.InitUploadDir(cfg.UploadDir); err != nil {
+		log.Fatalf("Failed to prepare upload directory: %v", err)
+	}
+
+	// AI features need an OpenAI key; warn now instead of failing on the first AI request
+	if cfg.AIProvider == "mock" {
+		ai.SetAnalyzer(ai.MockAnalyzer{})
+	}
+	switch {
+	case !cfg.EnableAI:
+		log.Println("AI features disabled (ENABLE_AI=false): AI endpoints will return 503 AI_NOT_CONFIGURED and transcripts are not cleaned")
+	case cfg.AIProvider == "mock":
+		log.Println("Using mock AI (AI_PROVIDER=mock): cleaning and analysis are rule-based, other AI endpoints still need OPENAI_API_KEY")
+	case cfg.OpenAIKey == "":
+		log.Println("WARNING: OPENAI_API_KEY is not set: AI endpoints will return 503 AI_NOT_CONFIGURED and transcripts are not cleaned. Set OPENAI_API_KEY or ENABLE_AI=false")
+	}
+
+	// Cleaning prompts can be overridden from CLEAN_PROMPT_DIR; a broken template must not serve traffic
+	if err := ai.LoadCleanPrompts(); err != nil {
+		log.Fatalf("Failed to load prompt templates: %v", err)
+	}
+	if err := ai.LoadPromptExperiment(); err != nil {
+		log.Fatalf("Failed to load prompt experiment: %v", err)
+	}
+	if err := ai.LoadGlossary(); err != nil {
+		log.Fatalf("Failed to load glossary: %v", err)
+	}
 
 	// Set Gin mode (default to release mode)
 	if os.Getenv("GIN_MODE") == "" {
@@ -32,9 +88,14 @@ func main() {
 	// Initialize database if DATABASE_URL is provided
 	if cfg.DatabaseURL != "" {
 		log.Printf("Initializing database connection with DATABASE_URL...")
-		if err := db.Init(); err != nil {
+		if err := db.Init(cfg); err != nil {
 			log.Printf("Warning: Failed to initialize database: %v. Continuing without database.", err)
 		} else {
+			// Apply schema migrations; a failed migration must not serve traffic
+			if err := db.Migrate(context.Background()); err != nil {
+				log.Fatalf("Failed to run database migrations: %v", err)
+			}
+
 			// Initialize repository
 			log.Printf("Creating PostgreSQL repository...")
 			repo := repository.NewPostgresRepository()
@@ -49,30 +110,103 @@ func main() {
 		log.Println("DATABASE_URL not set, running without database (in-memory storage only)")
 	}
 
+	// OpenTelemetry tracing (no-op unless an OTLP endpoint is configured)
+	shutdownTracing, err := tracing.Init(context.Background())
+	if err != nil {
+		log.Printf("Warning: Failed to initialize tracing: %v. Continuing without tracing.", err)
+	}
+
 	r := gin.Default()
+	r.Use(tracing.GinMiddleware())
+
+	// Cap request bodies before any handler parses them
+	r.Use(api.BodyLimitMiddleware(cfg.MaxBodyBytes, cfg.MaxUploadBodyBytes))
+
+	// Prometheus metrics for HTTP, STT and OpenAI usage
+	metrics.Register()
+	r.Use(metrics.GinMiddleware())
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	// Add CORS middleware for mobile app
-	r.Use(corsMiddleware())
+	r.Use(corsMiddleware(cfg))
+
+	// Compress JSON responses for clients that accept gzip
+	if cfg.EnableGzip {
+		r.Use(api.GzipMiddleware())
+	}
 
 	// Register routes
 	api.RegisterRoutes(r)
 
-	log.Printf("NoteMe backend running on :%s", cfg.Port)
-	if err := r.Run(":" + cfg.Port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	srv := &http.Server{
+		Addr:    ":" + cfg.Port,
+		Handler: r,
+	}
+
+	// Stop accepting requests on SIGINT/SIGTERM and drain in-flight ones
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		log.Printf("NoteMe backend running on :%s", cfg.Port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// Delete recordings past RETENTION_DAYS (or a user's override) on a schedule
+	api.StartRetentionJob(ctx, api.RetentionOptions{
+		Days:     cfg.RetentionDays,
+		Purge:    cfg.RetentionPurge,
+		Interval: cfg.RetentionInterval,
+	})
+
+	<-ctx.Done()
+	stop()
+	log.Printf("[Shutdown] Signal received, draining in-flight requests (grace period %v)...", cfg.ShutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("[Shutdown] Grace period exceeded, forcing close: %v", err)
+		srv.Close()
+	} else {
+		log.Printf("[Shutdown] All requests drained")
+	}
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Printf("[Shutdown] Failed to flush traces: %v", err)
 	}
+
+	if err := db.Close(); err != nil {
+		log.Printf("[Shutdown] Failed to close database: %v", err)
+	} else {
+		log.Printf("[Shutdown] Database connection closed")
+	}
+
+	log.Printf("[Shutdown] Server stopped")
 }
 
 // corsMiddleware adds CORS headers for mobile app and Flutter web
-func corsMiddleware() gin.HandlerFunc {
+// Allowed methods and headers come from CORS_ALLOW_METHODS / CORS_ALLOW_HEADERS
+func corsMiddleware(cfg *config.Config) gin.HandlerFunc {
+	allowMethods := strings.Join(cfg.CORSAllowMethods, ", ")
+	allowHeaders := strings.Join(cfg.CORSAllowHeaders, ", ")
+	exposeHeaders := strings.Join(cfg.CORSExposeHeaders, ", ")
+
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-User-ID")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+		c.Writer.Header().Set("Access-Control-Allow-Methods", allowMethods)
+		if exposeHeaders != "" {
+			c.Writer.Header().Set("Access-Control-Expose-Headers", exposeHeaders)
+		}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		// Answer preflight requests for every route, including PATCH endpoints
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
 
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"noteme/internal/api"
+	"noteme/internal/config"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCORSPreflightForPatchRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(corsMiddleware(config.Default()))
+	api.RegisterRoutes(r)
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/stt/6f1c1a2e-9a4b-4a7e-8a55-2f6f0f1d9c01/title", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPatch)
+	req.Header.Set("Access-Control-Request-Headers", "Content-Type, X-API-Key")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("preflight status %d, want %d (body %s)", w.Code, http.StatusNoContent, w.Body.String())
+	}
+	methods := w.Header().Get("Access-Control-Allow-Methods")
+	for _, method := range []string{http.MethodPatch, http.MethodOptions} {
+		if !strings.Contains(methods, method) {
+			t.Errorf("Access-Control-Allow-Methods = %q, want it to include %s", methods, method)
+		}
+	}
+	headers := w.Header().Get("Access-Control-Allow-Headers")
+	for _, header := range []string{"Content-Type", "X-API-Key"} {
+		if !strings.Contains(headers, header) {
+			t.Errorf("Access-Control-Allow-Headers = %q, want it to include %s", headers, header)
+		}
+	}
+}
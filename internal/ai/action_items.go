@@ -0,0 +1,89 @@
+package ai
+
+import (
+	"strings"
+	"unicode"
+)
+
+// actionItemSimilarityThreshold is the word-overlap ratio above which two action items are merged
+const actionItemSimilarityThreshold = 0.8
+
+// MergedActionItem is an action item deduplicated across recordings
+type MergedActionItem struct {
+	Text         string   // representative wording (first occurrence)
+	RecordingIDs []string // recordings the item came from
+}
+
+// MergeActionItems deduplicates near-identical action items across analyses.
+// Items are compared by normalized text, then by word overlap (Jaccard) so small
+// wording differences ("Gửi báo giá." vs "gửi báo giá") collapse into one entry.
+func MergeActionItems(analyses []AnalysisContext) []MergedActionItem {
+	var merged []MergedActionItem
+	var mergedTokens []map[string]bool
+
+	for _, analysis := range analyses {
+		for _, item := range analysis.ActionItems {
+			text := strings.TrimSpace(item)
+			if text == "" {
+				continue
+			}
+			tokens := actionItemTokens(text)
+
+			idx := -1
+			for i, existing := range mergedTokens {
+				if jaccard(tokens, existing) >= actionItemSimilarityThreshold {
+					idx = i
+					break
+				}
+			}
+
+			if idx < 0 {
+				merged = append(merged, MergedActionItem{Text: text, RecordingIDs: []string{analysis.RecordingID}})
+				mergedTokens = append(mergedTokens, tokens)
+				continue
+			}
+
+			if !containsString(merged[idx].RecordingIDs, analysis.RecordingID) {
+				merged[idx].RecordingIDs = append(merged[idx].RecordingIDs, analysis.RecordingID)
+			}
+		}
+	}
+
+	return merged
+}
+
+// actionItemTokens lowercases and splits text into a set of words, ignoring punctuation
+func actionItemTokens(text string) map[string]bool {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
+
+// jaccard returns |a ∩ b| / |a ∪ b|
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	intersection := 0
+	for w := range a {
+		if b[w] {
+			intersection++
+		}
+	}
+	union := len(a) + len(b) - intersection
+	return float64(intersection) / float64(union)
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
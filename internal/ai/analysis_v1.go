@@ -0,0 +1,144 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Contexts of the V1 schema
+const (
+	ContextV1Meeting  = "MEETING"
+	ContextV1Thinking = "THINKING"
+	ContextV1Lecture  = "LECTURE"
+)
+
+// AnalysisResultV1 is an analysis in the structured Prompt Engine v1 schema (see BuildPromptV1)
+type AnalysisResultV1 struct {
+	Context         string            `json:"context"` // MEETING, THINKING or LECTURE
+	ConfidenceScore float64           `json:"confidence_score"`
+	Content         AnalysisContentV1 `json:"content"`
+	ZaloBrief       string            `json:"zalo_brief"`
+	Truncated       bool              `json:"truncated,omitempty"` // only the start of the transcript was analyzed
+}
+
+// AnalysisContentV1 is the content section of a V1 analysis
+type AnalysisContentV1 struct {
+	Summary     string         `json:"summary"`
+	ActionItems []ActionItemV1 `json:"action_items"`
+	KeyIdeas    []string       `json:"key_ideas"`
+}
+
+// ActionItemV1 is a structured action item; Assignee and Deadline are empty when not mentioned
+type ActionItemV1 struct {
+	Task     string `json:"task"`
+	Assignee string `json:"assignee"`
+	Deadline string `json:"deadline"`
+}
+
+// AnalyzeTranscriptV1 analyzes a transcript with BuildPromptV1. Transcripts over
+// ANALYSIS_MAX_TRANSCRIPT_TOKENS are truncated rather than chunked, since the V1
+// summary is a single paragraph.
+func AnalyzeTranscriptV1(ctx context.Context, transcript string) (*AnalysisResultV1, error) {
+	apiKey, err := openAIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if fastCleanEnabled(ctx) {
+		transcript = fastClean("analysis v1", transcript)
+	}
+
+	truncated := false
+	if maxTokens := analysisMaxTokens(); EstimateTokens(transcript) > maxTokens {
+		log.Printf("WARNING: Transcript has ~%d tokens (limit %d), truncating for V1 analysis", EstimateTokens(transcript), maxTokens)
+		transcript, truncated = SplitTranscript(transcript, maxTokens)[0], true
+	}
+
+	systemPrompt, userPrompt := BuildPromptV1(transcript)
+
+	ctx, span := startSpan(ctx, "ai.AnalyzeTranscriptV1", attribute.Int("ai.transcript_length", len(transcript)))
+	defer span.End()
+	ctx, cancel := withOpenAITimeout(ctx)
+	defer cancel()
+
+	req := openai.ChatCompletionRequest{
+		Model: conf.OpenAIModel,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: systemPrompt},
+			{Role: openai.ChatMessageRoleUser, Content: userPrompt},
+		},
+		Temperature: 0.3,
+		ResponseFormat: &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		},
+	}
+	applyGenerationParams(ctx, &req)
+
+	client := openai.NewClient(apiKey)
+	resp, err := client.CreateChatCompletion(ctx, req)
+	if err != nil {
+		log.Printf("OpenAI API error during V1 analysis: %v", err)
+		err = wrapOpenAIError(err)
+		recordSpanError(span, err)
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("OpenAI returned no choices")
+	}
+	recordUsage("analyze_v1", resp.Usage)
+
+	content := resp.Choices[0].Message.Content
+	var result AnalysisResultV1
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
+		if err := json.Unmarshal([]byte(extractJSONFromMarkdown(content)), &result); err != nil {
+			return nil, fmt.Errorf("failed to parse OpenAI response as JSON: %w", err)
+		}
+	}
+
+	normalizeAnalysisV1(&result, transcript)
+	result.Truncated = truncated
+	return &result, nil
+}
+
+// normalizeAnalysisV1 fills what the model left out so clients always get the full schema
+func normalizeAnalysisV1(result *AnalysisResultV1, transcript string) {
+	result.Context = strings.ToUpper(strings.TrimSpace(result.Context))
+	switch result.Context {
+	case ContextV1Meeting, ContextV1Thinking, ContextV1Lecture:
+	default:
+		// Map the rule-based detector onto the V1 contexts
+		switch DetectContext(transcript) {
+		case "meeting":
+			result.Context = ContextV1Meeting
+		case "lecture":
+			result.Context = ContextV1Lecture
+		default:
+			result.Context = ContextV1Thinking
+		}
+	}
+
+	if result.ConfidenceScore < 0 {
+		result.ConfidenceScore = 0
+	} else if result.ConfidenceScore > 1 {
+		result.ConfidenceScore = 1
+	}
+
+	items := make([]ActionItemV1, 0, len(result.Content.ActionItems))
+	for _, item := range result.Content.ActionItems {
+		item.Task = strings.TrimSpace(item.Task)
+		if item.Task == "" {
+			continue
+		}
+		item.Assignee = strings.TrimSpace(item.Assignee)
+		item.Deadline = strings.TrimSpace(item.Deadline)
+		items = append(items, item)
+	}
+	result.Content.ActionItems = items
+	result.Content.KeyIdeas = nonNilStrings(result.Content.KeyIdeas)
+}
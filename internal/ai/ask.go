@@ -4,21 +4,36 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
+	"regexp"
 	"strings"
 
 	"github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-// AskAnything answers questions based on all analyzed data
-func AskAnything(question string, allAnalyses []AnalysisContext) (string, error) {
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
-		return "", fmt.Errorf("OPENAI_API_KEY environment variable is not set")
+// AskResult is an Ask Anything answer with the recordings it was drawn from
+type AskResult struct {
+	Answer string
+	// Sources are the recording IDs the model cited, or every recording in the context when it cited none
+	Sources []string
+	// Cited reports whether Sources came from the model's citations rather than the context
+	Cited bool
+}
+
+// sourceTagPattern matches the "[nguồn: rec_1, rec_2]" tags the model is asked to append
+var sourceTagPattern = regexp.MustCompile(`\s*\[(?i:nguồn|source)s?:\s*([^\]]*)\]`)
+
+// AskAnything answers questions based on all analyzed data.
+// cacheKey identifies the user and analyses version so the built context can be reused; pass "" to skip caching.
+// The call is bounded by OPENAI_TIMEOUT.
+func AskAnything(ctx context.Context, question string, allAnalyses []AnalysisContext, cacheKey string) (*AskResult, error) {
+	apiKey, err := openAIKey()
+	if err != nil {
+		return nil, err
 	}
 
 	if len(allAnalyses) == 0 {
-		return "", fmt.Errorf("no analysis data available to answer the question")
+		return nil, fmt.Errorf("no analysis data available to answer the question")
 	}
 
 	log.Printf("=== Ask Anything Request ===")
@@ -26,7 +41,7 @@ func AskAnything(question string, allAnalyses []AnalysisContext) (string, error)
 	log.Printf("Number of analyses: %d", len(allAnalyses))
 
 	// Build context from all analyses
-	contextText := buildContextFromAnalyses(allAnalyses)
+	contextText := cachedContextFromAnalyses(cacheKey, allAnalyses)
 	log.Printf("Context length: %d characters", len(contextText))
 
 	// Build prompt
@@ -38,6 +53,7 @@ NGUYÊN TẮC:
 - Nếu không có thông tin, hãy nói rõ "Không tìm thấy thông tin trong dữ liệu đã ghi"
 - Trả lời ngắn gọn, rõ ràng, bằng TIẾNG VIỆT
 - Không chat dài, không roleplay, chỉ trả lời trực tiếp
+- Cuối mỗi ý lấy từ dữ liệu, ghi nguồn bằng ID ghi âm dạng [nguồn: <ID>] (nhiều nguồn: [nguồn: <ID1>, <ID2>])
 
 QUAN TRỌNG VỀ NGÔN NGỮ:
 - TẤT CẢ câu trả lời phải bằng TIẾNG VIỆT
@@ -57,11 +73,14 @@ Hãy trả lời câu hỏi dựa trên dữ liệu trên. Nếu không có thô
 	client := openai.NewClient(apiKey)
 
 	// Call OpenAI API
-	ctx := context.Background()
+	ctx, span := startSpan(ctx, "ai.AskAnything", attribute.Int("ai.analyses", len(allAnalyses)))
+	defer span.End()
+	ctx, cancel := withOpenAITimeout(ctx)
+	defer cancel()
 	log.Printf("Calling OpenAI API to answer question...")
 
 	req := openai.ChatCompletionRequest{
-		Model: openai.GPT4oMini,
+		Model: conf.OpenAIModel,
 		Messages: []openai.ChatCompletionMessage{
 			{
 				Role:    openai.ChatMessageRoleSystem,
@@ -75,24 +94,59 @@ Hãy trả lời câu hỏi dựa trên dữ liệu trên. Nếu không có thô
 		Temperature: 0.3, // Low temperature for factual answers
 		MaxTokens:   500, // Limit response length
 	}
+	applyGenerationParams(ctx, &req)
 
 	resp, err := client.CreateChatCompletion(ctx, req)
 	if err != nil {
 		log.Printf("OpenAI API error while answering: %v", err)
-		return "", fmt.Errorf("OpenAI API error: %w", err)
+		err = wrapOpenAIError(err)
+		recordSpanError(span, err)
+		return nil, err
 	}
 
 	if len(resp.Choices) == 0 {
-		return "", fmt.Errorf("OpenAI returned no choices")
+		return nil, fmt.Errorf("OpenAI returned no choices")
 	}
 
+	recordUsage("ask", resp.Usage)
 	answer := strings.TrimSpace(resp.Choices[0].Message.Content)
 	log.Printf("OpenAI answer received (length: %d)", len(answer))
 	log.Printf("Usage - Prompt tokens: %d, Completion tokens: %d, Total tokens: %d",
 		resp.Usage.PromptTokens, resp.Usage.CompletionTokens, resp.Usage.TotalTokens)
 	log.Printf("Answer: %s", answer)
 
-	return answer, nil
+	return attributeSources(answer, allAnalyses), nil
+}
+
+// attributeSources strips the source tags from an answer and keeps the cited recording IDs that
+// were actually in the context. Without valid citations every context recording is a source.
+func attributeSources(answer string, analyses []AnalysisContext) *AskResult {
+	known := make(map[string]bool, len(analyses))
+	for _, analysis := range analyses {
+		known[analysis.RecordingID] = true
+	}
+
+	var cited []string
+	seen := make(map[string]bool)
+	for _, match := range sourceTagPattern.FindAllStringSubmatch(answer, -1) {
+		for _, id := range strings.Split(match[1], ",") {
+			id = strings.Trim(strings.TrimSpace(id), "<>\"'`")
+			if known[id] && !seen[id] {
+				seen[id] = true
+				cited = append(cited, id)
+			}
+		}
+	}
+	answer = strings.TrimSpace(sourceTagPattern.ReplaceAllString(answer, ""))
+
+	if len(cited) > 0 {
+		return &AskResult{Answer: answer, Sources: cited, Cited: true}
+	}
+	sources := make([]string, 0, len(analyses))
+	for _, analysis := range analyses {
+		sources = append(sources, analysis.RecordingID)
+	}
+	return &AskResult{Answer: answer, Sources: sources}
 }
 
 // AnalysisContext represents analysis data with recording info
@@ -126,13 +180,6 @@ func buildContextFromAnalyses(analyses []AnalysisContext) string {
 			}
 		}
 
-		if len(analysis.ActionItems) > 0 {
-			builder.WriteString("Action Items:\n")
-			for _, item := range analysis.ActionItems {
-				builder.WriteString(fmt.Sprintf("- %s\n", item))
-			}
-		}
-
 		if len(analysis.KeyPoints) > 0 {
 			builder.WriteString("Điểm quan trọng:\n")
 			for _, item := range analysis.KeyPoints {
@@ -152,6 +199,14 @@ func buildContextFromAnalyses(analyses []AnalysisContext) string {
 		builder.WriteString("\n")
 	}
 
+	// Action items are deduplicated across recordings, keeping source attribution
+	if merged := MergeActionItems(analyses); len(merged) > 0 {
+		builder.WriteString("=== Action Items (đã gộp trùng lặp) ===\n")
+		for _, item := range merged {
+			builder.WriteString(fmt.Sprintf("- %s (nguồn: %s)\n", item.Text, strings.Join(item.RecordingIDs, ", ")))
+		}
+		builder.WriteString("\n")
+	}
+
 	return builder.String()
 }
-
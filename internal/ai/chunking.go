@@ -0,0 +1,224 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+)
+
+const (
+	// Oversize modes (ANALYSIS_OVERSIZE_MODE)
+	oversizeModeChunk    = "chunk"
+	oversizeModeTruncate = "truncate"
+
+	// Caps for merged list fields, so a chunked analysis reads like a single one
+	maxMergedSummary   = 8
+	maxMergedKeyPoints = 10
+	maxMergedQuestions = 5
+)
+
+// analysisMaxTokens returns ANALYSIS_MAX_TRANSCRIPT_TOKENS. The default (24000) keeps the
+// transcript well inside gpt-4o-mini's context window while leaving room for the prompt
+// template and the JSON response.
+func analysisMaxTokens() int {
+	return conf.AnalysisMaxTranscriptTokens
+}
+
+// analysisMaxChunks returns ANALYSIS_MAX_CHUNKS, the OpenAI calls spent on a single analysis (default 8)
+func analysisMaxChunks() int {
+	return conf.AnalysisMaxChunks
+}
+
+// analysisOversizeMode returns ANALYSIS_OVERSIZE_MODE: chunk (default) or truncate
+func analysisOversizeMode() string {
+	return conf.AnalysisOversizeMode
+}
+
+// AnalyzeTranscript analyzes transcript using OpenAI API
+// outputLanguage selects the prompt variant ("vi" default, "en"); the call is bounded by OPENAI_TIMEOUT.
+// Transcripts over ANALYSIS_MAX_TRANSCRIPT_TOKENS are analyzed per chunk and merged
+// (or truncated when ANALYSIS_OVERSIZE_MODE=truncate); the result records which happened.
+func AnalyzeTranscript(ctx context.Context, transcript string, detectedContext string, outputLanguage string) (*AnalysisResult, error) {
+	if a := currentAnalyzer(); a != nil && !aiDisabled.Load() {
+		return a.Analyze(ctx, transcript, detectedContext, outputLanguage)
+	}
+
+	if fastCleanEnabled(ctx) {
+		transcript = fastClean("analysis", transcript)
+	}
+
+	maxTokens := analysisMaxTokens()
+	estimated := EstimateTokens(transcript)
+	if estimated <= maxTokens {
+		return analyzeTranscriptOnce(ctx, transcript, detectedContext, outputLanguage)
+	}
+
+	// Detect context on the full transcript so every chunk uses the same prompt
+	if detectedContext == "" {
+		detectedContext = DetectContext(transcript)
+	}
+
+	chunks := SplitTranscript(transcript, maxTokens)
+	truncated := false
+	if analysisOversizeMode() == oversizeModeTruncate {
+		chunks, truncated = chunks[:1], true
+	} else if maxChunks := analysisMaxChunks(); len(chunks) > maxChunks {
+		log.Printf("WARNING: Transcript needs %d chunks, analyzing only the first %d (ANALYSIS_MAX_CHUNKS)", len(chunks), maxChunks)
+		chunks, truncated = chunks[:maxChunks], true
+	}
+
+	if len(chunks) == 1 {
+		log.Printf("WARNING: Transcript has ~%d tokens (limit %d), truncating for analysis", estimated, maxTokens)
+		result, err := analyzeTranscriptOnce(ctx, chunks[0], detectedContext, outputLanguage)
+		if err != nil {
+			return nil, err
+		}
+		result.Truncated = true
+		return result, nil
+	}
+
+	log.Printf("Transcript has ~%d tokens (limit %d), analyzing in %d chunks", estimated, maxTokens, len(chunks))
+	results := make([]*AnalysisResult, 0, len(chunks))
+	for i, chunk := range chunks {
+		result, err := analyzeTranscriptOnce(ctx, chunk, detectedContext, outputLanguage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze chunk %d/%d: %w", i+1, len(chunks), err)
+		}
+		results = append(results, result)
+	}
+
+	merged := mergeChunkAnalyses(results, detectedContext, outputLanguage)
+	merged.Truncated = truncated
+	return merged, nil
+}
+
+// SplitTranscript splits a transcript into chunks of at most maxTokens estimated tokens,
+// breaking on sentence boundaries where possible
+func SplitTranscript(transcript string, maxTokens int) []string {
+	var chunks []string
+	var current strings.Builder
+	currentTokens := 0
+
+	flush := func() {
+		if text := strings.TrimSpace(current.String()); text != "" {
+			chunks = append(chunks, text)
+		}
+		current.Reset()
+		currentTokens = 0
+	}
+	add := func(piece string, tokens int) {
+		if currentTokens+tokens > maxTokens {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteString(" ")
+		}
+		current.WriteString(piece)
+		currentTokens += tokens
+	}
+
+	for _, sentence := range splitSentences(transcript) {
+		tokens := EstimateTokens(sentence)
+		if tokens <= maxTokens {
+			add(sentence, tokens)
+			continue
+		}
+		// A single sentence over budget (e.g. unpunctuated STT output): fall back to words
+		for _, word := range strings.Fields(sentence) {
+			add(word, EstimateTokens(word))
+		}
+	}
+	flush()
+	return chunks
+}
+
+// splitSentences splits text after ., ! and ? and on line breaks
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	runes := []rune(text)
+	for i, r := range runes {
+		end := r == '\n' || ((r == '.' || r == '!' || r == '?') && (i+1 == len(runes) || unicode.IsSpace(runes[i+1])))
+		if !end {
+			continue
+		}
+		if s := strings.TrimSpace(string(runes[start : i+1])); s != "" {
+			sentences = append(sentences, s)
+		}
+		start = i + 1
+	}
+	if s := strings.TrimSpace(string(runes[start:])); s != "" {
+		sentences = append(sentences, s)
+	}
+	return sentences
+}
+
+// mergeChunkAnalyses combines per-chunk analyses into one result.
+// The title comes from the first chunk; list fields are deduplicated by word overlap and capped.
+func mergeChunkAnalyses(results []*AnalysisResult, detectedContext string, outputLanguage string) *AnalysisResult {
+	merged := &AnalysisResult{
+		Context:  detectedContext,
+		Title:    results[0].Title,
+		Language: outputLanguage,
+		Chunks:   len(results),
+
+		PromptTemplate: results[0].PromptTemplate,
+		SystemPrompt:   results[0].SystemPrompt,
+	}
+
+	var summary, actionItems, keyPoints, questions [][]string
+	confidence := 0.0
+	for _, result := range results {
+		summary = append(summary, result.Summary)
+		actionItems = append(actionItems, result.ActionItems)
+		keyPoints = append(keyPoints, result.KeyPoints)
+		questions = append(questions, result.Questions)
+		confidence += result.Confidence
+	}
+
+	merged.Summary = mergeDistinct(summary, maxMergedSummary)
+	merged.ActionItems = mergeDistinct(actionItems, 0)
+	merged.KeyPoints = mergeDistinct(keyPoints, maxMergedKeyPoints)
+	merged.Questions = mergeDistinct(questions, maxMergedQuestions)
+	merged.Entities = mergeEntities(results)
+	merged.ZaloBrief = generateZaloBrief(merged.Summary)
+	merged.Confidence = confidence / float64(len(results))
+
+	log.Printf("Merged %d chunk analyses: %d summary, %d action items, %d key points",
+		len(results), len(merged.Summary), len(merged.ActionItems), len(merged.KeyPoints))
+	return merged
+}
+
+// mergeDistinct flattens lists, dropping items that near-duplicate an earlier one
+// (same threshold as MergeActionItems). limit <= 0 means no cap.
+func mergeDistinct(lists [][]string, limit int) []string {
+	out := []string{}
+	var seen []map[string]bool
+	for _, list := range lists {
+		for _, item := range list {
+			text := strings.TrimSpace(item)
+			if text == "" {
+				continue
+			}
+			tokens := actionItemTokens(text)
+			duplicate := false
+			for _, existing := range seen {
+				if jaccard(tokens, existing) >= actionItemSimilarityThreshold {
+					duplicate = true
+					break
+				}
+			}
+			if duplicate {
+				continue
+			}
+			out = append(out, text)
+			seen = append(seen, tokens)
+			if limit > 0 && len(out) == limit {
+				return out
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,138 @@
+package ai
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// defaultPromptFS holds the built-in cleaning prompt templates, used for any file missing from CLEAN_PROMPT_DIR
+//
+//go:embed prompts/*.tmpl
+var defaultPromptFS embed.FS
+
+// cleanPromptData is the data passed to the cleaning prompt templates
+type cleanPromptData struct {
+	Transcript string
+}
+
+// cleanPromptSet is the system and user template of one output language
+type cleanPromptSet struct {
+	system *template.Template
+	user   *template.Template
+}
+
+var (
+	cleanPrompts     map[string]cleanPromptSet // by output language
+	cleanPromptsErr  error
+	cleanPromptsOnce sync.Once
+)
+
+// LoadCleanPrompts loads the cleaning prompt templates clean_{system,user}_{vi,en}.tmpl.
+// Files in CLEAN_PROMPT_DIR override the embedded defaults; missing ones fall back to them.
+// Call it at startup to fail fast on a broken template; otherwise it runs on first use.
+func LoadCleanPrompts() error {
+	cleanPromptsOnce.Do(func() {
+		cleanPrompts, cleanPromptsErr = loadCleanPrompts(conf.CleanPromptDir)
+	})
+	return cleanPromptsErr
+}
+
+func loadCleanPrompts(dir string) (map[string]cleanPromptSet, error) {
+	sets := make(map[string]cleanPromptSet, 2)
+	for _, lang := range []string{LanguageVietnamese, LanguageEnglish} {
+		system, err := loadPromptTemplate(dir, "clean_system_"+lang+".tmpl")
+		if err != nil {
+			return nil, err
+		}
+		user, err := loadPromptTemplate(dir, "clean_user_"+lang+".tmpl")
+		if err != nil {
+			return nil, err
+		}
+
+		// A user prompt without the transcript would silently clean nothing
+		const probe = "\x00transcript\x00"
+		rendered, err := renderPromptTemplate(user, probe)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.Contains(rendered, probe) {
+			return nil, fmt.Errorf("prompt template %s must contain {{.Transcript}}", user.Name())
+		}
+
+		sets[lang] = cleanPromptSet{system: system, user: user}
+	}
+	return sets, nil
+}
+
+// loadPromptTemplate parses name from dir, or from the embedded defaults when dir is empty or lacks the file
+func loadPromptTemplate(dir string, name string) (*template.Template, error) {
+	source := "embedded default"
+	var data []byte
+	if dir != "" {
+		path := filepath.Join(dir, name)
+		content, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			data, source = content, path
+		case !errors.Is(err, fs.ErrNotExist):
+			return nil, fmt.Errorf("failed to read prompt template %s: %w", path, err)
+		}
+	}
+	if data == nil {
+		content, err := defaultPromptFS.ReadFile("prompts/" + name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read embedded prompt template %s: %w", name, err)
+		}
+		data = content
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template %s (%s): %w", name, source, err)
+	}
+	log.Printf("[Prompts] Loaded %s from %s", name, source)
+	return tmpl, nil
+}
+
+// renderPromptTemplate executes a cleaning prompt template for a transcript
+func renderPromptTemplate(tmpl *template.Template, transcript string) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, cleanPromptData{Transcript: transcript}); err != nil {
+		return "", fmt.Errorf("failed to render prompt template %s: %w", tmpl.Name(), err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// buildCleanPrompt builds the cleaning prompts for the requested output language,
+// using the variant B templates when ctx was assigned to them (see WithPromptVariant)
+func buildCleanPrompt(ctx context.Context, transcript string, outputLanguage string) (systemPrompt string, userPrompt string, err error) {
+	if err := LoadCleanPrompts(); err != nil {
+		return "", "", err
+	}
+
+	prompts := cleanPrompts
+	if exp, ok := experimentVariantB(ctx); ok {
+		prompts = exp.cleanPrompts
+	}
+	set, ok := prompts[outputLanguage]
+	if !ok {
+		set = prompts[LanguageVietnamese]
+	}
+
+	if systemPrompt, err = renderPromptTemplate(set.system, transcript); err != nil {
+		return "", "", err
+	}
+	if userPrompt, err = renderPromptTemplate(set.user, transcript); err != nil {
+		return "", "", err
+	}
+	return systemPrompt, userPrompt, nil
+}
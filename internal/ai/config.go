@@ -0,0 +1,16 @@
+package ai
+
+import (
+	"noteme/internal/config"
+)
+
+// conf holds the AI settings (model, timeouts, analysis limits, prompt locations).
+// It starts at the defaults and is replaced by Configure at startup.
+var conf = config.Default()
+
+// Configure applies the loaded configuration. Call it at startup, before LoadCleanPrompts
+// and LoadPromptExperiment.
+func Configure(cfg *config.Config) {
+	conf = cfg
+	SetEnabled(cfg.EnableAI)
+}
@@ -2,52 +2,122 @@ package ai
 
 import (
 	"strings"
+	"unicode"
 )
 
+// ContextDetection is the rule-based context result: the keyword match counts (every occurrence)
+// and the weighted scores, net of negative signals, that decide the context
+type ContextDetection struct {
+	Context      string `json:"context"`
+	MeetingCount int    `json:"meeting_count"`
+	LectureCount int    `json:"lecture_count"`
+	MeetingScore int    `json:"meeting_score"`
+	LectureScore int    `json:"lecture_score"`
+}
+
+// Meeting keywords with weights (strong signals count double)
+var meetingKeywords = map[string]int{
+	"họp": 2, "dự án": 2, "deadline": 2, "gửi": 1, "báo cáo": 2,
+	"khách hàng": 2, "đồng nghiệp": 2, "team": 1, "nhóm": 1,
+	"thống nhất": 2, "chốt": 2, "phê duyệt": 2, "approve": 2,
+	"task": 1, "công việc": 1, "nhiệm vụ": 1,
+}
+
+// Lecture keywords with weights (strong signals count double)
+var lectureKeywords = map[string]int{
+	"bài giảng": 2, "thầy": 1, "cô": 1, "chương": 2, "ví dụ": 1,
+	"kiến thức": 2, "học": 1, "giải thích": 1, "định nghĩa": 2,
+	"khái niệm": 2, "nguyên lý": 2, "phương pháp": 1,
+	"các em": 2, "sinh viên": 2, "bài tập": 2,
+}
+
+// Negative signals subtract from a class score when they appear
+var meetingNegativeKeywords = map[string]int{
+	"các em": 2, "sinh viên": 2, "bài tập": 2, "bài giảng": 1,
+}
+
+var lectureNegativeKeywords = map[string]int{
+	"khách hàng": 2, "phê duyệt": 1, "chốt": 1, "deadline": 1,
+}
+
 // DetectContext detects context type based on simple rules
 // Returns: "meeting", "lecture", or "thinking"
 func DetectContext(transcript string) string {
-	transcript = strings.ToLower(transcript)
-
-	// Meeting keywords
-	meetingKeywords := []string{
-		"họp", "dự án", "deadline", "gửi", "báo cáo",
-		"khách hàng", "đồng nghiệp", "team", "nhóm",
-		"thống nhất", "chốt", "phê duyệt", "approve",
-		"task", "công việc", "nhiệm vụ",
+	return DetectContextWithCounts(transcript).Context
+}
+
+// DetectContextWithCounts detects context type and returns the weighted scores.
+// Keywords are matched on whole words and every occurrence counts; a genuine tie
+// between meeting and lecture returns "thinking" rather than guessing.
+func DetectContextWithCounts(transcript string) ContextDetection {
+	tokens := tokenize(transcript)
+
+	meetingScore := weightedCount(tokens, meetingKeywords) - weightedCount(tokens, meetingNegativeKeywords)
+	lectureScore := weightedCount(tokens, lectureKeywords) - weightedCount(tokens, lectureNegativeKeywords)
+
+	result := ContextDetection{
+		MeetingCount: matchCount(tokens, meetingKeywords),
+		LectureCount: matchCount(tokens, lectureKeywords),
+		MeetingScore: meetingScore,
+		LectureScore: lectureScore,
 	}
 
-	// Lecture keywords
-	lectureKeywords := []string{
-		"bài giảng", "thầy", "cô", "chương", "ví dụ",
-		"kiến thức", "học", "giải thích", "định nghĩa",
-		"khái niệm", "nguyên lý", "phương pháp",
+	// Determine context
+	switch {
+	case meetingScore > 0 && meetingScore > lectureScore:
+		result.Context = "meeting"
+	case lectureScore > 0 && lectureScore > meetingScore:
+		result.Context = "lecture"
+	default:
+		// No signal or a genuine tie
+		result.Context = "thinking"
 	}
 
-	// Count matches
-	meetingCount := 0
-	lectureCount := 0
+	return result
+}
 
-	for _, keyword := range meetingKeywords {
-		if strings.Contains(transcript, keyword) {
-			meetingCount++
-		}
+// tokenize lowercases text and splits it into words on anything that isn't a letter or digit
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// weightedCount sums weight × occurrences for every keyword (single or multi-word)
+func weightedCount(tokens []string, keywords map[string]int) int {
+	total := 0
+	for keyword, weight := range keywords {
+		total += weight * countPhrase(tokens, strings.Fields(keyword))
 	}
+	return total
+}
 
-	for _, keyword := range lectureKeywords {
-		if strings.Contains(transcript, keyword) {
-			lectureCount++
-		}
+// matchCount counts the occurrences of all keywords, ignoring their weights
+func matchCount(tokens []string, keywords map[string]int) int {
+	total := 0
+	for keyword := range keywords {
+		total += countPhrase(tokens, strings.Fields(keyword))
 	}
+	return total
+}
 
-	// Determine context
-	if meetingCount > 0 && meetingCount >= lectureCount {
-		return "meeting"
+// countPhrase counts occurrences of a word sequence in tokens
+func countPhrase(tokens []string, phrase []string) int {
+	if len(phrase) == 0 {
+		return 0
 	}
-	if lectureCount > 0 {
-		return "lecture"
+	count := 0
+	for i := 0; i+len(phrase) <= len(tokens); i++ {
+		match := true
+		for j, word := range phrase {
+			if tokens[i+j] != word {
+				match = false
+				break
+			}
+		}
+		if match {
+			count++
+		}
 	}
-
-	// Default to thinking
-	return "thinking"
+	return count
 }
@@ -0,0 +1,70 @@
+package ai
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// askContextEntry is a cached Ask Anything context string
+type askContextEntry struct {
+	text      string
+	expiresAt time.Time
+}
+
+var (
+	askContextCache   = make(map[string]askContextEntry)
+	askContextHits    uint64
+	askContextMisses  uint64
+	muAskContextCache sync.Mutex
+)
+
+// InvalidateAskContextCache drops all cached contexts (called when an analysis changes)
+func InvalidateAskContextCache() {
+	muAskContextCache.Lock()
+	defer muAskContextCache.Unlock()
+	if len(askContextCache) > 0 {
+		log.Printf("[AskCache] Invalidated %d cached contexts", len(askContextCache))
+	}
+	askContextCache = make(map[string]askContextEntry)
+}
+
+// cachedContextFromAnalyses returns the built context for cacheKey, building it on a miss.
+// An empty cacheKey disables caching.
+func cachedContextFromAnalyses(cacheKey string, analyses []AnalysisContext) string {
+	if cacheKey == "" {
+		return buildContextFromAnalyses(analyses)
+	}
+
+	now := time.Now()
+
+	muAskContextCache.Lock()
+	entry, ok := askContextCache[cacheKey]
+	if ok && now.Before(entry.expiresAt) {
+		askContextHits++
+		log.Printf("[AskCache] hit (hits=%d, misses=%d)", askContextHits, askContextMisses)
+		muAskContextCache.Unlock()
+		return entry.text
+	}
+	askContextMisses++
+	log.Printf("[AskCache] miss (hits=%d, misses=%d)", askContextHits, askContextMisses)
+	muAskContextCache.Unlock()
+
+	text := buildContextFromAnalyses(analyses)
+
+	muAskContextCache.Lock()
+	defer muAskContextCache.Unlock()
+	// Drop expired entries while we hold the lock so the map stays small
+	for key, e := range askContextCache {
+		if now.After(e.expiresAt) {
+			delete(askContextCache, key)
+		}
+	}
+	askContextCache[cacheKey] = askContextEntry{text: text, expiresAt: now.Add(askContextCacheTTL())}
+	return text
+}
+
+// askContextCacheTTL returns ASK_CONTEXT_CACHE_TTL (default 10m)
+func askContextCacheTTL() time.Duration {
+	return conf.AskContextCacheTTL
+}
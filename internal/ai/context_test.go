@@ -0,0 +1,64 @@
+package ai
+
+import "testing"
+
+func TestDetectContextWithCounts(t *testing.T) {
+	tests := []struct {
+		name       string
+		transcript string
+		want       ContextDetection
+	}{
+		{
+			name:       "meeting",
+			transcript: "Cuộc họp hôm nay chốt deadline dự án, khách hàng cần báo cáo trước thứ sáu.",
+			want:       ContextDetection{Context: "meeting", MeetingCount: 6, MeetingScore: 12, LectureScore: -4},
+		},
+		{
+			name:       "lecture",
+			transcript: "Hôm nay các em học chương ba, thầy giải thích khái niệm và định nghĩa qua ví dụ.",
+			want:       ContextDetection{Context: "lecture", LectureCount: 8, MeetingScore: -2, LectureScore: 12},
+		},
+		{
+			name:       "lecture mentioning team",
+			transcript: "Các em chia team làm bài tập chương hai, sinh viên nộp bài tập cuối buổi.",
+			want:       ContextDetection{Context: "lecture", MeetingCount: 1, LectureCount: 5, MeetingScore: -7, LectureScore: 10},
+		},
+		{
+			name:       "repeated keyword counts every occurrence",
+			transcript: "Team, team, team: task này xong rồi.",
+			want:       ContextDetection{Context: "meeting", MeetingCount: 4, MeetingScore: 4},
+		},
+		{
+			name:       "thinking without keywords",
+			transcript: "Mình đang nghĩ xem cuối tuần nên đi đâu, có lẽ về quê thăm bà.",
+			want:       ContextDetection{Context: "thinking"},
+		},
+		{
+			name:       "tie returns thinking",
+			transcript: "Team đọc ví dụ.",
+			want:       ContextDetection{Context: "thinking", MeetingCount: 1, LectureCount: 1, MeetingScore: 1, LectureScore: 1},
+		},
+		{
+			name:       "tie after negative signals returns thinking",
+			transcript: "Deadline nộp bài giảng là thứ hai.",
+			want:       ContextDetection{Context: "thinking", MeetingCount: 1, LectureCount: 1, MeetingScore: 1, LectureScore: 1},
+		},
+		{
+			name:       "empty transcript",
+			transcript: "",
+			want:       ContextDetection{Context: "thinking"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectContextWithCounts(tt.transcript)
+			if got != tt.want {
+				t.Errorf("DetectContextWithCounts(%q) = %+v, want %+v", tt.transcript, got, tt.want)
+			}
+			if DetectContext(tt.transcript) != tt.want.Context {
+				t.Errorf("DetectContext(%q) = %q, want %q", tt.transcript, DetectContext(tt.transcript), tt.want.Context)
+			}
+		})
+	}
+}
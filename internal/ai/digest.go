@@ -0,0 +1,233 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DigestResult is a consolidated roll-up of the analyses in a date range
+type DigestResult struct {
+	Summary     []string           `json:"summary"`      // overall summary of the period
+	Highlights  []string           `json:"highlights"`   // most important points across recordings
+	Decisions   []string           `json:"decisions"`    // decisions that were made
+	ActionItems []DigestActionItem `json:"action_items"` // deduplicated action items with their recordings
+	Recordings  int                `json:"recordings"`   // analyses included
+	Chunks      int                `json:"chunks"`       // digest calls before the final merge (1 = no chunking)
+	Truncated   bool               `json:"truncated"`    // oldest analyses were left out to respect ANALYSIS_MAX_CHUNKS
+}
+
+// DigestActionItem is an action item merged across recordings
+type DigestActionItem struct {
+	Task    string   `json:"task"`
+	Sources []string `json:"sources"`
+}
+
+// digestSections is the JSON the model returns for a digest call
+type digestSections struct {
+	Summary    []string `json:"summary"`
+	Highlights []string `json:"highlights"`
+	Decisions  []string `json:"decisions"`
+}
+
+// digestMaxContextTokens returns DIGEST_MAX_CONTEXT_TOKENS, the analyses context sent in one
+// digest call (default 12000)
+func digestMaxContextTokens() int {
+	return conf.DigestMaxContextTokens
+}
+
+// BuildDigest produces one consolidated summary across analyses (newest first).
+// Action items are merged deterministically with MergeActionItems. When the analyses do not fit in
+// DIGEST_MAX_CONTEXT_TOKENS they are digested in chunks whose results are merged by a final call.
+func BuildDigest(ctx context.Context, analyses []AnalysisContext, outputLanguage string) (*DigestResult, error) {
+	apiKey, err := openAIKey()
+	if err != nil {
+		return nil, err
+	}
+	if len(analyses) == 0 {
+		return nil, fmt.Errorf("no analysis data available for the digest")
+	}
+	if outputLanguage == "" {
+		outputLanguage = LanguageVietnamese
+	}
+
+	ctx, span := startSpan(ctx, "ai.BuildDigest", attribute.Int("ai.analyses", len(analyses)))
+	defer span.End()
+
+	client := openai.NewClient(apiKey)
+	result := &DigestResult{}
+
+	groups := splitAnalysesForDigest(analyses, digestMaxContextTokens())
+	if maxChunks := analysisMaxChunks(); len(groups) > maxChunks {
+		log.Printf("[Digest] %d chunks exceed ANALYSIS_MAX_CHUNKS=%d, leaving out the oldest analyses", len(groups), maxChunks)
+		groups = groups[:maxChunks]
+		result.Truncated = true
+	}
+
+	var included []AnalysisContext
+	for _, group := range groups {
+		included = append(included, group...)
+	}
+	result.Recordings = len(included)
+	result.Chunks = len(groups)
+
+	log.Printf("[Digest] Building digest of %d analyses in %d chunk(s)", len(included), len(groups))
+
+	partials := make([]*digestSections, 0, len(groups))
+	for i, group := range groups {
+		sections, err := requestDigest(ctx, client, buildContextFromAnalyses(group), outputLanguage, false)
+		if err != nil {
+			recordSpanError(span, err)
+			return nil, fmt.Errorf("digest chunk %d/%d failed: %w", i+1, len(groups), err)
+		}
+		partials = append(partials, sections)
+	}
+
+	final := partials[0]
+	if len(partials) > 1 {
+		merged, err := requestDigest(ctx, client, formatPartialDigests(partials), outputLanguage, true)
+		if err != nil {
+			recordSpanError(span, err)
+			return nil, fmt.Errorf("merging digest chunks failed: %w", err)
+		}
+		final = merged
+	}
+
+	result.Summary = nonNilStrings(final.Summary)
+	result.Highlights = nonNilStrings(final.Highlights)
+	result.Decisions = nonNilStrings(final.Decisions)
+	result.ActionItems = []DigestActionItem{}
+	for _, item := range MergeActionItems(included) {
+		result.ActionItems = append(result.ActionItems, DigestActionItem{Task: item.Text, Sources: item.RecordingIDs})
+	}
+	return result, nil
+}
+
+// splitAnalysesForDigest groups analyses so each group's context stays under maxTokens.
+// An analysis larger than maxTokens gets a group of its own (buildContextFromAnalyses truncates transcripts).
+func splitAnalysesForDigest(analyses []AnalysisContext, maxTokens int) [][]AnalysisContext {
+	var groups [][]AnalysisContext
+	var current []AnalysisContext
+	currentTokens := 0
+	for _, analysis := range analyses {
+		tokens := EstimateTokens(buildContextFromAnalyses([]AnalysisContext{analysis}))
+		if len(current) > 0 && currentTokens+tokens > maxTokens {
+			groups = append(groups, current)
+			current, currentTokens = nil, 0
+		}
+		current = append(current, analysis)
+		currentTokens += tokens
+	}
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+	return groups
+}
+
+// formatPartialDigests renders chunk digests as the input of the final merge call
+func formatPartialDigests(partials []*digestSections) string {
+	var b strings.Builder
+	for i, partial := range partials {
+		b.WriteString(fmt.Sprintf("=== Phần %d ===\n", i+1))
+		writeDigestList(&b, "Tóm tắt", partial.Summary)
+		writeDigestList(&b, "Điểm nổi bật", partial.Highlights)
+		writeDigestList(&b, "Quyết định", partial.Decisions)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func writeDigestList(b *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	b.WriteString(title + ":\n")
+	for _, item := range items {
+		b.WriteString("- " + item + "\n")
+	}
+}
+
+// buildDigestPrompt builds the digest prompts. merge is set when input holds chunk digests
+// rather than analyses.
+func buildDigestPrompt(input string, outputLanguage string, merge bool) (string, string) {
+	language := "TIẾNG VIỆT (chỉ giữ keywords chuyên ngành bằng tiếng Anh)"
+	if outputLanguage == LanguageEnglish {
+		language = "ENGLISH"
+	}
+
+	systemPrompt := fmt.Sprintf(`Bạn là trợ lý AI của NoteMe. Nhiệm vụ: tổng hợp nhiều cuộc ghi âm trong một khoảng thời gian thành MỘT bản tổng kết (digest) duy nhất.
+
+NGUYÊN TẮC:
+- Chỉ dùng thông tin có trong dữ liệu, không bịa đặt
+- Gộp các ý trùng lặp giữa các cuộc ghi âm, ưu tiên nội dung quan trọng và lặp lại nhiều lần
+- Ngắn gọn, mỗi mục một câu
+- Viết bằng %s
+
+Trả về JSON hợp lệ, ĐÚNG format sau, không thêm trường khác:
+{
+  "summary": ["3-6 câu tóm tắt toàn bộ khoảng thời gian"],
+  "highlights": ["tối đa 10 điểm nổi bật quan trọng nhất"],
+  "decisions": ["các quyết định đã được đưa ra, [] nếu không có"]
+}`, language)
+
+	intro := "Dữ liệu đã phân tích từ các cuộc ghi âm:"
+	if merge {
+		intro = "Các bản tổng kết từng phần (mỗi phần là một nhóm ghi âm), hãy gộp thành một bản tổng kết duy nhất:"
+	}
+	userPrompt := fmt.Sprintf("%s\n\n%s", intro, input)
+	return systemPrompt, userPrompt
+}
+
+// requestDigest runs one digest call, bounded by OPENAI_TIMEOUT
+func requestDigest(ctx context.Context, client *openai.Client, input string, outputLanguage string, merge bool) (*digestSections, error) {
+	systemPrompt, userPrompt := buildDigestPrompt(input, outputLanguage, merge)
+	log.Printf("[Digest] Calling OpenAI (merge: %v, ~%d prompt tokens)", merge, EstimatePromptTokens(systemPrompt, userPrompt))
+
+	ctx, cancel := withOpenAITimeout(ctx)
+	defer cancel()
+
+	req := openai.ChatCompletionRequest{
+		Model: conf.OpenAIModel,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: systemPrompt},
+			{Role: openai.ChatMessageRoleUser, Content: userPrompt},
+		},
+		Temperature: 0.3,
+		ResponseFormat: &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		},
+	}
+	applyGenerationParams(ctx, &req)
+
+	resp, err := client.CreateChatCompletion(ctx, req)
+	if err != nil {
+		log.Printf("OpenAI API error while building digest: %v", err)
+		return nil, wrapOpenAIError(err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("OpenAI returned no choices")
+	}
+	recordUsage("digest", resp.Usage)
+
+	content := resp.Choices[0].Message.Content
+	var sections digestSections
+	if err := json.Unmarshal([]byte(content), &sections); err != nil {
+		if err := json.Unmarshal([]byte(extractJSONFromMarkdown(content)), &sections); err != nil {
+			return nil, fmt.Errorf("failed to parse OpenAI response as JSON: %w", err)
+		}
+	}
+	return &sections, nil
+}
+
+// nonNilStrings returns items, or an empty slice so JSON renders [] instead of null
+func nonNilStrings(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
+}
@@ -0,0 +1,68 @@
+package ai
+
+import (
+	"strings"
+)
+
+// Entity types emitted by the analysis prompt
+const (
+	EntityPerson       = "person"
+	EntityProject      = "project"
+	EntityTechnology   = "technology"
+	EntityOrganization = "organization"
+	EntityOther        = "other"
+)
+
+// maxEntities caps the entities kept per analysis
+const maxEntities = 20
+
+// Entity is a named entity mentioned in a recording
+type Entity struct {
+	Type string `json:"type"` // person, project, technology, organization or other
+	Text string `json:"text"` // as spoken, e.g. "anh Minh", "NoteMe", "Golang"
+}
+
+var entityTypes = map[string]bool{
+	EntityPerson:       true,
+	EntityProject:      true,
+	EntityTechnology:   true,
+	EntityOrganization: true,
+	EntityOther:        true,
+}
+
+// normalizeEntities trims entities, maps unknown types to "other" and drops empty and
+// case-insensitive duplicates, keeping at most maxEntities
+func normalizeEntities(entities []Entity) []Entity {
+	out := []Entity{}
+	seen := make(map[string]bool, len(entities))
+	for _, entity := range entities {
+		text := strings.TrimSpace(entity.Text)
+		if text == "" {
+			continue
+		}
+		key := strings.ToLower(text)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		entityType := strings.ToLower(strings.TrimSpace(entity.Type))
+		if !entityTypes[entityType] {
+			entityType = EntityOther
+		}
+		out = append(out, Entity{Type: entityType, Text: text})
+		if len(out) == maxEntities {
+			break
+		}
+	}
+	return out
+}
+
+// mergeEntities combines the entities of chunk analyses, first mention wins
+func mergeEntities(results []*AnalysisResult) []Entity {
+	var all []Entity
+	for _, result := range results {
+		all = append(all, result.Entities...)
+	}
+	return normalizeEntities(all)
+}
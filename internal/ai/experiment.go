@@ -0,0 +1,125 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Prompt experiment variants. A is always the production prompt.
+const (
+	PromptVariantA = "A"
+	PromptVariantB = "B"
+)
+
+// experimentAnalysisPromptFile is the optional variant B analysis system prompt in PROMPT_EXPERIMENT_B_DIR
+const experimentAnalysisPromptFile = "analysis_system.txt"
+
+// promptExperiment is the A/B prompt experiment configured from the environment
+type promptExperiment struct {
+	name           string
+	bPercent       int                       // share of recordings assigned to B, 0-100
+	cleanPrompts   map[string]cleanPromptSet // variant B cleaning templates
+	analysisPrompt string                    // variant B analysis system prompt, empty = same as A
+}
+
+var (
+	experiment     *promptExperiment // nil when no experiment is running
+	experimentErr  error
+	experimentOnce sync.Once
+)
+
+type promptVariantKey struct{}
+
+// LoadPromptExperiment reads the A/B prompt experiment config. It is disabled unless PROMPT_EXPERIMENT
+// names an experiment; variant B then uses the cleaning templates in PROMPT_EXPERIMENT_B_DIR (missing
+// files fall back to the embedded defaults) and, if present, its analysis_system.txt.
+// PROMPT_EXPERIMENT_B_PERCENT (default 50) sets the share of recordings assigned to B.
+func LoadPromptExperiment() error {
+	experimentOnce.Do(func() {
+		experiment, experimentErr = loadPromptExperiment()
+	})
+	return experimentErr
+}
+
+func loadPromptExperiment() (*promptExperiment, error) {
+	name := conf.PromptExperiment
+	if name == "" {
+		return nil, nil
+	}
+
+	// config.Load requires the directory whenever an experiment is named
+	dir := conf.PromptExperimentBDir
+	if dir == "" {
+		return nil, fmt.Errorf("PROMPT_EXPERIMENT_B_DIR is required when PROMPT_EXPERIMENT is set")
+	}
+
+	exp := &promptExperiment{name: name, bPercent: conf.PromptExperimentBPercent}
+
+	prompts, err := loadCleanPrompts(dir)
+	if err != nil {
+		return nil, fmt.Errorf("prompt experiment %s: %w", name, err)
+	}
+	exp.cleanPrompts = prompts
+
+	path := filepath.Join(dir, experimentAnalysisPromptFile)
+	content, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		// Same rules as a per-request system_prompt: the JSON output format stays enforced
+		if exp.analysisPrompt, err = NewSystemPrompt(string(content)); err != nil {
+			return nil, fmt.Errorf("prompt experiment %s: %s: %w", name, path, err)
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, fmt.Errorf("prompt experiment %s: failed to read %s: %w", name, path, err)
+	}
+
+	log.Printf("[Experiment] Prompt experiment %q running: %d%% of recordings use variant B from %s (custom analysis prompt: %v)",
+		name, exp.bPercent, dir, exp.analysisPrompt != "")
+	return exp, nil
+}
+
+// PromptExperimentName returns the running experiment, or "" when experiments are disabled
+func PromptExperimentName() string {
+	if LoadPromptExperiment() != nil || experiment == nil {
+		return ""
+	}
+	return experiment.name
+}
+
+// AssignPromptVariant deterministically assigns a recording to variant A or B by hashing the
+// experiment name and recording ID. It returns "" when no experiment is running.
+func AssignPromptVariant(recordingID string) string {
+	if LoadPromptExperiment() != nil || experiment == nil {
+		return ""
+	}
+	h := fnv.New32a()
+	h.Write([]byte(experiment.name + ":" + recordingID))
+	if int(h.Sum32()%100) < experiment.bPercent {
+		return PromptVariantB
+	}
+	return PromptVariantA
+}
+
+// WithPromptVariant returns a context whose cleaning and analysis use the prompts of variant
+func WithPromptVariant(ctx context.Context, variant string) context.Context {
+	if variant == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, promptVariantKey{}, variant)
+}
+
+// experimentVariantB returns the running experiment when ctx was assigned variant B
+func experimentVariantB(ctx context.Context) (*promptExperiment, bool) {
+	variant, _ := ctx.Value(promptVariantKey{}).(string)
+	if variant != PromptVariantB || experiment == nil {
+		return nil, false
+	}
+	return experiment, true
+}
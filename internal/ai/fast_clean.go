@@ -0,0 +1,104 @@
+package ai
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// noisePattern matches greetings, mic tests and filler words. Longer alternatives come first
+// so "okay" and "ừm" win over "ok" and "ừ".
+var noisePattern = regexp.MustCompile(`(?i)xin chào|chào bạn|hello|test mic|thử mic|check mic|được rồi|okay|ok|hi|ừm|ừ`)
+
+var (
+	multiSpacePattern       = regexp.MustCompile(`[ \t]+`)
+	spaceBeforePunctPattern = regexp.MustCompile(`[ \t]+([,.;:!?])`)
+	// a comma/semicolon/colon left in front of other punctuation after a filler was removed
+	danglingPunctPattern = regexp.MustCompile(`[,;:][ \t]*([,.;:!?])`)
+	// a comma/semicolon/colon right after the end of a sentence
+	orphanPunctPattern  = regexp.MustCompile(`([.!?])[ \t]*[,;:]+`)
+	leadingPunctPattern = regexp.MustCompile(`(?m)^[ \t]*[,.;:!?]+[ \t]*`)
+)
+
+// CleanTranscript is the rule-based noise remover: it strips greetings, mic tests and filler words
+// without an AI call. Only whole words are removed and the casing of the remaining text is kept.
+func CleanTranscript(transcript string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range noisePattern.FindAllStringIndex(transcript, -1) {
+		if !isWordBoundary(transcript, loc[0], loc[1]) {
+			continue
+		}
+		b.WriteString(transcript[last:loc[0]])
+		last = loc[1]
+	}
+	b.WriteString(transcript[last:])
+	cleaned := b.String()
+
+	cleaned = multiSpacePattern.ReplaceAllString(cleaned, " ")
+	cleaned = spaceBeforePunctPattern.ReplaceAllString(cleaned, "$1")
+	for danglingPunctPattern.MatchString(cleaned) {
+		cleaned = danglingPunctPattern.ReplaceAllString(cleaned, "$1")
+	}
+	cleaned = orphanPunctPattern.ReplaceAllString(cleaned, "$1")
+	cleaned = leadingPunctPattern.ReplaceAllString(cleaned, "")
+
+	lines := strings.Split(cleaned, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// isWordBoundary reports whether s[start:end] is not part of a longer word.
+// regexp's \b only knows ASCII letters, so Vietnamese words are checked here.
+func isWordBoundary(s string, start, end int) bool {
+	if start > 0 {
+		r, _ := utf8.DecodeLastRuneInString(s[:start])
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return false
+		}
+	}
+	if end < len(s) {
+		r, _ := utf8.DecodeRuneInString(s[end:])
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+type fastCleanKey struct{}
+
+// WithFastClean returns a context whose analyses run CleanTranscript on the transcript first
+func WithFastClean(ctx context.Context) context.Context {
+	return context.WithValue(ctx, fastCleanKey{}, true)
+}
+
+// fastCleanEnabled reports whether ctx asks for the rule-based preprocessing
+func fastCleanEnabled(ctx context.Context) bool {
+	enabled, _ := ctx.Value(fastCleanKey{}).(bool)
+	return enabled
+}
+
+// fastClean runs CleanTranscript and logs the estimated prompt tokens it saved.
+// The original is kept when nothing would be left.
+func fastClean(label string, transcript string) string {
+	cleaned := CleanTranscript(transcript)
+	if cleaned == "" {
+		log.Printf("[FastClean] %s: transcript is only noise, keeping the original", label)
+		return transcript
+	}
+
+	before, after := EstimateTokens(transcript), EstimateTokens(cleaned)
+	saved := before - after
+	percent := 0.0
+	if before > 0 {
+		percent = float64(saved) * 100 / float64(before)
+	}
+	log.Printf("[FastClean] %s: ~%d -> ~%d tokens (saved ~%d, %.1f%%)", label, before, after, saved, percent)
+	return cleaned
+}
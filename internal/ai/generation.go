@@ -0,0 +1,68 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Accepted ranges for per-request generation settings
+const (
+	MinTemperature = 0.0
+	MaxTemperature = 1.5
+	MinMaxTokens   = 16
+	MaxMaxTokens   = 4096
+)
+
+// GenerationParams override the temperature and max_tokens an endpoint sends to OpenAI.
+// Like stt.Options they travel in the context, so the AI function signatures stay unchanged.
+type GenerationParams struct {
+	Temperature *float32 // nil = endpoint default
+	MaxTokens   int      // 0 = endpoint default
+}
+
+type generationParamsKey struct{}
+
+// NewGenerationParams validates optional request values into GenerationParams
+func NewGenerationParams(temperature *float64, maxTokens int) (GenerationParams, error) {
+	var params GenerationParams
+	if temperature != nil {
+		if *temperature < MinTemperature || *temperature > MaxTemperature || math.IsNaN(*temperature) {
+			return params, fmt.Errorf("temperature must be between %g and %g", MinTemperature, MaxTemperature)
+		}
+		t := float32(*temperature)
+		params.Temperature = &t
+	}
+	if maxTokens != 0 {
+		if maxTokens < MinMaxTokens || maxTokens > MaxMaxTokens {
+			return params, fmt.Errorf("max_tokens must be between %d and %d", MinMaxTokens, MaxMaxTokens)
+		}
+		params.MaxTokens = maxTokens
+	}
+	return params, nil
+}
+
+// WithGenerationParams returns a context carrying generation overrides
+func WithGenerationParams(ctx context.Context, params GenerationParams) context.Context {
+	return context.WithValue(ctx, generationParamsKey{}, params)
+}
+
+// applyGenerationParams overrides req.Temperature and req.MaxTokens with the values in ctx, if any
+func applyGenerationParams(ctx context.Context, req *openai.ChatCompletionRequest) {
+	params, ok := ctx.Value(generationParamsKey{}).(GenerationParams)
+	if !ok {
+		return
+	}
+	if params.Temperature != nil {
+		req.Temperature = *params.Temperature
+		// go-openai omits a zero temperature (the API then defaults to 1), so send the smallest non-zero value
+		if req.Temperature == 0 {
+			req.Temperature = math.SmallestNonzeroFloat32
+		}
+	}
+	if params.MaxTokens > 0 {
+		req.MaxTokens = params.MaxTokens
+	}
+}
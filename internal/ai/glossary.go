@@ -0,0 +1,207 @@
+package ai
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxGlossaryEntries caps the terms plus corrections sent with one cleaning prompt
+const maxGlossaryEntries = 500
+
+// Glossary lists domain vocabulary the cleaning prompt must recognize on top of the built-in
+// tech terms: Terms are kept exactly as spelled (project names, internal jargon) and
+// Corrections map a known misrecognition to the right term.
+type Glossary struct {
+	Terms       []string
+	Corrections []GlossaryCorrection
+}
+
+// GlossaryCorrection is a "wrong → right" pair
+type GlossaryCorrection struct {
+	Wrong string
+	Right string
+}
+
+// Empty reports whether the glossary has no entries
+func (g Glossary) Empty() bool {
+	return len(g.Terms) == 0 && len(g.Corrections) == 0
+}
+
+// Merge returns g followed by the entries of other that g lacks (compared case-insensitively)
+func (g Glossary) Merge(other Glossary) Glossary {
+	merged := Glossary{}
+	seenTerms := make(map[string]bool)
+	for _, term := range append(append([]string{}, g.Terms...), other.Terms...) {
+		key := strings.ToLower(term)
+		if !seenTerms[key] {
+			seenTerms[key] = true
+			merged.Terms = append(merged.Terms, term)
+		}
+	}
+	seenWrong := make(map[string]bool)
+	for _, c := range append(append([]GlossaryCorrection{}, g.Corrections...), other.Corrections...) {
+		key := strings.ToLower(c.Wrong)
+		if !seenWrong[key] {
+			seenWrong[key] = true
+			merged.Corrections = append(merged.Corrections, c)
+		}
+	}
+	return merged
+}
+
+var (
+	configGlossary     Glossary
+	configGlossaryErr  error
+	configGlossaryOnce sync.Once
+)
+
+// LoadGlossary loads the server-wide glossary from CLEAN_GLOSSARY and CLEAN_GLOSSARY_FILE.
+// Call it at startup to fail fast on an unreadable file; otherwise it runs on first use.
+func LoadGlossary() error {
+	configGlossaryOnce.Do(func() {
+		configGlossary, configGlossaryErr = loadGlossary(conf.CleanGlossary, conf.CleanGlossaryFile)
+	})
+	return configGlossaryErr
+}
+
+func loadGlossary(entries []string, path string) (Glossary, error) {
+	g := parseGlossary(entries)
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Glossary{}, fmt.Errorf("failed to read glossary file %s: %w", path, err)
+		}
+		g = g.Merge(parseGlossary(strings.Split(string(data), "\n")))
+	}
+	if !g.Empty() {
+		log.Printf("[Glossary] Loaded %d terms and %d corrections", len(g.Terms), len(g.Corrections))
+	}
+	return g, nil
+}
+
+// parseGlossary parses glossary entries: "term", or "wrong => right" (also "->" or "→").
+// Blank entries, "#" comments and corrections missing a side are skipped.
+func parseGlossary(entries []string) Glossary {
+	var g Glossary
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		if wrong, right, ok := cutCorrection(entry); ok {
+			if wrong == "" || right == "" {
+				log.Printf("Warning: glossary entry %q skipped, a correction needs both sides of the arrow", entry)
+				continue
+			}
+			g.Corrections = append(g.Corrections, GlossaryCorrection{Wrong: wrong, Right: right})
+			continue
+		}
+		g.Terms = append(g.Terms, entry)
+	}
+	return g.Merge(Glossary{})
+}
+
+// cutCorrection splits entry around its first correction arrow; ok is false for a plain term
+func cutCorrection(entry string) (wrong string, right string, ok bool) {
+	for _, sep := range []string{"=>", "->", "→"} {
+		if wrong, right, ok := strings.Cut(entry, sep); ok {
+			return strings.TrimSpace(wrong), strings.TrimSpace(right), true
+		}
+	}
+	return "", "", false
+}
+
+// cleanGlossary returns the server-wide glossary followed by the request's own entries
+func cleanGlossary(extra Glossary) Glossary {
+	if err := LoadGlossary(); err != nil {
+		log.Printf("Warning: glossary not loaded: %v", err)
+	}
+	return configGlossary.Merge(extra)
+}
+
+// glossaryPrompt is appended to the cleaning user prompt so the model keeps domain terms and
+// applies known corrections. Empty when there is no glossary.
+func glossaryPrompt(outputLanguage string, g Glossary) string {
+	if g.Empty() {
+		return ""
+	}
+	if n := len(g.Terms) + len(g.Corrections); n > maxGlossaryEntries {
+		log.Printf("Warning: glossary has %d entries, only the first %d are sent", n, maxGlossaryEntries)
+		if len(g.Corrections) > maxGlossaryEntries {
+			g.Corrections = g.Corrections[:maxGlossaryEntries]
+		}
+		if keep := maxGlossaryEntries - len(g.Corrections); len(g.Terms) > keep {
+			g.Terms = g.Terms[:keep]
+		}
+	}
+
+	var b strings.Builder
+	if outputLanguage == LanguageEnglish {
+		b.WriteString("\n\nGLOSSARY (domain vocabulary of this user, takes precedence over guesses):")
+		if len(g.Terms) > 0 {
+			b.WriteString("\n- Keep these terms exactly as spelled: " + strings.Join(g.Terms, ", "))
+		}
+		if len(g.Corrections) > 0 {
+			b.WriteString("\n- Known recognition errors (wrong → right), fix them and list them in decoded_words:")
+		}
+	} else {
+		b.WriteString("\n\nTỪ ĐIỂN RIÊNG (thuật ngữ của người dùng, ưu tiên hơn phỏng đoán):")
+		if len(g.Terms) > 0 {
+			b.WriteString("\n- Giữ nguyên đúng chính tả các thuật ngữ: " + strings.Join(g.Terms, ", "))
+		}
+		if len(g.Corrections) > 0 {
+			b.WriteString("\n- Lỗi nhận dạng đã biết (sai → đúng), hãy sửa và liệt kê trong decoded_words:")
+		}
+	}
+	for _, c := range g.Corrections {
+		b.WriteString("\n  \"" + c.Wrong + "\" → \"" + c.Right + "\"")
+	}
+	return b.String()
+}
+
+// applyCorrections replaces the glossary's wrong terms case-insensitively on word boundaries,
+// returning the new text and the applied corrections in decoded_words format
+func applyCorrections(text string, g Glossary) (string, []string) {
+	decoded := []string{}
+	for _, c := range g.Corrections {
+		re := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(c.Wrong))
+		var b strings.Builder
+		last := 0
+		for _, m := range re.FindAllStringIndex(text, -1) {
+			if !wordBoundary(text, m[0], m[1]) {
+				continue
+			}
+			b.WriteString(text[last:m[0]])
+			b.WriteString(c.Right)
+			last = m[1]
+		}
+		if last == 0 {
+			continue
+		}
+		b.WriteString(text[last:])
+		text = b.String()
+		decoded = append(decoded, c.Wrong+" → "+c.Right)
+	}
+	return text, decoded
+}
+
+// wordBoundary reports whether text[start:end] is not part of a longer word
+func wordBoundary(text string, start int, end int) bool {
+	if before, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isWordRune(before) {
+		return false
+	}
+	if after, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isWordRune(after) {
+		return false
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
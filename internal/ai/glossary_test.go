@@ -0,0 +1,196 @@
+package ai
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseGlossary(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    Glossary
+	}{
+		{
+			name:    "terms and the three arrows",
+			entries: []string{"NoteMe", "nút mi => NoteMe", "cu bơ nét -> Kubernetes", "gô lang → Go"},
+			want: Glossary{
+				Terms: []string{"NoteMe"},
+				Corrections: []GlossaryCorrection{
+					{"nút mi", "NoteMe"}, {"cu bơ nét", "Kubernetes"}, {"gô lang", "Go"},
+				},
+			},
+		},
+		{
+			name:    "blank entries, comments and surrounding spaces",
+			entries: []string{"", "   ", "# project names", "  #indented comment", "  Phượng Hoàng  ", "\tsprint\r"},
+			want:    Glossary{Terms: []string{"Phượng Hoàng", "sprint"}},
+		},
+		{
+			name:    "corrections missing a side are skipped",
+			entries: []string{"=> NoteMe", "nút mi =>", "->", " → Go", "Jira"},
+			want:    Glossary{Terms: []string{"Jira"}},
+		},
+		{
+			name:    "first arrow splits the entry",
+			entries: []string{"a => b -> c"},
+			want:    Glossary{Corrections: []GlossaryCorrection{{"a", "b -> c"}}},
+		},
+		{
+			name:    "duplicates merged case-insensitively, first spelling kept",
+			entries: []string{"NoteMe", "noteme", "NOTEME", "Nút Mi => NoteMe", "nút mi => Notes"},
+			want: Glossary{
+				Terms:       []string{"NoteMe"},
+				Corrections: []GlossaryCorrection{{"Nút Mi", "NoteMe"}},
+			},
+		},
+		{
+			name:    "nothing usable",
+			entries: []string{"", "# only a comment", "=>"},
+			want:    Glossary{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseGlossary(tt.entries); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseGlossary(%q) = %+v, want %+v", tt.entries, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyCorrectionsWordBoundaries(t *testing.T) {
+	tests := []struct {
+		name        string
+		corrections []GlossaryCorrection
+		text        string
+		want        string
+		wantDecoded []string
+	}{
+		{
+			name:        "whole words, any case",
+			corrections: []GlossaryCorrection{{"nút mi", "NoteMe"}},
+			text:        "Mở Nút Mi rồi ghi âm bằng nút mi.",
+			want:        "Mở NoteMe rồi ghi âm bằng NoteMe.",
+			wantDecoded: []string{"nút mi → NoteMe"},
+		},
+		{
+			name:        "Vietnamese letter before the match",
+			corrections: []GlossaryCorrection{{"an", "AN"}},
+			text:        "Toàn bàn an toàn",
+			want:        "Toàn bàn AN toàn",
+			wantDecoded: []string{"an → AN"},
+		},
+		{
+			name:        "diacritic letter after the match",
+			corrections: []GlossaryCorrection{{"ch", "CH"}},
+			text:        "chạy ch chữ",
+			want:        "chạy CH chữ",
+			wantDecoded: []string{"ch → CH"},
+		},
+		{
+			name:        "match inside a word only",
+			corrections: []GlossaryCorrection{{"ạ", "à"}},
+			text:        "Dạ vâng, cảm ơn chị nhạc",
+			want:        "Dạ vâng, cảm ơn chị nhạc",
+			wantDecoded: []string{},
+		},
+		{
+			name:        "uppercase diacritics fold",
+			corrections: []GlossaryCorrection{{"đề xuất", "proposal"}},
+			text:        "ĐỀ XUẤT mới: đề xuất cũ, đề xuấtt",
+			want:        "proposal mới: proposal cũ, đề xuấtt",
+			wantDecoded: []string{"đề xuất → proposal"},
+		},
+		{
+			name:        "digits are word characters",
+			corrections: []GlossaryCorrection{{"v2", "V2"}},
+			text:        "v2 và v21",
+			want:        "V2 và v21",
+			wantDecoded: []string{"v2 → V2"},
+		},
+		{
+			name:        "punctuation and start or end of text are boundaries",
+			corrections: []GlossaryCorrection{{"gô lang", "Go"}},
+			text:        "gô lang,(gô lang)gô lang",
+			want:        "Go,(Go)Go",
+			wantDecoded: []string{"gô lang → Go"},
+		},
+		{
+			name:        "regexp metacharacters are literal",
+			corrections: []GlossaryCorrection{{"c.", "C++"}},
+			text:        "ca c. cb",
+			want:        "ca C++ cb",
+			wantDecoded: []string{"c. → C++"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, decoded := applyCorrections(tt.text, Glossary{Corrections: tt.corrections})
+			if got != tt.want {
+				t.Errorf("applyCorrections(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+			if !reflect.DeepEqual(decoded, tt.wantDecoded) {
+				t.Errorf("decoded_words = %q, want %q", decoded, tt.wantDecoded)
+			}
+		})
+	}
+}
+
+func TestGlossaryPromptCap(t *testing.T) {
+	entries := func(n int, format string) []string {
+		list := make([]string, n)
+		for i := range list {
+			list[i] = fmt.Sprintf(format, i)
+		}
+		return list
+	}
+	corrections := func(n int) []GlossaryCorrection {
+		list := make([]GlossaryCorrection, n)
+		for i := range list {
+			list[i] = GlossaryCorrection{Wrong: fmt.Sprintf("wrong%d", i), Right: fmt.Sprintf("right%d", i)}
+		}
+		return list
+	}
+
+	tests := []struct {
+		name            string
+		glossary        Glossary
+		wantTerms       int
+		wantCorrections int
+	}{
+		{"under the cap", Glossary{Terms: entries(10, "term%d"), Corrections: corrections(10)}, 10, 10},
+		{"exactly the cap", Glossary{Terms: entries(maxGlossaryEntries-1, "term%d"), Corrections: corrections(1)}, maxGlossaryEntries - 1, 1},
+		{"terms truncated after corrections", Glossary{Terms: entries(400, "term%d"), Corrections: corrections(300)}, maxGlossaryEntries - 300, 300},
+		{"terms only", Glossary{Terms: entries(maxGlossaryEntries+50, "term%d")}, maxGlossaryEntries, 0},
+		{"corrections alone over the cap", Glossary{Terms: entries(5, "term%d"), Corrections: corrections(maxGlossaryEntries + 1)}, 0, maxGlossaryEntries},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompt := glossaryPrompt(LanguageEnglish, tt.glossary)
+			terms := 0
+			if _, list, ok := strings.Cut(prompt, "Keep these terms exactly as spelled: "); ok {
+				list, _, _ = strings.Cut(list, "\n")
+				terms = len(strings.Split(list, ", "))
+			}
+			if terms != tt.wantTerms {
+				t.Errorf("prompt lists %d terms, want %d", terms, tt.wantTerms)
+			}
+			if got := strings.Count(prompt, "\n  \"wrong"); got != tt.wantCorrections {
+				t.Errorf("prompt lists %d corrections, want %d", got, tt.wantCorrections)
+			}
+			// The first entries are the ones kept
+			if tt.wantTerms > 0 && !strings.Contains(prompt, "spelled: term0, term1") {
+				t.Error("prompt does not keep the first terms")
+			}
+			if tt.wantCorrections > 0 && !strings.Contains(prompt, `"wrong0" → "right0"`) {
+				t.Error("prompt does not keep the first correction")
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Supported output languages for analysis and cleaning
+const (
+	LanguageVietnamese = "vi"
+	LanguageEnglish    = "en"
+)
+
+// NormalizeOutputLanguage validates an output language, defaulting to Vietnamese
+func NormalizeOutputLanguage(lang string) (string, error) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	switch lang {
+	case "":
+		return LanguageVietnamese, nil
+	case LanguageVietnamese, LanguageEnglish:
+		return lang, nil
+	default:
+		return "", fmt.Errorf("unsupported output_language: %s. Supported: vi, en", lang)
+	}
+}
@@ -0,0 +1,40 @@
+package ai
+
+import (
+	"sync"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Metrics receives OpenAI token usage; the default implementation does nothing
+// so this package does not depend on any metrics backend.
+type Metrics interface {
+	AddTokens(operation string, promptTokens, completionTokens int)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) AddTokens(string, int, int) {}
+
+var (
+	metrics   Metrics = noopMetrics{}
+	muMetrics sync.RWMutex
+)
+
+// SetMetrics installs the AI metrics sink
+func SetMetrics(m Metrics) {
+	muMetrics.Lock()
+	defer muMetrics.Unlock()
+	if m == nil {
+		m = noopMetrics{}
+	}
+	metrics = m
+}
+
+// recordUsage reports token usage of an OpenAI call (operation: analyze, clean, ask, title, embed)
+func recordUsage(operation string, usage openai.Usage) {
+	muMetrics.RLock()
+	m := metrics
+	muMetrics.RUnlock()
+	m.AddTokens(operation, usage.PromptTokens, usage.CompletionTokens)
+}
@@ -0,0 +1,116 @@
+package ai
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+)
+
+// Analyzer replaces the OpenAI calls behind AnalyzeTranscript and CleanTranscriptDetailed
+// (see SetAnalyzer). Other AI features (Ask Anything, digest, V1 analysis) still need OpenAI.
+type Analyzer interface {
+	Analyze(ctx context.Context, transcript string, detectedContext string, outputLanguage string) (*AnalysisResult, error)
+	Clean(ctx context.Context, transcript string, outputLanguage string, opts CleanOptions) (*CleanedTranscriptResult, error)
+}
+
+var (
+	analyzerMu sync.RWMutex
+	analyzer   Analyzer
+)
+
+// SetAnalyzer routes analysis and cleaning to a, e.g. MockAnalyzer with AI_PROVIDER=mock.
+// nil restores OpenAI. ENABLE_AI=false still disables both.
+func SetAnalyzer(a Analyzer) {
+	analyzerMu.Lock()
+	defer analyzerMu.Unlock()
+	analyzer = a
+}
+
+// currentAnalyzer returns the analyzer set with SetAnalyzer, or nil for OpenAI
+func currentAnalyzer() Analyzer {
+	analyzerMu.RLock()
+	defer analyzerMu.RUnlock()
+	return analyzer
+}
+
+// mockActionMarkers flag sentences MockAnalyzer reports as action items
+var mockActionMarkers = []string{"cần", "phải", "sẽ", "need", "must", "will"}
+
+// MockAnalyzer derives deterministic results from the transcript text without any API call,
+// for local development and handler tests
+type MockAnalyzer struct{}
+
+// Analyze returns the first sentences as summary and key points and sentences with an action
+// marker ("cần", "sẽ", "need", ...) as action items
+func (MockAnalyzer) Analyze(ctx context.Context, transcript string, detectedContext string, outputLanguage string) (*AnalysisResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if detectedContext == "" {
+		detectedContext = DetectContext(transcript)
+	}
+	language, err := NormalizeOutputLanguage(outputLanguage)
+	if err != nil {
+		return nil, err
+	}
+
+	sentences := splitSentences(transcript)
+	summary := firstN(sentences, 3)
+	actionItems := []string{}
+	for _, sentence := range sentences {
+		lower := strings.ToLower(sentence)
+		for _, marker := range mockActionMarkers {
+			if strings.Contains(lower, marker+" ") {
+				actionItems = append(actionItems, sentence)
+				break
+			}
+		}
+	}
+
+	title := ""
+	if len(summary) > 0 {
+		words := strings.Fields(summary[0])
+		title = strings.Join(firstN(words, 10), " ")
+	}
+
+	log.Printf("[Mock AI] Analyzed transcript (%d sentences, %d action items)", len(sentences), len(actionItems))
+	return &AnalysisResult{
+		Context:     detectedContext,
+		Title:       title,
+		Summary:     summary,
+		ActionItems: actionItems,
+		KeyPoints:   firstN(sentences, 5),
+		Questions:   []string{},
+		Entities:    []Entity{},
+		Confidence:  1,
+		Language:    language,
+	}, nil
+}
+
+// Clean runs the rule-based CleanTranscript, applies the glossary corrections and uses the first sentence as summary
+func (MockAnalyzer) Clean(ctx context.Context, transcript string, outputLanguage string, opts CleanOptions) (*CleanedTranscriptResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	cleaned := CleanTranscript(transcript)
+	if cleaned == "" {
+		cleaned = strings.TrimSpace(transcript)
+	}
+	cleaned, decoded := applyCorrections(cleaned, cleanGlossary(opts.Glossary))
+
+	summary := ""
+	if sentences := splitSentences(cleaned); len(sentences) > 0 {
+		summary = sentences[0]
+	}
+
+	log.Printf("[Mock AI] Cleaned transcript: %d -> %d characters", len(transcript), len(cleaned))
+	return &CleanedTranscriptResult{CleanedText: cleaned, Summary: summary, DecodedWords: decoded}, nil
+}
+
+func firstN(items []string, n int) []string {
+	if len(items) > n {
+		items = items[:n]
+	}
+	return append([]string{}, items...)
+}
@@ -5,10 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"os"
 	"strings"
 
 	"github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // AnalysisResult represents the AI analysis result
@@ -20,14 +20,28 @@ type AnalysisResult struct {
 	KeyPoints   []string `json:"key_points"`
 	ZaloBrief   string   `json:"zalo_brief,omitempty"`
 	Questions   []string `json:"questions"`
+	Entities    []Entity `json:"entities"`
 	Confidence  float64  `json:"confidence_score,omitempty"`
+	Language    string   `json:"language,omitempty"`  // output language (vi, en)
+	Chunks      int      `json:"chunks,omitempty"`    // number of chunks merged when the transcript exceeded the token budget
+	Truncated   bool     `json:"truncated,omitempty"` // part of the transcript was not analyzed
+
+	// PromptTemplate names the system prompt used ("default", "custom:<hash>" or "experiment:<name>:B"),
+	// SystemPrompt holds the custom text
+	PromptTemplate string `json:"prompt_template,omitempty"`
+	SystemPrompt   string `json:"system_prompt,omitempty"`
+
+	// Version counts the analyses of a recording (1 for the first, bumped on every re-analyze) and
+	// TranscriptHash is the SHA-256 of the transcript it was derived from (see storage.HashTranscript)
+	Version        int    `json:"analysis_version,omitempty"`
+	TranscriptHash string `json:"transcript_hash,omitempty"`
 }
 
-// AnalyzeTranscript analyzes transcript using OpenAI API
-func AnalyzeTranscript(transcript string, detectedContext string) (*AnalysisResult, error) {
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
-		return nil, fmt.Errorf("OPENAI_API_KEY environment variable is not set")
+// analyzeTranscriptOnce analyzes a transcript that fits the token budget with a single OpenAI call
+func analyzeTranscriptOnce(ctx context.Context, transcript string, detectedContext string, outputLanguage string) (*AnalysisResult, error) {
+	apiKey, err := openAIKey()
+	if err != nil {
+		return nil, err
 	}
 
 	// Use rule-based context detection if not provided
@@ -35,11 +49,17 @@ func AnalyzeTranscript(transcript string, detectedContext string) (*AnalysisResu
 		detectedContext = DetectContext(transcript)
 	}
 
+	if outputLanguage == "" {
+		outputLanguage = LanguageVietnamese
+	}
+
 	// Build prompt (using simple version from day2.md)
-	systemPrompt, userPrompt := BuildPrompt(transcript, detectedContext)
+	systemPrompt, userPrompt := BuildPromptForLanguage(transcript, detectedContext, outputLanguage)
+	systemPrompt = applySystemPrompt(ctx, systemPrompt)
 
 	log.Printf("=== OpenAI Analysis Request ===")
 	log.Printf("Detected context: %s", detectedContext)
+	log.Printf("Output language: %s", outputLanguage)
 	log.Printf("Transcript length: %d characters", len(transcript))
 	log.Printf("System prompt length: %d characters", len(systemPrompt))
 	log.Printf("User prompt length: %d characters", len(userPrompt))
@@ -48,11 +68,17 @@ func AnalyzeTranscript(transcript string, detectedContext string) (*AnalysisResu
 	client := openai.NewClient(apiKey)
 
 	// Call OpenAI API
-	ctx := context.Background()
+	ctx, span := startSpan(ctx, "ai.AnalyzeTranscript",
+		attribute.String("ai.context", detectedContext),
+		attribute.String("ai.output_language", outputLanguage),
+		attribute.Int("ai.transcript_length", len(transcript)))
+	defer span.End()
+	ctx, cancel := withOpenAITimeout(ctx)
+	defer cancel()
 	log.Printf("Calling OpenAI API with model: GPT-4o-mini")
 
 	req := openai.ChatCompletionRequest{
-		Model: openai.GPT4oMini, // Using GPT-4o-mini as per MVP plan
+		Model: conf.OpenAIModel, // gpt-4o-mini by default, as per MVP plan
 		Messages: []openai.ChatCompletionMessage{
 			{
 				Role:    openai.ChatMessageRoleSystem,
@@ -68,14 +94,18 @@ func AnalyzeTranscript(transcript string, detectedContext string) (*AnalysisResu
 			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
 		},
 	}
+	applyGenerationParams(ctx, &req)
 
 	resp, err := client.CreateChatCompletion(ctx, req)
 
 	if err != nil {
 		log.Printf("OpenAI API error: %v", err)
-		return nil, fmt.Errorf("OpenAI API error: %w", err)
+		err = wrapOpenAIError(err)
+		recordSpanError(span, err)
+		return nil, err
 	}
 
+	recordUsage("analyze", resp.Usage)
 	log.Printf("OpenAI API response received")
 	log.Printf("Number of choices: %d", len(resp.Choices))
 	log.Printf("Usage - Prompt tokens: %d, Completion tokens: %d, Total tokens: %d",
@@ -136,12 +166,18 @@ func AnalyzeTranscript(transcript string, detectedContext string) (*AnalysisResu
 		log.Printf("Questions: %v", result.Questions)
 	}
 
+	result.Entities = normalizeEntities(result.Entities)
+
 	// Set context if not in response
 	if result.Context == "" {
 		log.Printf("Context missing in response, using detected context: %s", detectedContext)
 		result.Context = detectedContext
 	}
 
+	// Record the prompt for reproducibility
+	result.PromptTemplate = PromptTemplateFromContext(ctx)
+	result.SystemPrompt, _ = customSystemPrompt(ctx)
+
 	// Generate zalo_brief from summary if missing
 	if result.ZaloBrief == "" && len(result.Summary) > 0 {
 		log.Printf("Zalo brief is empty, generating from summary...")
@@ -181,12 +217,27 @@ func AnalyzeTranscript(transcript string, detectedContext string) (*AnalysisResu
 			"Có những điểm quan trọng nào cần lưu ý?",
 			"Cần thực hiện những hành động gì tiếp theo?",
 		}
+		if outputLanguage == LanguageEnglish {
+			defaultQuestions = []string{
+				"What are the details of this content?",
+				"What are the important points to note?",
+				"What actions need to be taken next?",
+			}
+		}
 		// Add more questions if we have context
 		if result.Context != "" {
-			defaultQuestions = append(defaultQuestions, fmt.Sprintf("Bối cảnh %s này có ý nghĩa gì?", result.Context))
+			if outputLanguage == LanguageEnglish {
+				defaultQuestions = append(defaultQuestions, fmt.Sprintf("What is the significance of this %s?", result.Context))
+			} else {
+				defaultQuestions = append(defaultQuestions, fmt.Sprintf("Bối cảnh %s này có ý nghĩa gì?", result.Context))
+			}
 		}
 		if len(result.ActionItems) > 0 {
-			defaultQuestions = append(defaultQuestions, "Các action items cụ thể là gì?")
+			if outputLanguage == LanguageEnglish {
+				defaultQuestions = append(defaultQuestions, "What are the specific action items?")
+			} else {
+				defaultQuestions = append(defaultQuestions, "Các action items cụ thể là gì?")
+			}
 		}
 		// Take first 5 questions
 		if len(defaultQuestions) > 5 {
@@ -215,6 +266,8 @@ func AnalyzeTranscript(transcript string, detectedContext string) (*AnalysisResu
 		log.Printf("WARNING: Zalo brief is still empty after fallback")
 	}
 
+	result.Language = outputLanguage
+
 	log.Printf("=== Analysis Complete ===")
 	return &result, nil
 }
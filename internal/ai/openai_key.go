@@ -0,0 +1,39 @@
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrAINotConfigured is returned by AI calls when AI features are disabled (ENABLE_AI=false)
+// or OPENAI_API_KEY is not set
+var ErrAINotConfigured = errors.New("AI is not configured")
+
+var aiDisabled atomic.Bool
+
+// SetEnabled turns the AI features on or off (ENABLE_AI, applied by Configure)
+func SetEnabled(enabled bool) {
+	aiDisabled.Store(!enabled)
+}
+
+// Available reports whether transcripts can be cleaned and analyzed (by OpenAI or the Analyzer set with SetAnalyzer)
+func Available() bool {
+	if currentAnalyzer() != nil {
+		return !aiDisabled.Load()
+	}
+	_, err := openAIKey()
+	return err == nil
+}
+
+// openAIKey returns OPENAI_API_KEY, or an ErrAINotConfigured error explaining why AI is unavailable
+func openAIKey() (string, error) {
+	if aiDisabled.Load() {
+		return "", fmt.Errorf("%w: AI features are disabled (ENABLE_AI=false)", ErrAINotConfigured)
+	}
+	apiKey := conf.OpenAIKey
+	if apiKey == "" {
+		return "", fmt.Errorf("%w: OPENAI_API_KEY is not set", ErrAINotConfigured)
+	}
+	return apiKey, nil
+}
@@ -0,0 +1,65 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Prompt versions that can be previewed
+const (
+	PromptVersionDefault = "default" // BuildPrompt / BuildPromptEnglish, used by AnalyzeTranscript
+	PromptVersionV1      = "v1"      // BuildPromptV1 (Prompt Engine v1 spec)
+)
+
+// PromptPreview is the exact request an analysis would send to OpenAI
+type PromptPreview struct {
+	Model           string `json:"model"`
+	PromptVersion   string `json:"prompt_version"`
+	PromptTemplate  string `json:"prompt_template"`
+	Context         string `json:"context"`
+	OutputLanguage  string `json:"output_language"`
+	SystemPrompt    string `json:"system_prompt"`
+	UserPrompt      string `json:"user_prompt"`
+	EstimatedTokens int    `json:"estimated_tokens"`
+}
+
+// PreviewAnalysisPrompt builds the prompts for an analysis without calling OpenAI.
+// Context detection runs the same way as in AnalyzeTranscript when detectedContext is empty,
+// and a system prompt override in ctx (see WithSystemPrompt) is applied.
+func PreviewAnalysisPrompt(ctx context.Context, transcript string, detectedContext string, outputLanguage string, version string) (*PromptPreview, error) {
+	if fastCleanEnabled(ctx) {
+		transcript = fastClean("analysis preview", transcript)
+	}
+	if detectedContext == "" {
+		detectedContext = DetectContext(transcript)
+	}
+	if outputLanguage == "" {
+		outputLanguage = LanguageVietnamese
+	}
+
+	var systemPrompt, userPrompt string
+	switch strings.ToLower(strings.TrimSpace(version)) {
+	case "", PromptVersionDefault:
+		version = PromptVersionDefault
+		systemPrompt, userPrompt = BuildPromptForLanguage(transcript, detectedContext, outputLanguage)
+	case PromptVersionV1:
+		version = PromptVersionV1
+		systemPrompt, userPrompt = BuildPromptV1(transcript)
+	default:
+		return nil, fmt.Errorf("unsupported prompt_version: %s. Supported: %s, %s", version, PromptVersionDefault, PromptVersionV1)
+	}
+
+	systemPrompt = applySystemPrompt(ctx, systemPrompt)
+
+	return &PromptPreview{
+		Model:           conf.OpenAIModel,
+		PromptVersion:   version,
+		PromptTemplate:  PromptTemplateFromContext(ctx),
+		Context:         detectedContext,
+		OutputLanguage:  outputLanguage,
+		SystemPrompt:    systemPrompt,
+		UserPrompt:      userPrompt,
+		EstimatedTokens: EstimatePromptTokens(systemPrompt, userPrompt),
+	}, nil
+}
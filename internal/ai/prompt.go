@@ -2,7 +2,6 @@ package ai
 
 import (
 	"fmt"
-	"strings"
 )
 
 // BuildPrompt builds the complete prompt for LLM
@@ -34,6 +33,7 @@ Nhiệm vụ:
 4. Trích xuất các sự kiện quan trọng, số liệu, tên, hoặc cam kết - BẮT BUỘC, phải là mảng các chuỗi tiếng Việt (có thể rỗng nếu không có).
 5. Tạo tóm tắt ngắn cho Zalo (tối đa 3 điểm) - BẮT BUỘC, phải là chuỗi tiếng Việt (có thể rỗng nếu không có nội dung).
 6. Tạo 3 đến 5 câu hỏi gợi ý để người dùng có thể hỏi thêm về nội dung - BẮT BUỘC, phải là mảng các chuỗi tiếng Việt (tối thiểu 3, tối đa 5 câu hỏi).
+7. Trích xuất các thực thể được nhắc đến (người, dự án, công nghệ, tổ chức) - BẮT BUỘC, phải là mảng các object (có thể rỗng nếu không có).
 
 QUY TẮC QUAN TRỌNG:
 - TẤT CẢ các trường đều BẮT BUỘC trong JSON response.
@@ -43,6 +43,7 @@ QUY TẮC QUAN TRỌNG:
 - key_points: mảng các chuỗi tiếng Việt, trích xuất các sự kiện/số liệu/tên/cam kết quan trọng, có thể rỗng [] nếu không có
 - zalo_brief: chuỗi tiếng Việt, định dạng 3 điểm như "- Điểm 1\n- Điểm 2\n- Điểm 3", có thể là chuỗi rỗng "" nếu không có nội dung
 - questions: mảng các chuỗi tiếng Việt, từ 3 đến 5 câu hỏi gợi ý để người dùng có thể hỏi thêm về nội dung, ví dụ: "Chi tiết về [chủ đề] là gì?", "Có những action items nào cần thực hiện?", "Kết quả của [sự kiện] như thế nào?"
+- entities: mảng các object {"type", "text"}, type là một trong "person", "project", "technology", "organization", "other"; text giữ nguyên như trong transcript (tên người, tên dự án, keywords công nghệ như Golang, Flutter, OpenAI), không dịch, không lặp lại, có thể rỗng [] nếu không có
 - Nếu transcript về lecture/thinking, key_points nên chứa các ý tưởng/khái niệm chính
 - Nếu transcript về meeting, action_items nên chứa các nhiệm vụ/cam kết
 - TẤT CẢ nội dung phải bằng TIẾNG VIỆT, chỉ giữ keywords chuyên ngành bằng tiếng Anh (API, Backend, MVP, etc.)
@@ -56,7 +57,8 @@ Trả về JSON chính xác theo format sau (TẤT CẢ các trường bắt bu
   "action_items": ["nhiệm vụ 1", "nhiệm vụ 2"],
   "key_points": ["sự kiện 1", "sự kiện 2"],
   "zalo_brief": "- Điểm 1\\n- Điểm 2\\n- Điểm 3",
-  "questions": ["Câu hỏi 1?", "Câu hỏi 2?", "Câu hỏi 3?"]
+  "questions": ["Câu hỏi 1?", "Câu hỏi 2?", "Câu hỏi 3?"],
+  "entities": [{"type": "person", "text": "Tên người"}, {"type": "technology", "text": "Golang"}]
 }
 
 QUAN TRỌNG: Bạn PHẢI cung cấp tất cả các trường:
@@ -66,11 +68,72 @@ QUAN TRỌNG: Bạn PHẢI cung cấp tất cả các trường:
 - key_points: mảng (PHẢI trích xuất các sự kiện/số liệu/tên/ý tưởng quan trọng, chỉ rỗng [] nếu thực sự không có thông tin quan trọng)
 - zalo_brief: chuỗi (PHẢI cung cấp định dạng 3 điểm, chỉ dùng chuỗi rỗng "" nếu transcript hoàn toàn trống)
 - questions: PHẢI có từ 3 đến 5 câu hỏi gợi ý bằng tiếng Việt, giúp người dùng khám phá thêm nội dung
+- entities: mảng (chỉ rỗng [] nếu transcript không nhắc đến người, dự án, công nghệ hay tổ chức nào)
 - TẤT CẢ nội dung phải bằng TIẾNG VIỆT, chỉ giữ keywords chuyên ngành bằng tiếng Anh`, transcript, context, context)
 
 	return systemPrompt, userPrompt
 }
 
+// BuildPromptForLanguage builds the analysis prompt for the requested output language
+func BuildPromptForLanguage(transcript string, context string, language string) (string, string) {
+	if language == LanguageEnglish {
+		return BuildPromptEnglish(transcript, context)
+	}
+	return BuildPrompt(transcript, context)
+}
+
+// BuildPromptEnglish builds the analysis prompt with English output
+func BuildPromptEnglish(transcript string, context string) (string, string) {
+	systemPrompt := `You are NoteMe's AI assistant analyzing Vietnamese voice recordings.
+You must be accurate, neutral and factual.
+DO NOT invent information.
+ONLY use information present in the transcript.
+Return valid JSON.
+ALL fields are REQUIRED, even if some are empty arrays.
+
+LANGUAGE:
+- The transcript is in Vietnamese, but ALL output must be in ENGLISH
+- Keep proper names (people, projects, companies) as spoken
+- Keep technical terms as-is (API, Backend, MVP, STT, OpenAI, FPT.AI, Golang, Flutter, etc.)`
+
+	userPrompt := fmt.Sprintf(`Transcript:
+"""
+%s
+"""
+
+Context: %s
+
+Tasks:
+1. Create a short title (max 10 words) - REQUIRED, English string.
+2. Write a short summary (max 5 points) - REQUIRED, array of English strings.
+3. Extract clear action items, if any - REQUIRED, array of English strings (may be empty).
+4. Extract important facts, numbers, names, or commitments - REQUIRED, array of English strings (may be empty).
+5. Create a short brief for chat sharing (max 3 points) - REQUIRED, English string (may be empty if there is no content).
+6. Create 3 to 5 suggested follow-up questions the user could ask about the content - REQUIRED, array of English strings.
+7. Extract the named entities mentioned (people, projects, technologies, organizations) - REQUIRED, array of objects (may be empty).
+
+IMPORTANT RULES:
+- entities: objects {"type", "text"} where type is one of "person", "project", "technology", "organization", "other"; keep text as spoken (names, project names, tech terms like Golang, Flutter, OpenAI), no duplicates
+- If the transcript is a lecture/thinking, key_points should contain the main ideas/concepts
+- If the transcript is a meeting, action_items should contain tasks/commitments
+- ALL content must be in ENGLISH
+
+Return JSON exactly in this format (ALL fields required, use [] or "" when there is no data):
+
+{
+  "context": "%s",
+  "title": "Short title of the content",
+  "summary": ["point 1", "point 2"],
+  "action_items": ["task 1", "task 2"],
+  "key_points": ["fact 1", "fact 2"],
+  "zalo_brief": "- Point 1\\n- Point 2\\n- Point 3",
+  "questions": ["Question 1?", "Question 2?", "Question 3?"],
+  "entities": [{"type": "person", "text": "Person name"}, {"type": "technology", "text": "Golang"}]
+}`, transcript, context, context)
+
+	return systemPrompt, userPrompt
+}
+
 // BuildPromptV1 builds prompt according to NoteMe Prompt Engine v1 spec
 func BuildPromptV1(transcript string) (string, string) {
 	systemPrompt := `You are NoteMe's AI brain - an advanced assistant for Vietnamese users. 
@@ -114,24 +177,3 @@ Return ONLY valid JSON (no extra text):
 
 	return systemPrompt, userPrompt
 }
-
-// CleanTranscript removes noise from transcript
-func CleanTranscript(transcript string) string {
-	// Remove common noise patterns
-	noisePatterns := []string{
-		"xin chào", "chào bạn", "hello", "hi",
-		"test mic", "thử mic", "check mic",
-		"được rồi", "ok", "okay", "ừ", "ừm",
-	}
-
-	cleaned := transcript
-	for _, pattern := range noisePatterns {
-		cleaned = strings.ReplaceAll(strings.ToLower(cleaned), pattern, "")
-	}
-
-	// Remove extra whitespace
-	cleaned = strings.TrimSpace(cleaned)
-	cleaned = strings.ReplaceAll(cleaned, "  ", " ")
-
-	return cleaned
-}
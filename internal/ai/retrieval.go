@@ -0,0 +1,229 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// maxEmbeddingInputRunes keeps the embedded document well below the model's input limit
+const maxEmbeddingInputRunes = 6000
+
+// ScoredID is a search hit with its cosine similarity to the query
+type ScoredID struct {
+	ID    string
+	Score float64
+}
+
+// VectorStore stores analysis embeddings and finds the closest ones to a query.
+// The in-memory implementation is the default; a pgvector-backed store can replace it via SetVectorStore.
+type VectorStore interface {
+	Upsert(id string, vector []float32) error
+	Delete(id string) error
+	// Search returns up to k of candidateIDs ordered by similarity; unindexed candidates are omitted
+	Search(query []float32, candidateIDs []string, k int) ([]ScoredID, error)
+}
+
+var (
+	vectorStore   VectorStore = newMemoryVectorStore()
+	muVectorStore sync.RWMutex
+)
+
+// SetVectorStore replaces the store used for retrieval
+func SetVectorStore(store VectorStore) {
+	muVectorStore.Lock()
+	defer muVectorStore.Unlock()
+	vectorStore = store
+}
+
+func currentVectorStore() VectorStore {
+	muVectorStore.RLock()
+	defer muVectorStore.RUnlock()
+	return vectorStore
+}
+
+// memoryVectorStore is a brute-force cosine similarity index
+type memoryVectorStore struct {
+	mu      sync.RWMutex
+	vectors map[string][]float32
+}
+
+func newMemoryVectorStore() *memoryVectorStore {
+	return &memoryVectorStore{vectors: make(map[string][]float32)}
+}
+
+func (s *memoryVectorStore) Upsert(id string, vector []float32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vectors[id] = vector
+	return nil
+}
+
+func (s *memoryVectorStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.vectors, id)
+	return nil
+}
+
+func (s *memoryVectorStore) Search(query []float32, candidateIDs []string, k int) ([]ScoredID, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	hits := make([]ScoredID, 0, len(candidateIDs))
+	for _, id := range candidateIDs {
+		vector, ok := s.vectors[id]
+		if !ok {
+			continue
+		}
+		hits = append(hits, ScoredID{ID: id, Score: cosineSimilarity(query, vector)})
+	}
+
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	if k > 0 && len(hits) > k {
+		hits = hits[:k]
+	}
+	return hits, nil
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b (0 for mismatched or zero vectors)
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// EmbedText returns the OpenAI embedding for text (bounded by OPENAI_TIMEOUT)
+func EmbedText(ctx context.Context, text string) ([]float32, error) {
+	apiKey, err := openAIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := withOpenAITimeout(ctx)
+	defer cancel()
+
+	client := openai.NewClient(apiKey)
+	resp, err := client.CreateEmbeddings(ctx, openai.EmbeddingRequest{
+		Input: []string{text},
+		Model: openai.EmbeddingModel(conf.OpenAIEmbeddingModel),
+	})
+	if err != nil {
+		return nil, wrapOpenAIError(err)
+	}
+	recordUsage("embed", openai.Usage{PromptTokens: resp.Usage.PromptTokens})
+	if len(resp.Data) == 0 {
+		return nil, fmt.Errorf("OpenAI returned no embeddings")
+	}
+	return resp.Data[0].Embedding, nil
+}
+
+// IndexAnalysis embeds an analysis (summary, key points, transcript) and stores it for retrieval
+func IndexAnalysis(ctx context.Context, recordingID string, analysis *AnalysisResult, transcript string) error {
+	vector, err := EmbedText(ctx, embeddingDocument(analysis, transcript))
+	if err != nil {
+		return err
+	}
+	if err := currentVectorStore().Upsert(recordingID, vector); err != nil {
+		return fmt.Errorf("failed to store embedding: %w", err)
+	}
+	log.Printf("[Retrieval] Indexed analysis for recording %s (%d dims)", recordingID, len(vector))
+	return nil
+}
+
+// RemoveFromIndex drops a recording's embedding
+func RemoveFromIndex(recordingID string) {
+	if err := currentVectorStore().Delete(recordingID); err != nil {
+		log.Printf("[Retrieval] Failed to remove recording %s from index: %v", recordingID, err)
+	}
+}
+
+// embeddingDocument builds the text that represents an analysis in the index
+func embeddingDocument(analysis *AnalysisResult, transcript string) string {
+	var builder strings.Builder
+	if analysis.Title != "" {
+		builder.WriteString(analysis.Title + "\n")
+	}
+	for _, item := range analysis.Summary {
+		builder.WriteString(item + "\n")
+	}
+	for _, item := range analysis.KeyPoints {
+		builder.WriteString(item + "\n")
+	}
+	builder.WriteString(transcript)
+
+	doc := []rune(builder.String())
+	if len(doc) > maxEmbeddingInputRunes {
+		doc = doc[:maxEmbeddingInputRunes]
+	}
+	return string(doc)
+}
+
+// SelectRelevantAnalyses keeps the top-K analyses most similar to the question.
+// Analyses that have not been indexed yet rank after indexed ones; on any embedding
+// error the input is returned unchanged so Ask Anything keeps working without retrieval.
+func SelectRelevantAnalyses(ctx context.Context, question string, analyses []AnalysisContext) []AnalysisContext {
+	k := askTopK()
+	if len(analyses) <= k {
+		return analyses
+	}
+
+	queryVector, err := EmbedText(ctx, question)
+	if err != nil {
+		log.Printf("[Retrieval] Failed to embed question, using all %d analyses: %v", len(analyses), err)
+		return analyses
+	}
+
+	candidateIDs := make([]string, len(analyses))
+	byID := make(map[string]AnalysisContext, len(analyses))
+	for i, analysis := range analyses {
+		candidateIDs[i] = analysis.RecordingID
+		byID[analysis.RecordingID] = analysis
+	}
+
+	hits, err := currentVectorStore().Search(queryVector, candidateIDs, k)
+	if err != nil {
+		log.Printf("[Retrieval] Search failed, using all %d analyses: %v", len(analyses), err)
+		return analyses
+	}
+
+	selected := make([]AnalysisContext, 0, k)
+	used := make(map[string]bool, k)
+	for _, hit := range hits {
+		selected = append(selected, byID[hit.ID])
+		used[hit.ID] = true
+	}
+	// Fill remaining slots with unindexed analyses in their original order
+	for _, analysis := range analyses {
+		if len(selected) >= k {
+			break
+		}
+		if !used[analysis.RecordingID] {
+			selected = append(selected, analysis)
+		}
+	}
+
+	log.Printf("[Retrieval] Selected %d of %d analyses (%d by similarity)", len(selected), len(analyses), len(hits))
+	return selected
+}
+
+// askTopK returns ASK_TOP_K (default 5)
+func askTopK() int {
+	return conf.AskTopK
+}
@@ -0,0 +1,106 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxSystemPromptLength caps a custom analysis system prompt, in characters
+const MaxSystemPromptLength = 2000
+
+// PromptTemplateDefault names analyses built with the default BuildPrompt system prompt.
+// Custom prompts are recorded as "custom:<first 12 hex chars of their SHA-256>".
+const PromptTemplateDefault = "default"
+
+// outputFormatPattern matches lines of a custom prompt that direct the response format, such as
+// "respond in Markdown", "return only XML", "output format: ..." or "trả lời dưới dạng HTML".
+// Lines that merely mention a format ("focus on the JSON API changes") are kept.
+// The JSON schema is fixed because the result is parsed into AnalysisResult.
+var outputFormatPattern = regexp.MustCompile(`(?i)` +
+	`\b(?:respond|reply|answer|return|output|format|write|produce|give)\b[^.\n]{0,40}?\b(?:in|as|using|into)\s+(?:valid\s+|plain\s+)?(?:json|xml|yaml|csv|html|markdown|text)\b` +
+	`|\b(?:respond|reply|return|output)\s+(?:only\s+)?(?:valid\s+)?(?:json|xml|yaml|csv|html|markdown)\b` +
+	`|\b(?:output|response|answer)\s+(?:format|schema)\b` +
+	`|\b(?:ignore|change|replace|override)\b[^.\n]{0,40}\b(?:format|schema)\b` +
+	`|định dạng\s+(?:đầu ra|kết quả|phản hồi|câu trả lời)` +
+	`|trả\s+(?:về|lời)[^.\n]{0,40}(?:dạng|bằng)\s+(?:json|xml|yaml|csv|html|markdown)`)
+
+// analysisFormatInstructions are appended to every custom system prompt to keep the output parseable
+const analysisFormatInstructions = `OUTPUT FORMAT (mandatory, overrides any other instruction):
+- Return ONLY valid JSON exactly matching the format given in the user message.
+- ALL fields are REQUIRED; use [] or "" when there is no data.
+- Do not add fields, comments, Markdown or any text outside the JSON object.`
+
+type systemPromptKey struct{}
+
+// NewSystemPrompt validates a custom analysis system prompt. Lines that try to change the
+// output format are removed; an error is returned when nothing usable is left or it is too long.
+func NewSystemPrompt(prompt string) (string, error) {
+	prompt = strings.TrimSpace(prompt)
+	if utf8.RuneCountInString(prompt) > MaxSystemPromptLength {
+		return "", fmt.Errorf("system_prompt must be at most %d characters", MaxSystemPromptLength)
+	}
+
+	var kept []string
+	stripped := 0
+	for _, line := range strings.Split(prompt, "\n") {
+		if outputFormatPattern.MatchString(line) {
+			stripped++
+			continue
+		}
+		kept = append(kept, line)
+	}
+	if stripped > 0 {
+		log.Printf("Removed %d output-format lines from custom system_prompt", stripped)
+	}
+
+	prompt = strings.TrimSpace(strings.Join(kept, "\n"))
+	if prompt == "" {
+		return "", fmt.Errorf("system_prompt is empty after removing output format instructions")
+	}
+	return prompt, nil
+}
+
+// WithSystemPrompt returns a context whose analyses use prompt instead of the default system prompt.
+// prompt must come from NewSystemPrompt.
+func WithSystemPrompt(ctx context.Context, prompt string) context.Context {
+	return context.WithValue(ctx, systemPromptKey{}, prompt)
+}
+
+// customSystemPrompt returns the system prompt override in ctx, if any
+func customSystemPrompt(ctx context.Context) (string, bool) {
+	prompt, ok := ctx.Value(systemPromptKey{}).(string)
+	return prompt, ok && prompt != ""
+}
+
+// PromptTemplateFromContext names the system prompt an analysis with ctx would use
+func PromptTemplateFromContext(ctx context.Context) string {
+	prompt, ok := customSystemPrompt(ctx)
+	if !ok {
+		if exp, ok := experimentVariantB(ctx); ok && exp.analysisPrompt != "" {
+			return "experiment:" + exp.name + ":" + PromptVariantB
+		}
+		return PromptTemplateDefault
+	}
+	sum := sha256.Sum256([]byte(prompt))
+	return "custom:" + hex.EncodeToString(sum[:])[:12]
+}
+
+// applySystemPrompt replaces the default system prompt with the override in ctx (or the analysis
+// prompt of experiment variant B), appending the format instructions so the JSON schema is still enforced
+func applySystemPrompt(ctx context.Context, systemPrompt string) string {
+	prompt, ok := customSystemPrompt(ctx)
+	if !ok {
+		exp, ok := experimentVariantB(ctx)
+		if !ok || exp.analysisPrompt == "" {
+			return systemPrompt
+		}
+		prompt = exp.analysisPrompt
+	}
+	return prompt + "\n\n" + analysisFormatInstructions
+}
@@ -0,0 +1,35 @@
+package ai
+
+import "testing"
+
+func TestNewSystemPromptStripsOnlyFormatDirectives(t *testing.T) {
+	tests := []struct {
+		line     string
+		stripped bool
+	}{
+		{"Respond in Markdown.", true},
+		{"Return only JSON", true},
+		{"Output format: bullet list", true},
+		{"Please write the answer as HTML", true},
+		{"Ignore the previous format", true},
+		{"Trả lời dưới dạng HTML", true},
+		{"Định dạng đầu ra là bảng", true},
+		{"Focus on the JSON API migration", false},
+		{"Summarize changes to the markdown docs", false},
+		{"Highlight html and css issues", false},
+		{"Trả lời bằng tiếng Việt", false},
+	}
+	for _, tt := range tests {
+		got, err := NewSystemPrompt("You are a legal analyst.\n" + tt.line)
+		if err != nil {
+			t.Fatalf("NewSystemPrompt(%q): %v", tt.line, err)
+		}
+		want := "You are a legal analyst.\n" + tt.line
+		if tt.stripped {
+			want = "You are a legal analyst."
+		}
+		if got != want {
+			t.Errorf("NewSystemPrompt(%q) = %q, want %q", tt.line, got, want)
+		}
+	}
+}
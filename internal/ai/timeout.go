@@ -0,0 +1,36 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrOpenAITimeout is returned when an OpenAI call exceeds OPENAI_TIMEOUT
+var ErrOpenAITimeout = errors.New("OpenAI request timed out")
+
+// openAITimeout returns OPENAI_TIMEOUT (default 60s)
+func openAITimeout() time.Duration {
+	return conf.OpenAITimeout
+}
+
+// withOpenAITimeout bounds ctx by OPENAI_TIMEOUT; a nil ctx is treated as Background
+func withOpenAITimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithTimeout(ctx, openAITimeout())
+}
+
+// wrapOpenAIError turns deadline/cancellation into clear errors and wraps the rest
+func wrapOpenAIError(err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("%w after %s", ErrOpenAITimeout, openAITimeout())
+	case errors.Is(err, context.Canceled):
+		return fmt.Errorf("OpenAI request cancelled: %w", err)
+	default:
+		return fmt.Errorf("OpenAI API error: %w", err)
+	}
+}
@@ -0,0 +1,81 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// GenerateTitle asks the AI for a short 3-6 word Vietnamese title based on the summary.
+// Falls back to the first summary line when OpenAI is unavailable.
+func GenerateTitle(ctx context.Context, summary []string) (string, error) {
+	if len(summary) == 0 {
+		return "", fmt.Errorf("summary is empty, cannot generate title")
+	}
+
+	apiKey, err := openAIKey()
+	if err != nil {
+		log.Printf("%v, deriving title from summary", err)
+		return titleFromSummary(summary), nil
+	}
+
+	systemPrompt := `Bạn là trợ lý AI của NoteMe. Nhiệm vụ: đặt tiêu đề ngắn gọn cho bản ghi âm.
+- Tiêu đề từ 3 đến 6 từ, bằng TIẾNG VIỆT
+- CHỈ giữ keywords chuyên ngành bằng tiếng Anh (API, MVP, Deadline, Task, etc.)
+- Không dùng dấu ngoặc kép, không kết thúc bằng dấu chấm
+- Chỉ trả về tiêu đề, không giải thích`
+
+	userPrompt := fmt.Sprintf("Tóm tắt nội dung:\n- %s\n\nTiêu đề:", strings.Join(summary, "\n- "))
+
+	client := openai.NewClient(apiKey)
+	ctx, cancel := withOpenAITimeout(ctx)
+	defer cancel()
+	log.Printf("Calling OpenAI API to generate title...")
+
+	req := openai.ChatCompletionRequest{
+		Model: conf.OpenAIModel,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: systemPrompt,
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: userPrompt,
+			},
+		},
+		Temperature: 0.3,
+		MaxTokens:   30, // A title is only a few words
+	}
+
+	resp, err := client.CreateChatCompletion(ctx, req)
+	if err != nil {
+		log.Printf("OpenAI API error while generating title: %v. Deriving from summary.", err)
+		return titleFromSummary(summary), nil
+	}
+
+	recordUsage("title", resp.Usage)
+	if len(resp.Choices) == 0 {
+		return titleFromSummary(summary), nil
+	}
+
+	title := strings.Trim(strings.TrimSpace(resp.Choices[0].Message.Content), `"'.`)
+	if title == "" {
+		return titleFromSummary(summary), nil
+	}
+
+	log.Printf("Generated title: %s", title)
+	return title, nil
+}
+
+// titleFromSummary derives a title from the first summary line (max 6 words)
+func titleFromSummary(summary []string) string {
+	words := strings.Fields(summary[0])
+	if len(words) > 6 {
+		words = words[:6]
+	}
+	return strings.TrimRight(strings.Join(words, " "), ".,;:")
+}
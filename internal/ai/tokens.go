@@ -0,0 +1,52 @@
+package ai
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// perMessageTokenOverhead approximates the role/formatting tokens the chat API adds to each message
+const perMessageTokenOverhead = 4
+
+// EstimateTokens approximates the OpenAI token count of text without a tokenizer.
+// English words average ~4 characters per token; Vietnamese syllables with diacritics
+// split into more tokens, so they are counted at ~2 characters per token.
+func EstimateTokens(text string) int {
+	tokens := 0
+	wordRunes, wordHasNonASCII := 0, false
+
+	flushWord := func() {
+		if wordRunes == 0 {
+			return
+		}
+		perToken := 4
+		if wordHasNonASCII {
+			perToken = 2
+		}
+		tokens += (wordRunes + perToken - 1) / perToken
+		wordRunes, wordHasNonASCII = 0, false
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			wordRunes++
+			if r >= utf8.RuneSelf {
+				wordHasNonASCII = true
+			}
+		case unicode.IsSpace(r):
+			flushWord()
+		default:
+			// Punctuation is usually its own token
+			flushWord()
+			tokens++
+		}
+	}
+	flushWord()
+	return tokens
+}
+
+// EstimatePromptTokens approximates the prompt tokens of a system+user chat request
+func EstimatePromptTokens(systemPrompt, userPrompt string) int {
+	return EstimateTokens(systemPrompt) + EstimateTokens(userPrompt) + 2*perMessageTokenOverhead + 3
+}
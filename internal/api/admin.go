@@ -0,0 +1,115 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"noteme/internal/repository"
+	"noteme/internal/storage"
+	"noteme/internal/utils"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// isAdminRequest checks the X-Admin-Token header against ADMIN_TOKEN.
+// Admin endpoints are disabled when ADMIN_TOKEN is not set.
+func isAdminRequest(c *gin.Context) bool {
+	token := appConfig.AdminToken
+	if token == "" {
+		return false
+	}
+	provided := c.GetHeader("X-Admin-Token")
+	return subtle.ConstantTimeCompare([]byte(provided), []byte(token)) == 1
+}
+
+// adminOnlyMiddleware rejects requests without a valid admin token
+func adminOnlyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isAdminRequest(c) {
+			log.Printf("[Audit] Rejected admin request %s %s from %s", c.Request.Method, c.Request.URL.Path, c.ClientIP())
+			utils.Error(c, http.StatusForbidden, utils.CodeForbidden, "admin token required")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// purgeSTT handles DELETE /api/admin/stt/:id: permanently removes the row of any user and its audio file
+func purgeSTT(c *gin.Context) {
+	id := uuidParam(c, "id")
+
+	purged, err := sttRepo.Purge(c.Request.Context(), id)
+	if err != nil {
+		log.Printf("Error purging STT request: %v", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.Error(c, http.StatusNotFound, utils.CodeSTTRequestNotFound, "STT request not found")
+		} else {
+			utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to purge STT request")
+		}
+		return
+	}
+
+	cleanupPurgedRecord(*purged)
+	log.Printf("[Audit] Purged STT request %s (recording: %s, audio: %s) by admin from %s",
+		purged.ID, purged.RecordingID, purged.AudioURL, c.ClientIP())
+
+	utils.Success(c, gin.H{
+		"id":      id.String(),
+		"status":  "purged",
+		"message": "STT request permanently deleted",
+	})
+}
+
+// purgeUserData handles DELETE /api/admin/users/:user_id/data: full-account deletion
+func purgeUserData(c *gin.Context) {
+	userID := uuidParam(c, "user_id")
+
+	purged, err := sttRepo.PurgeAllByUser(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("Error purging data for user %s: %v", userID, err)
+		utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to purge user data")
+		return
+	}
+
+	for _, rec := range purged {
+		cleanupPurgedRecord(rec)
+	}
+	if _, err := sttRepo.ClearGlossary(c.Request.Context(), userID); err != nil {
+		log.Printf("Error purging glossary of user %s: %v", userID, err)
+		utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to purge user data")
+		return
+	}
+	log.Printf("[Audit] Purged %d STT requests and the glossary of user %s by admin from %s", len(purged), userID, c.ClientIP())
+
+	utils.Success(c, gin.H{
+		"user_id": userID.String(),
+		"purged":  len(purged),
+		"status":  "purged",
+	})
+}
+
+// cleanupPurgedRecord removes the audio file and in-memory state of a purged row
+func cleanupPurgedRecord(rec repository.PurgedRecord) {
+	purgeInMemoryRecording(rec.ID, rec.RecordingID)
+	removeUploadedAudio(rec.AudioURL)
+}
+
+// removeUploadedAudio deletes a local audio file, but only inside the uploads directory
+func removeUploadedAudio(path string) {
+	if path == "" {
+		return
+	}
+	cleaned := filepath.Clean(path)
+	if !strings.HasPrefix(cleaned, filepath.Clean(storage.UploadDir())+string(filepath.Separator)) {
+		log.Printf("[Audit] Skipping audio removal outside uploads directory: %s", path)
+		return
+	}
+	if err := os.Remove(cleaned); err != nil && !os.IsNotExist(err) {
+		log.Printf("Warning: Failed to remove audio file %s: %v", cleaned, err)
+	}
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"noteme/internal/model"
+	"noteme/internal/repository"
+	"noteme/internal/storage"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+const testAdminToken = "test-admin-token"
+
+// purgeRepo holds DB rows for the purge tests and records which ones were purged
+type purgeRepo struct {
+	repository.STTRepository
+	rows   map[uuid.UUID]*model.STTRequest
+	purged []uuid.UUID
+}
+
+func (r *purgeRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.STTRequest, error) {
+	if row, ok := r.rows[id]; ok {
+		return row, nil
+	}
+	return nil, fmt.Errorf("%w: %w", repository.ErrNotFound, sql.ErrNoRows)
+}
+
+func (r *purgeRepo) Purge(ctx context.Context, id uuid.UUID) (*repository.PurgedRecord, error) {
+	row, ok := r.rows[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	delete(r.rows, id)
+	r.purged = append(r.purged, id)
+	return &repository.PurgedRecord{ID: id, AudioURL: row.AudioURL}, nil
+}
+
+// withAdminToken enables the admin endpoints for the duration of the test
+func withAdminToken(t *testing.T) {
+	t.Helper()
+	previous := appConfig.AdminToken
+	appConfig.AdminToken = testAdminToken
+	t.Cleanup(func() { appConfig.AdminToken = previous })
+}
+
+func TestAdminPurgesRowOfAnotherUser(t *testing.T) {
+	withAdminToken(t)
+	audioPath := filepath.Join(storage.UploadDir(), "purge_other_user.wav")
+	if err := os.WriteFile(audioPath, testWAV(44+32000), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The row belongs to the other API key's user, not to the caller
+	owner, _ := uuid.Parse(appConfig.APIKeys[otherAPIKey])
+	rowID := uuid.New()
+	repo := &purgeRepo{rows: map[uuid.UUID]*model.STTRequest{
+		rowID: {ID: rowID, UserID: owner, AudioURL: audioPath, Status: "processed"},
+	}}
+	InitSTTRepository(repo)
+	t.Cleanup(func() { sttRepo = nil })
+	r := newTestRouter()
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/admin/stt/"+rowID.String(), nil)
+	req.Header.Set("X-Admin-Token", testAdminToken)
+	w, resp := doRequest(t, r, req, testAPIKey)
+	if w.Code != http.StatusOK || resp.Data["status"] != "purged" {
+		t.Fatalf("admin purge: status %d, body %s", w.Code, w.Body.String())
+	}
+	if len(repo.purged) != 1 || repo.purged[0] != rowID {
+		t.Errorf("purged rows %v, want [%s]", repo.purged, rowID)
+	}
+	if _, err := os.Stat(audioPath); !os.IsNotExist(err) {
+		t.Errorf("audio file of the purged row still exists (stat error %v)", err)
+	}
+
+	// Purging again reports the row missing
+	req = httptest.NewRequest(http.MethodDelete, "/api/admin/stt/"+rowID.String(), nil)
+	req.Header.Set("X-Admin-Token", testAdminToken)
+	if w, resp := doRequest(t, r, req, ""); w.Code != http.StatusNotFound || resp.Error.Code != "STT_REQUEST_NOT_FOUND" {
+		t.Errorf("second purge: status %d, code %s, want 404 STT_REQUEST_NOT_FOUND", w.Code, resp.Error.Code)
+	}
+}
+
+func TestPurgeRequiresAdminToken(t *testing.T) {
+	withAdminToken(t)
+	caller, _ := uuid.Parse(appConfig.APIKeys[testAPIKey])
+	rowID := uuid.New()
+	repo := &purgeRepo{rows: map[uuid.UUID]*model.STTRequest{
+		rowID: {ID: rowID, UserID: caller, Status: "processed"},
+	}}
+	InitSTTRepository(repo)
+	t.Cleanup(func() { sttRepo = nil })
+	r := newTestRouter()
+
+	tests := []struct {
+		name   string
+		path   string
+		token  string
+		status int
+		code   string
+	}{
+		{"admin route without a token", "/api/admin/stt/" + rowID.String(), "", http.StatusForbidden, "FORBIDDEN"},
+		{"admin route with a wrong token", "/api/admin/stt/" + rowID.String(), "wrong", http.StatusForbidden, "FORBIDDEN"},
+		{"owner route with purge=true", "/api/stt/" + rowID.String() + "?purge=true", testAdminToken, http.StatusBadRequest, "INVALID_REQUEST"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("X-Admin-Token", tt.token)
+			}
+			w, resp := doRequest(t, r, req, testAPIKey)
+			if w.Code != tt.status || resp.Error.Code != tt.code {
+				t.Errorf("status %d, code %s, want %d %s", w.Code, resp.Error.Code, tt.status, tt.code)
+			}
+		})
+	}
+	if len(repo.purged) != 0 {
+		t.Errorf("purged rows %v without a valid admin request", repo.purged)
+	}
+}
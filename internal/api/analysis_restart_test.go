@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"noteme/internal/model"
+	"noteme/internal/repository"
+	"noteme/internal/storage"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// persistedAnalysisRepo is a repository holding the DB rows of recordings that are no longer in
+// memory, as after a restart. Methods other than the two lookups are not used by these tests.
+type persistedAnalysisRepo struct {
+	repository.STTRepository
+	rows     map[string]*model.STTRequest // by recording ID
+	analyses map[string]string            // metadata.ai_analysis JSON by recording ID
+	lookups  int                          // GetAnalysisJSON calls
+}
+
+func (r *persistedAnalysisRepo) GetByRecordingID(ctx context.Context, recordingID string) (*model.STTRequest, error) {
+	if row, ok := r.rows[recordingID]; ok {
+		return row, nil
+	}
+	return nil, fmt.Errorf("%w: %w", repository.ErrNotFound, sql.ErrNoRows)
+}
+
+func (r *persistedAnalysisRepo) GetAnalysisJSON(ctx context.Context, recordingID string) ([]byte, error) {
+	r.lookups++
+	if analysis, ok := r.analyses[recordingID]; ok {
+		return []byte(analysis), nil
+	}
+	return nil, fmt.Errorf("analysis not found: %w", sql.ErrNoRows)
+}
+
+func TestGetAnalysisAfterRestart(t *testing.T) {
+	const recordingID = "rec_before_restart"
+	owner := apiKeys()[testAPIKey]
+	repo := &persistedAnalysisRepo{
+		rows: map[string]*model.STTRequest{
+			recordingID: {ID: uuid.New(), UserID: owner, Status: "processed"},
+		},
+		analyses: map[string]string{
+			recordingID: `{"context":"meeting","title":"Họp kế hoạch","summary":["Chốt tính năng beta"],` +
+				`"action_items":["Minh hoàn thành API upload"],"key_points":[],"questions":[]}`,
+		},
+	}
+	InitSTTRepository(repo)
+	t.Cleanup(func() {
+		sttRepo = nil
+		storage.DeleteAnalysis(recordingID)
+	})
+	r := newTestRouter()
+
+	// Neither the recording nor its analysis is in memory: the analysis is read from metadata
+	if _, ok := storage.GetAnalysis(recordingID); ok {
+		t.Fatal("analysis unexpectedly in memory before the request")
+	}
+	w, resp := doRequest(t, r, httptest.NewRequest(http.MethodGet, "/api/v1/ai/analyze/"+recordingID, nil), testAPIKey)
+	if w.Code != http.StatusOK {
+		t.Fatalf("get analysis: status %d, body %s", w.Code, w.Body.String())
+	}
+	if resp.Data["title"] != "Họp kế hoạch" || resp.Data["context"] != "meeting" {
+		t.Errorf("get analysis: title %v, context %v, want the persisted analysis", resp.Data["title"], resp.Data["context"])
+	}
+	if summary, _ := resp.Data["summary"].([]interface{}); len(summary) != 1 || summary[0] != "Chốt tính năng beta" {
+		t.Errorf("get analysis: summary %v, want the persisted summary", resp.Data["summary"])
+	}
+
+	// The loaded analysis is cached in memory, so the next request does not hit the database
+	if _, ok := storage.GetAnalysis(recordingID); !ok {
+		t.Error("persisted analysis not cached in memory after loading")
+	}
+	doRequest(t, r, httptest.NewRequest(http.MethodGet, "/api/v1/ai/analyze/"+recordingID, nil), testAPIKey)
+	if repo.lookups != 1 {
+		t.Errorf("GetAnalysisJSON called %d times, want 1", repo.lookups)
+	}
+
+	// Only the owner of the DB row may read it
+	w, resp = doRequest(t, r, httptest.NewRequest(http.MethodGet, "/api/v1/ai/analyze/"+recordingID, nil), otherAPIKey)
+	if w.Code != http.StatusNotFound || resp.Error.Code != "RECORDING_NOT_FOUND" {
+		t.Errorf("other user: status %d, code %s, want 404 RECORDING_NOT_FOUND", w.Code, resp.Error.Code)
+	}
+
+	// A recording without a persisted analysis is still reported missing
+	w, resp = doRequest(t, r, httptest.NewRequest(http.MethodGet, "/api/v1/ai/analyze/rec_never_analyzed", nil), testAPIKey)
+	if w.Code != http.StatusNotFound || resp.Error.Code != "ANALYSIS_NOT_FOUND" {
+		t.Errorf("unanalyzed recording: status %d, code %s, want 404 ANALYSIS_NOT_FOUND", w.Code, resp.Error.Code)
+	}
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"noteme/internal/ai"
+	"noteme/internal/storage"
+	"noteme/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Analysis response formats of GET /api/v1/ai/analyze/:recording_id
+const (
+	analysisFormatLegacy = "legacy"
+	analysisFormatV1     = "v1"
+)
+
+// getAnalysisV1 handles GET /api/v1/ai/analyze/:recording_id?format=v1.
+// Returns the structured V1 analysis (key_ideas, action items with assignee/deadline, confidence_score),
+// running it on first request and caching it until the transcript changes.
+func getAnalysisV1(c *gin.Context, id string) {
+	result, cached, err := performAnalysisV1(c.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, errRecordingNotFound):
+			utils.Error(c, http.StatusNotFound, utils.CodeRecordingNotFound, err.Error())
+		case errors.Is(err, errTranscriptNotAvailable):
+			utils.Error(c, http.StatusBadRequest, utils.CodeTranscriptNotAvailable, err.Error())
+		case errors.Is(err, errLowConfidenceTranscript):
+			utils.Error(c, http.StatusBadRequest, utils.CodeLowConfidence, err.Error())
+		case errors.Is(err, ai.ErrOpenAITimeout):
+			utils.Error(c, http.StatusGatewayTimeout, utils.CodeAITimeout, "AI analysis timed out: "+err.Error())
+		case errors.Is(err, ai.ErrAINotConfigured):
+			utils.Error(c, http.StatusServiceUnavailable, utils.CodeAINotConfigured, err.Error())
+		default:
+			utils.Error(c, http.StatusInternalServerError, utils.CodeAIFailed, "AI analysis failed: "+err.Error())
+		}
+		return
+	}
+
+	utils.Success(c, gin.H{
+		"recording_id": id,
+		"format":       analysisFormatV1,
+		"cached":       cached,
+		"analysis":     result,
+	})
+}
+
+// performAnalysisV1 returns the cached V1 analysis of the recording's current transcript, or runs it
+func performAnalysisV1(ctx context.Context, id string) (*ai.AnalysisResultV1, bool, error) {
+	rec, ok := storage.GetRecording(id)
+	if !ok {
+		return nil, false, errRecordingNotFound
+	}
+	if rec.Transcript == "" {
+		return nil, false, errTranscriptNotAvailable
+	}
+
+	if existing, ok := storage.GetAnalysisV1(id, rec.Transcript); ok {
+		log.Printf("Returning existing V1 analysis for recording: %s", id)
+		return existing, true, nil
+	}
+
+	if rec.LowConfidence && skipAIOnLowConfidence() {
+		return nil, false, errLowConfidenceTranscript
+	}
+
+	log.Printf("Analyzing recording %s in V1 format", id)
+	result, err := ai.AnalyzeTranscriptV1(ctx, rec.Transcript)
+	if err != nil {
+		log.Printf("AI V1 analysis error for recording %s: %v", id, err)
+		return nil, false, err
+	}
+
+	storage.SaveAnalysisV1(id, rec.Transcript, result)
+	return result, false, nil
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"noteme/internal/ai"
+	"noteme/internal/storage"
+	"noteme/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// previewAnalysis handles POST /api/v1/ai/analyze/:recording_id?dry_run=true.
+// It returns the prompts and estimated tokens an analysis would use, without calling OpenAI
+// or storing anything. ?prompt_version=v1 previews BuildPromptV1 instead of the default prompt.
+// ctx carries the request's system_prompt override, if any.
+func previewAnalysis(c *gin.Context, ctx context.Context, id string, outputLanguage string) {
+	rec, ok := storage.GetRecording(id)
+	if !ok {
+		utils.Error(c, http.StatusNotFound, utils.CodeRecordingNotFound, errRecordingNotFound.Error())
+		return
+	}
+	if rec.Transcript == "" {
+		utils.Error(c, http.StatusBadRequest, utils.CodeTranscriptNotAvailable, errTranscriptNotAvailable.Error())
+		return
+	}
+
+	ctx = ai.WithPromptVariant(ctx, ai.AssignPromptVariant(id))
+	preview, err := ai.PreviewAnalysisPrompt(ctx, rec.Transcript, ai.DetectContext(rec.Transcript), outputLanguage, c.Query("prompt_version"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	log.Printf("Dry-run analysis for recording %s: context=%s, prompt=%s, ~%d tokens",
+		id, preview.Context, preview.PromptVersion, preview.EstimatedTokens)
+
+	utils.Success(c, gin.H{
+		"recording_id":     id,
+		"dry_run":          true,
+		"model":            preview.Model,
+		"prompt_version":   preview.PromptVersion,
+		"prompt_template":  preview.PromptTemplate,
+		"context":          preview.Context,
+		"output_language":  preview.OutputLanguage,
+		"system_prompt":    preview.SystemPrompt,
+		"user_prompt":      preview.UserPrompt,
+		"estimated_tokens": preview.EstimatedTokens,
+		"low_confidence":   rec.LowConfidence,
+	})
+}
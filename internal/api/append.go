@@ -0,0 +1,139 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"noteme/internal/audio"
+	"noteme/internal/storage"
+	"noteme/internal/utils"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// appendRecording handles POST /api/v1/recordings/:recording_id/append.
+// Concatenates an uploaded clip (multipart field audio_file) to the stored audio with ffmpeg,
+// for meetings recorded in several clips. Clips in a different format are transcoded first.
+// The recording is reset to "uploaded" so it can be processed again with POST /process.
+func appendRecording(c *gin.Context) {
+	id := c.Param("recording_id")
+
+	rec, ok := storage.GetRecording(id)
+	if !ok {
+		utils.Error(c, http.StatusNotFound, utils.CodeRecordingNotFound, "recording not found")
+		return
+	}
+	if rec.AudioDeleted {
+		utils.Error(c, http.StatusGone, utils.CodeAudioDeleted, errAudioDeleted.Error())
+		return
+	}
+	if rec.Status == "processing" {
+		utils.Error(c, http.StatusConflict, utils.CodeAlreadyProcessing, "recording is being processed, append after it finishes")
+		return
+	}
+
+	file, err := c.FormFile("audio_file")
+	if isBodyTooLarge(err) {
+		utils.Error(c, http.StatusRequestEntityTooLarge, utils.CodePayloadTooLarge, "request body exceeds upload size limit")
+		return
+	}
+	if err != nil {
+		// Same alternative field names as uploadRecording
+		if file, err = c.FormFile("audio"); err != nil {
+			if file, err = c.FormFile("file"); err != nil {
+				utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "audio_file is required. Error: "+err.Error())
+				return
+			}
+		}
+	}
+	if err := validateAudioUpload(file.Filename, file.Size); err != nil {
+		utils.Error(c, uploadErrorStatus(err), uploadErrorCode(err), err.Error())
+		return
+	}
+	// The joined file goes to the STT provider, so it is held to the upload limit too
+	if rec.Size+file.Size > maxUploadBytes {
+		utils.Error(c, http.StatusRequestEntityTooLarge, utils.CodeAudioTooLarge, "recording with the appended clip exceeds 25MB limit")
+		return
+	}
+
+	if _, err := os.Stat(rec.Path); err != nil {
+		log.Printf("Audio file of recording %s is missing: %v", id, err)
+		utils.Error(c, http.StatusNotFound, utils.CodeNotFound, "audio file not found")
+		return
+	}
+
+	// Block processing and other appends until the new audio is in place
+	if !storage.TryBeginProcessing(id, rec.Status) {
+		utils.Error(c, http.StatusConflict, utils.CodeAlreadyProcessing, "recording is being processed, append after it finishes")
+		return
+	}
+
+	clipPath, err := storage.SaveAudioClip(id, file)
+	if err != nil {
+		storage.UpdateStatus(id, rec.Status)
+		log.Printf("[Append] Failed to save clip for %s: %v", id, err)
+		utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to save audio clip")
+		return
+	}
+	defer removeUploadedAudio(clipPath)
+
+	if err := verifyUploadedAudio(c.Request.Context(), clipPath); err != nil {
+		storage.UpdateStatus(id, rec.Status)
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidAudio, err.Error())
+		return
+	}
+
+	joinedPath, err := audio.ConcatAudio(c.Request.Context(), rec.Path, clipPath)
+	if err != nil {
+		storage.UpdateStatus(id, rec.Status)
+		log.Printf("[Append] Failed to append clip to %s: %v", id, err)
+		if errors.Is(err, audio.ErrFFmpegUnavailable) {
+			utils.Error(c, http.StatusServiceUnavailable, utils.CodeInternal, "appending audio is not available")
+			return
+		}
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidAudio, "failed to append audio clip")
+		return
+	}
+
+	// Keep the original name; only the extension changes when the clip was transcoded
+	newPath := strings.TrimSuffix(rec.Path, filepath.Ext(rec.Path)) + filepath.Ext(joinedPath)
+	if err := os.Rename(joinedPath, newPath); err != nil {
+		storage.UpdateStatus(id, rec.Status)
+		removeUploadedAudio(joinedPath)
+		log.Printf("[Append] Failed to replace audio of %s: %v", id, err)
+		utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to save appended audio")
+		return
+	}
+	if newPath != rec.Path {
+		removeUploadedAudio(rec.Path)
+	}
+
+	storage.ReplaceAudio(id, newPath)
+	detectDuration(id)
+
+	providerName := "fpt" // default
+	if provider, err := getSTTProvider(); err == nil {
+		providerName = provider.Name()
+	}
+	syncToDatabase(id, requestUserID(c), providerName)
+	// Cached peaks describe the old audio; an empty list makes the next request recompute them
+	syncPeaksToDatabase(id, []float64{})
+
+	updated, ok := storage.GetRecording(id)
+	if !ok {
+		utils.Error(c, http.StatusNotFound, utils.CodeRecordingNotFound, "recording not found")
+		return
+	}
+	log.Printf("[Append] Appended %s to recording %s (%d bytes, %ds)", file.Filename, id, updated.Size, updated.Duration)
+	utils.Success(c, gin.H{
+		"recording_id":      id,
+		"status":            updated.Status,
+		"duration":          updated.Duration, // seconds, kept for existing clients
+		"size":              updated.Size,     // kept for existing clients
+		"audio_duration_ms": updated.Duration * 1000,
+		"audio_size_bytes":  updated.Size,
+	})
+}
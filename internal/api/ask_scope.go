@@ -0,0 +1,117 @@
+package api
+
+import (
+	"fmt"
+	"noteme/internal/ai"
+	"sort"
+	"strings"
+	"time"
+)
+
+// askScope selects which analyses are used as Ask Anything context
+type askScope struct {
+	recordingIDs map[string]bool
+	from         time.Time
+	to           time.Time
+	maxAnalyses  int
+}
+
+// parseAskScope validates the optional filters of an AskRequest
+func parseAskScope(req AskRequest) (*askScope, error) {
+	scope := &askScope{maxAnalyses: askMaxAnalyses()}
+
+	if len(req.RecordingIDs) > 0 {
+		scope.recordingIDs = make(map[string]bool, len(req.RecordingIDs))
+		for _, id := range req.RecordingIDs {
+			scope.recordingIDs[id] = true
+		}
+	}
+
+	var err error
+	if req.From != "" {
+		if scope.from, err = parseAskDate(req.From, false); err != nil {
+			return nil, fmt.Errorf("invalid from: %w", err)
+		}
+	}
+	if req.To != "" {
+		if scope.to, err = parseAskDate(req.To, true); err != nil {
+			return nil, fmt.Errorf("invalid to: %w", err)
+		}
+	}
+	if !scope.from.IsZero() && !scope.to.IsZero() && scope.to.Before(scope.from) {
+		return nil, fmt.Errorf("to must not be before from")
+	}
+
+	return scope, nil
+}
+
+// hasFilter reports whether the client restricted the context explicitly
+func (s *askScope) hasFilter() bool {
+	return len(s.recordingIDs) > 0 || !s.from.IsZero() || !s.to.IsZero()
+}
+
+// apply filters contexts (newest first). Without explicit filters the result is capped
+// to the most recent maxAnalyses; the returned int is the pre-cap total when capping happened.
+func (s *askScope) apply(contexts []ai.AnalysisContext) ([]ai.AnalysisContext, int) {
+	filtered := make([]ai.AnalysisContext, 0, len(contexts))
+	for _, ctx := range contexts {
+		if len(s.recordingIDs) > 0 && !s.recordingIDs[ctx.RecordingID] {
+			continue
+		}
+		if !s.from.IsZero() || !s.to.IsZero() {
+			createdAt, err := time.Parse(time.RFC3339, ctx.CreatedAt)
+			if err != nil {
+				continue // unknown date cannot match a date range
+			}
+			if !s.from.IsZero() && createdAt.Before(s.from) {
+				continue
+			}
+			if !s.to.IsZero() && createdAt.After(s.to) {
+				continue
+			}
+		}
+		filtered = append(filtered, ctx)
+	}
+
+	// Newest first (RFC3339 strings sort chronologically)
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filtered[i].CreatedAt > filtered[j].CreatedAt
+	})
+
+	if !s.hasFilter() && s.maxAnalyses > 0 && len(filtered) > s.maxAnalyses {
+		return filtered[:s.maxAnalyses], len(filtered)
+	}
+	return filtered, 0
+}
+
+// parseAskDate parses RFC3339 or YYYY-MM-DD; date-only "to" values include the whole day
+func parseAskDate(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC3339 or YYYY-MM-DD, got %q", value)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+// askMaxAnalyses returns ASK_MAX_ANALYSES (default 20)
+func askMaxAnalyses() int {
+	return appConfig.AskMaxAnalyses
+}
+
+// askContextCacheKey identifies a built Ask context by user, analyses version and the selected recordings
+func askContextCacheKey(userID string, version uint64, contexts []ai.AnalysisContext) string {
+	if userID == "" {
+		userID = getDefaultUserID().String()
+	}
+	ids := make([]string, len(contexts))
+	for i, ctx := range contexts {
+		ids[i] = ctx.RecordingID
+	}
+	return fmt.Sprintf("%s|v%d|%s", userID, version, strings.Join(ids, ","))
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"noteme/internal/audio"
+	"noteme/internal/utils"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// checkAudioContent rejects audio that is empty, too short, or silent before any paid API call.
+// If ffprobe/ffmpeg are unavailable the duration and silence checks are skipped rather than blocking processing.
+func checkAudioContent(audioPath string) error {
+	// Cheap first filter: tiny files are almost certainly empty or corrupted (MIN_AUDIO_BYTES)
+	info, err := os.Stat(audioPath)
+	if err != nil {
+		return fmt.Errorf("failed to read audio file: %w", err)
+	}
+	if err := audio.CheckSize(info.Size()); err != nil {
+		return err
+	}
+
+	thresholdDB := appConfig.SilenceThresholdDB
+	minDuration := appConfig.MinAudioDurationSeconds
+
+	// The size says little about the content: a short clip can be small and a corrupt file large,
+	// so check the duration the container actually reports
+	duration, err := audio.ProbeDuration(audioPath)
+	switch {
+	case errors.Is(err, audio.ErrProbeUnavailable), errors.Is(err, audio.ErrDurationUnknown):
+		log.Printf("[Audio Check] Warning: duration check skipped for %s: %v", audioPath, err)
+	case err != nil:
+		log.Printf("[Audio Check] Rejected %s: %v", audioPath, err)
+		return fmt.Errorf("audio duration could not be read, file may be corrupted")
+	case duration < minDuration:
+		return fmt.Errorf("audio too short (%.2fs), minimum is %.2fs", duration, minDuration)
+	}
+
+	report, err := audio.DetectSilence(audioPath, thresholdDB)
+	if err != nil {
+		log.Printf("[Audio Check] Warning: silence detection skipped for %s: %v", audioPath, err)
+		return nil
+	}
+
+	log.Printf("[Audio Check] %s: duration=%.2fs, silence=%.2fs, speech=%.2fs (threshold=%gdB)",
+		audioPath, report.Duration, report.SilentSeconds, report.SpeechSeconds(), thresholdDB)
+
+	if report.Duration < minDuration {
+		return fmt.Errorf("audio too short (%.2fs), minimum is %.2fs", report.Duration, minDuration)
+	}
+	if report.SpeechSeconds() < minDuration {
+		return errAudioSilent
+	}
+
+	return nil
+}
+
+// verifyUploadedAudio checks that an uploaded file really is audio of the type its extension claims:
+// first by magic bytes, then with ffprobe. Without ffprobe only the magic bytes are checked.
+func verifyUploadedAudio(ctx context.Context, audioPath string) error {
+	ext := strings.ToLower(filepath.Ext(audioPath))
+	matches, err := audio.SniffMatchesExtension(audioPath, ext)
+	if err != nil {
+		return fmt.Errorf("failed to read audio file: %w", err)
+	}
+	if !matches {
+		log.Printf("[Audio Check] Rejected upload %s: content does not match extension %s", audioPath, ext)
+		return fmt.Errorf("%w: content is not %s audio", errAudioTypeMismatch, strings.TrimPrefix(ext, "."))
+	}
+
+	info, err := audio.ProbeStreams(ctx, audioPath)
+	if errors.Is(err, audio.ErrProbeUnavailable) {
+		log.Printf("[Audio Check] Warning: ffprobe not available, accepting %s by extension only", audioPath)
+		return nil
+	}
+	if err != nil {
+		log.Printf("[Audio Check] Rejected upload %s: %v", audioPath, err)
+		return errNotAudio
+	}
+	log.Printf("[Audio Check] %s: format=%s, codec=%s", audioPath, info.FormatName, info.AudioCodec)
+	return nil
+}
+
+var (
+	// errNotAudio is returned by verifyUploadedAudio when ffprobe finds no audio in the file
+	errNotAudio = errors.New("file is not a readable audio file")
+	// errAudioTypeMismatch is returned when the file header does not match its extension
+	errAudioTypeMismatch = errors.New("file content does not match its extension")
+)
+
+// errAudioSilent is returned by checkAudioContent when the audio has no speech
+var errAudioSilent = errors.New("audio appears to be silent")
+
+// audioCheckErrorCode maps a checkAudioContent error to its error code
+func audioCheckErrorCode(err error) utils.ErrorCode {
+	if errors.Is(err, errAudioSilent) {
+		return utils.CodeNoSpeechDetected
+	}
+	return utils.CodeInvalidAudio
+}
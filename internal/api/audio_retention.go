@@ -0,0 +1,67 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"noteme/internal/storage"
+	"noteme/internal/utils"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errAudioDeleted is returned for operations that need the audio of a transcript-only recording
+var errAudioDeleted = errors.New("audio deleted: this recording only keeps its transcript, upload the audio again to re-transcribe")
+
+// deleteAudioAfterProcessing returns DELETE_AUDIO_AFTER_PROCESSING: when true, every recording is
+// transcript-only and its audio file is removed as soon as STT succeeds (default false)
+func deleteAudioAfterProcessing() bool {
+	return appConfig.DeleteAudioAfterProcessing
+}
+
+// applyDeleteAudioOption marks a new recording transcript-only when the upload asked for it (?delete_audio=true)
+func applyDeleteAudioOption(c *gin.Context, recordingID string) {
+	if c.Query("delete_audio") == "true" {
+		storage.SetDeleteAudio(recordingID, true)
+	}
+}
+
+// discardProcessedAudio removes the audio of a transcript-only recording after a successful transcription
+func discardProcessedAudio(rec *storage.Recording) {
+	if !rec.DeleteAudio && !deleteAudioAfterProcessing() {
+		return
+	}
+
+	removeUploadedAudio(rec.Path)
+	storage.MarkAudioDeleted(rec.ID)
+	syncAudioDeletedToDatabase(rec.ID)
+	log.Printf("Deleted audio of transcript-only recording %s", rec.ID)
+}
+
+// getRecordingAudio handles GET /api/v1/recordings/:recording_id/audio.
+// Returns 410 Gone once the audio was deleted after processing.
+func getRecordingAudio(c *gin.Context) {
+	id := c.Param("recording_id")
+
+	rec, ok := storage.GetRecording(id)
+	if !ok {
+		utils.Error(c, http.StatusNotFound, utils.CodeRecordingNotFound, "recording not found")
+		return
+	}
+	if rec.AudioDeleted {
+		utils.Error(c, http.StatusGone, utils.CodeAudioDeleted, errAudioDeleted.Error())
+		return
+	}
+	if _, err := os.Stat(rec.Path); err != nil {
+		log.Printf("Audio file of recording %s is missing: %v", id, err)
+		utils.Error(c, http.StatusNotFound, utils.CodeNotFound, "audio file not found")
+		return
+	}
+
+	// Uploads are stored as <UPLOAD_DIR>/<id>_<original name>
+	name := strings.TrimPrefix(filepath.Base(rec.Path), id+"_")
+	c.FileAttachment(rec.Path, name)
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"noteme/internal/storage"
+	"noteme/internal/utils"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// fallbackDefaultUserID owns unauthenticated requests (REQUIRE_AUTH=false) when DEFAULT_USER_ID is not set
+const fallbackDefaultUserID = "00000000-0000-0000-0000-000000000001"
+
+// Context keys set by authMiddleware
+const (
+	userIDContextKey     = "auth_user_id"
+	authMethodContextKey = "auth_method"
+)
+
+// Ways a request identified its user
+const (
+	authMethodAPIKey  = "api_key"
+	authMethodJWT     = "jwt"
+	authMethodDefault = "default_user" // no credential, REQUIRE_AUTH=false
+)
+
+var (
+	defaultUserID     uuid.UUID
+	defaultUserIDOnce sync.Once
+
+	configuredAPIKeys map[string]uuid.UUID
+	apiKeysOnce       sync.Once
+)
+
+// getDefaultUserID returns the user of unauthenticated requests when REQUIRE_AUTH=false.
+// DEFAULT_USER_ID overrides the built-in MVP user.
+func getDefaultUserID() uuid.UUID {
+	defaultUserIDOnce.Do(func() {
+		v := appConfig.DefaultUserID
+		if v == "" {
+			v = fallbackDefaultUserID
+		}
+		// config.Load already rejected malformed values
+		defaultUserID = uuid.MustParse(v)
+	})
+	return defaultUserID
+}
+
+// apiKeys returns the API_KEYS map (key → user ID), converted on first use
+func apiKeys() map[string]uuid.UUID {
+	apiKeysOnce.Do(func() {
+		configuredAPIKeys = make(map[string]uuid.UUID, len(appConfig.APIKeys))
+		for key, user := range appConfig.APIKeys {
+			configuredAPIKeys[key] = uuid.MustParse(user)
+		}
+		if len(configuredAPIKeys) > 0 {
+			log.Printf("[Auth] %d API keys configured", len(configuredAPIKeys))
+		}
+	})
+	return configuredAPIKeys
+}
+
+// lookupAPIKey returns the user of an API key, comparing keys in constant time
+func lookupAPIKey(provided string) (uuid.UUID, bool) {
+	for key, userID := range apiKeys() {
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(key)) == 1 {
+			return userID, true
+		}
+	}
+	return uuid.Nil, false
+}
+
+// requireAuth returns REQUIRE_AUTH; when true, requests without an API key or bearer token are rejected
+func requireAuth() bool {
+	return appConfig.RequireAuth
+}
+
+// authMiddleware identifies the calling user for protected routes. The user comes only from a
+// verified credential: an X-API-Key header checked against API_KEYS, or an Authorization: Bearer
+// JWT signed with JWT_SECRET. An invalid credential is always rejected with 401; a missing one
+// too, unless REQUIRE_AUTH=false (local development), where the request acts as DEFAULT_USER_ID.
+func authMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, method, err := authenticate(c)
+		if err != nil {
+			log.Printf("[Auth] Rejected %s for %s %s from %s: %v", method, c.Request.Method, c.Request.URL.Path, c.ClientIP(), err)
+			utils.Error(c, http.StatusUnauthorized, utils.CodeUnauthorized, err.Error())
+			c.Abort()
+			return
+		}
+		if method == "" {
+			if requireAuth() {
+				utils.Error(c, http.StatusUnauthorized, utils.CodeUnauthorized, "authentication required (X-API-Key header or Authorization: Bearer token)")
+				c.Abort()
+				return
+			}
+			userID, method = getDefaultUserID(), authMethodDefault
+		}
+
+		c.Set(userIDContextKey, userID)
+		c.Set(authMethodContextKey, method)
+		c.Next()
+	}
+}
+
+// authenticate verifies the request's credential. method is empty when none was sent.
+func authenticate(c *gin.Context) (userID uuid.UUID, method string, err error) {
+	if key := strings.TrimSpace(c.GetHeader("X-API-Key")); key != "" {
+		userID, ok := lookupAPIKey(key)
+		if !ok {
+			return uuid.Nil, authMethodAPIKey, errors.New("invalid API key")
+		}
+		return userID, authMethodAPIKey, nil
+	}
+
+	if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		userID, err := verifyJWT(strings.TrimSpace(token), time.Now())
+		if err != nil {
+			return uuid.Nil, authMethodJWT, err
+		}
+		return userID, authMethodJWT, nil
+	}
+	return uuid.Nil, "", nil
+}
+
+// requestUserID returns the user identified by authMiddleware, or the default user on
+// routes without it
+func requestUserID(c *gin.Context) uuid.UUID {
+	if userID, ok := c.Get(userIDContextKey); ok {
+		return userID.(uuid.UUID)
+	}
+	return getDefaultUserID()
+}
+
+// recordingOwnerMiddleware answers 404 for a :recording_id the caller does not own, so other
+// users' recordings are indistinguishable from missing ones
+func recordingOwnerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !ownsRecording(c.Request.Context(), c.Param("recording_id"), requestUserID(c)) {
+			utils.Error(c, http.StatusNotFound, utils.CodeRecordingNotFound, "recording not found")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ownsRecording reports whether userID may access a recording. Recordings no longer in memory
+// are checked against the owner of their DB row; unknown IDs pass so the handler reports them
+// missing itself.
+func ownsRecording(ctx context.Context, id string, userID uuid.UUID) bool {
+	if rec, ok := storage.GetRecording(id); ok {
+		return rec.UserID == userID.String()
+	}
+	if sttRepo == nil {
+		return true
+	}
+	req, err := sttRepo.GetByRecordingID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return true
+	}
+	return err == nil && req.UserID == userID
+}
+
+// sttRequestOwnerMiddleware answers 404 for an /api/stt/:id row of another user
+func sttRequestOwnerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, err := sttRepo.GetByID(c.Request.Context(), uuidParam(c, "id"))
+		if err != nil || req.UserID != requestUserID(c) {
+			utils.Error(c, http.StatusNotFound, utils.CodeSTTRequestNotFound, "STT request not found")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
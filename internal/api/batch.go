@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"noteme/internal/ai"
+	"noteme/internal/utils"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxBatchSize = 50
+
+// BatchAnalyzeRequest represents the batch analyze request body
+type BatchAnalyzeRequest struct {
+	RecordingIDs   []string `json:"recording_ids" binding:"required"`
+	Force          bool     `json:"force"`           // re-analyze even if an analysis exists
+	OutputLanguage string   `json:"output_language"` // vi (default) or en
+	UseFastClean   bool     `json:"use_fast_clean"`  // strip greetings/mic tests/fillers before analysis
+}
+
+// batchItemResult is the per-recording outcome of a batch analysis
+type batchItemResult struct {
+	Status   int                `json:"status"`
+	Skipped  bool               `json:"skipped,omitempty"`
+	Analysis *ai.AnalysisResult `json:"analysis,omitempty"`
+	Error    string             `json:"error,omitempty"`
+}
+
+// analyzeBatch handles POST /api/v1/ai/analyze/batch
+// Each recording is analyzed independently; partial failures are reported per item (207).
+func analyzeBatch(c *gin.Context) {
+	var req BatchAnalyzeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.RecordingIDs) == 0 {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "recording_ids is required")
+		return
+	}
+
+	if len(req.RecordingIDs) > maxBatchSize {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "too many recording_ids (max "+strconv.Itoa(maxBatchSize)+")")
+		return
+	}
+
+	outputLanguage, err := ai.NormalizeOutputLanguage(req.OutputLanguage)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+	if req.UseFastClean {
+		ctx = ai.WithFastClean(ctx)
+	}
+
+	concurrency := batchConcurrency()
+	log.Printf("[Batch] Analyzing %d recordings (concurrency: %d, force: %v)", len(req.RecordingIDs), concurrency, req.Force)
+
+	results := make(map[string]*batchItemResult, len(req.RecordingIDs))
+	var resultsMu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	userID := requestUserID(c)
+	for _, id := range dedupeStrings(req.RecordingIDs) {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			// Other users' recordings are reported like missing ones
+			item := &batchItemResult{Status: http.StatusNotFound, Error: errRecordingNotFound.Error()}
+			if ownsRecording(ctx, id, userID) {
+				item = analyzeBatchItem(ctx, id, outputLanguage, req.Force)
+			}
+
+			resultsMu.Lock()
+			results[id] = item
+			resultsMu.Unlock()
+		}(id)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, item := range results {
+		if item.Status == http.StatusOK {
+			succeeded++
+		}
+	}
+	log.Printf("[Batch] Completed: %d/%d succeeded", succeeded, len(results))
+
+	utils.SuccessWithStatus(c, http.StatusMultiStatus, gin.H{
+		"results":   results,
+		"total":     len(results),
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+	})
+}
+
+// analyzeBatchItem analyzes one recording of a batch, mapping errors to per-item statuses
+func analyzeBatchItem(ctx context.Context, id string, outputLanguage string, force bool) *batchItemResult {
+	if !force {
+		if existing, ok := getStoredAnalysis(id); ok && analysisLanguage(existing) == outputLanguage && !analysisStale(id, existing) {
+			return &batchItemResult{Status: http.StatusOK, Skipped: true, Analysis: existing}
+		}
+	}
+
+	result, err := performAnalysis(ctx, id, outputLanguage, force)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, errRecordingNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, errTranscriptNotAvailable), errors.Is(err, errLowConfidenceTranscript):
+			status = http.StatusBadRequest
+		case errors.Is(err, ai.ErrOpenAITimeout):
+			status = http.StatusGatewayTimeout
+		case errors.Is(err, ai.ErrAINotConfigured):
+			status = http.StatusServiceUnavailable
+		}
+		return &batchItemResult{Status: status, Error: err.Error()}
+	}
+
+	return &batchItemResult{Status: http.StatusOK, Analysis: result}
+}
+
+// batchConcurrency returns AI_BATCH_CONCURRENCY (default 3)
+func batchConcurrency() int {
+	return appConfig.AIBatchConcurrency
+}
+
+// dedupeStrings removes duplicates and empty values while keeping order
+func dedupeStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"noteme/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// uploadRoutes carry audio and get the larger upload body limit ("METHOD path" as registered)
+var uploadRoutes = map[string]bool{
+	"POST /api/v1/recordings":                      true,
+	"POST /api/v1/recordings/base64":               true,
+	"PATCH /api/v1/uploads/:id":                    true,
+	"POST /api/v1/recordings/:recording_id/append": true,
+}
+
+// BodyLimitMiddleware caps request bodies at defaultLimit bytes, or uploadLimit on upload routes,
+// answering 413 PAYLOAD_TOO_LARGE when exceeded. Bodies of other routes are small, so they are read
+// up front: a chunked body over the limit is rejected before any handler parses it. Upload bodies
+// are streamed and the handlers report the limit (see isBodyTooLarge).
+func BodyLimitMiddleware(defaultLimit, uploadLimit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		upload := uploadRoutes[c.Request.Method+" "+c.FullPath()]
+		limit := defaultLimit
+		if upload {
+			limit = uploadLimit
+		}
+
+		if c.Request.ContentLength > limit {
+			respondBodyTooLarge(c, limit)
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		if upload {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			if isBodyTooLarge(err) {
+				respondBodyTooLarge(c, limit)
+				return
+			}
+			utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "failed to read request body")
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
+
+// isBodyTooLarge reports whether err comes from reading past the body limit
+func isBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+func respondBodyTooLarge(c *gin.Context, limit int64) {
+	utils.Error(c, http.StatusRequestEntityTooLarge, utils.CodePayloadTooLarge,
+		fmt.Sprintf("request body exceeds %dMB limit", limit>>20))
+	c.Abort()
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"noteme/internal/ai"
+	"noteme/internal/utils"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CleanRequest represents the POST /api/v1/ai/clean request body
+type CleanRequest struct {
+	Transcript      string   `json:"transcript" binding:"required"`
+	OutputLanguage  string   `json:"output_language"`  // vi (default) or en
+	FilterProfanity bool     `json:"filter_profanity"` // redact profanity in the cleaned text
+	Temperature     *float64 `json:"temperature"`      // optional, 0-1.5 (default 0.2)
+	MaxTokens       int      `json:"max_tokens"`       // optional, 16-4096
+	UseFastClean    bool     `json:"use_fast_clean"`   // strip greetings/mic tests/fillers before the AI call
+}
+
+// BatchCleanRequest represents the POST /api/v1/ai/clean/batch request body
+type BatchCleanRequest struct {
+	Transcripts     []string `json:"transcripts" binding:"required"`
+	OutputLanguage  string   `json:"output_language"`
+	FilterProfanity bool     `json:"filter_profanity"`
+	Temperature     *float64 `json:"temperature"`
+	MaxTokens       int      `json:"max_tokens"`
+	UseFastClean    bool     `json:"use_fast_clean"`
+}
+
+// cleanItemResult is the per-transcript outcome of a batch clean
+type cleanItemResult struct {
+	Index        int      `json:"index"`
+	Status       int      `json:"status"`
+	CleanedText  string   `json:"cleaned_text,omitempty"`
+	Summary      string   `json:"summary,omitempty"`
+	DecodedWords []string `json:"decoded_words"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// cleanTranscript handles POST /api/v1/ai/clean for a transcript that is not tied to a recording
+func cleanTranscript(c *gin.Context) {
+	var req CleanRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Transcript) == "" {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "transcript is required")
+		return
+	}
+
+	outputLanguage, err := ai.NormalizeOutputLanguage(req.OutputLanguage)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	ctx, err := generationContext(c.Request.Context(), req.Temperature, req.MaxTokens)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	opts := ai.CleanOptions{FilterProfanity: req.FilterProfanity, FastClean: req.UseFastClean, Glossary: userGlossary(ctx, requestUserID(c))}
+	item := cleanBatchItem(ctx, 0, req.Transcript, outputLanguage, opts)
+	if item.Status != http.StatusOK {
+		code := utils.CodeAIFailed
+		switch item.Status {
+		case http.StatusGatewayTimeout:
+			code = utils.CodeAITimeout
+		case http.StatusServiceUnavailable:
+			code = utils.CodeAINotConfigured
+		}
+		utils.Error(c, item.Status, code, "AI cleaning failed: "+item.Error)
+		return
+	}
+
+	utils.Success(c, gin.H{
+		"cleaned_text":  item.CleanedText,
+		"summary":       item.Summary,
+		"decoded_words": item.DecodedWords,
+	})
+}
+
+// cleanTranscriptBatch handles POST /api/v1/ai/clean/batch
+// Transcripts are cleaned with AI_BATCH_CONCURRENCY workers; partial failures are reported per item (207).
+func cleanTranscriptBatch(c *gin.Context) {
+	var req BatchCleanRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.Transcripts) == 0 {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "transcripts is required")
+		return
+	}
+
+	if len(req.Transcripts) > maxBatchSize {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "too many transcripts (max "+strconv.Itoa(maxBatchSize)+")")
+		return
+	}
+
+	outputLanguage, err := ai.NormalizeOutputLanguage(req.OutputLanguage)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	ctx, err := generationContext(c.Request.Context(), req.Temperature, req.MaxTokens)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	concurrency := batchConcurrency()
+	log.Printf("[Batch] Cleaning %d transcripts (concurrency: %d)", len(req.Transcripts), concurrency)
+
+	opts := ai.CleanOptions{FilterProfanity: req.FilterProfanity, FastClean: req.UseFastClean, Glossary: userGlossary(ctx, requestUserID(c))}
+	results := make([]*cleanItemResult, len(req.Transcripts))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for i, transcript := range req.Transcripts {
+		wg.Add(1)
+		go func(i int, transcript string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = cleanBatchItem(ctx, i, transcript, outputLanguage, opts)
+		}(i, transcript)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, item := range results {
+		if item.Status == http.StatusOK {
+			succeeded++
+		}
+	}
+	log.Printf("[Batch] Cleaning completed: %d/%d succeeded", succeeded, len(results))
+
+	utils.SuccessWithStatus(c, http.StatusMultiStatus, gin.H{
+		"results":   results,
+		"total":     len(results),
+		"succeeded": succeeded,
+		"failed":    len(results) - succeeded,
+	})
+}
+
+// cleanBatchItem cleans one transcript, mapping errors to per-item statuses
+func cleanBatchItem(ctx context.Context, index int, transcript string, outputLanguage string, opts ai.CleanOptions) *cleanItemResult {
+	if strings.TrimSpace(transcript) == "" {
+		return &cleanItemResult{Index: index, Status: http.StatusBadRequest, DecodedWords: []string{}, Error: "transcript is empty"}
+	}
+
+	result, err := ai.CleanTranscriptDetailed(ctx, transcript, outputLanguage, opts)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, ai.ErrOpenAITimeout):
+			status = http.StatusGatewayTimeout
+		case errors.Is(err, ai.ErrAINotConfigured):
+			status = http.StatusServiceUnavailable
+		}
+		return &cleanItemResult{Index: index, Status: status, DecodedWords: []string{}, Error: err.Error()}
+	}
+
+	decodedWords := result.DecodedWords
+	if decodedWords == nil {
+		decodedWords = []string{}
+	}
+	return &cleanItemResult{
+		Index:        index,
+		Status:       http.StatusOK,
+		CleanedText:  result.CleanedText,
+		Summary:      result.Summary,
+		DecodedWords: decodedWords,
+	}
+}
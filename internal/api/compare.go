@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"noteme/internal/storage"
+	"noteme/internal/stt"
+	"noteme/internal/utils"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// compareDeadline bounds a whole comparison; providers still running when it expires are reported as failed
+const compareDeadline = 3 * time.Minute
+
+// CompareRequest lists the providers to run on a stored recording
+type CompareRequest struct {
+	Providers []string `json:"providers" binding:"required"` // e.g. ["fpt", "google"]
+}
+
+// providerComparison is one provider's transcription of the recording
+type providerComparison struct {
+	Provider            string  `json:"provider"`
+	Status              string  `json:"status"` // success or failed
+	Transcript          string  `json:"transcript,omitempty"`
+	Confidence          float64 `json:"confidence"` // -1 when the provider gave no score
+	ConfidenceAvailable bool    `json:"confidence_available"`
+	Language            string  `json:"language,omitempty"`
+	DurationMs          int64   `json:"duration_ms"`
+	Error               string  `json:"error,omitempty"`
+}
+
+// compareProviders handles POST /api/v1/recordings/:recording_id/compare.
+// Each provider transcribes the stored audio concurrently under a shared deadline.
+// Raw STT output is returned (no AI cleaning) and the stored transcript is left untouched.
+func compareProviders(c *gin.Context) {
+	id := c.Param("recording_id")
+
+	var req CompareRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.Providers) == 0 {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "providers is required")
+		return
+	}
+
+	names := make([]string, 0, len(req.Providers))
+	for _, name := range req.Providers {
+		names = append(names, strings.ToLower(strings.TrimSpace(name)))
+	}
+	names = dedupeStrings(names)
+	for _, name := range names {
+		if !isSupportedProvider(name) {
+			utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, fmt.Sprintf("unsupported provider: %s. Supported: %s",
+				name, strings.Join(stt.SupportedProviders(), ", ")))
+			return
+		}
+	}
+
+	rec, ok := storage.GetRecording(id)
+	if !ok {
+		utils.Error(c, http.StatusNotFound, utils.CodeRecordingNotFound, "recording not found")
+		return
+	}
+	if rec.AudioDeleted {
+		utils.Error(c, http.StatusGone, utils.CodeAudioDeleted, errAudioDeleted.Error())
+		return
+	}
+
+	if err := checkAudioContent(rec.Path); err != nil {
+		utils.Error(c, http.StatusBadRequest, audioCheckErrorCode(err), err.Error())
+		return
+	}
+
+	log.Printf("[Compare] Transcribing recording %s with %v", id, names)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), compareDeadline)
+	defer cancel()
+
+	results := make([]providerComparison, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			results[i] = transcribeForComparison(ctx, name, rec.Path)
+		}(i, name)
+	}
+	wg.Wait()
+
+	for _, r := range results {
+		log.Printf("[Compare] %s: status=%s, confidence=%.2f, length=%d, duration=%dms",
+			r.Provider, r.Status, r.Confidence, len(r.Transcript), r.DurationMs)
+	}
+
+	utils.Success(c, gin.H{
+		"recording_id": id,
+		"results":      results,
+	})
+}
+
+// transcribeForComparison runs one provider without touching the stored recording
+func transcribeForComparison(ctx context.Context, name string, audioPath string) providerComparison {
+	comparison := providerComparison{Provider: name, Status: "failed"}
+
+	provider, err := getNamedSTTProvider(name)
+	if err != nil {
+		comparison.Error = "STT provider not available: " + err.Error()
+		return comparison
+	}
+
+	start := time.Now()
+	result, err := provider.Transcribe(ctx, audioPath)
+	comparison.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		comparison.Error = err.Error()
+		return comparison
+	}
+
+	comparison.Status = "success"
+	comparison.Transcript = result.Transcript
+	comparison.Confidence = result.Confidence
+	comparison.ConfidenceAvailable = stt.HasConfidence(result.Confidence)
+	comparison.Language = transcriptLanguage(stt.ResolveLanguage(result))
+	if result.Duration > 0 {
+		comparison.DurationMs = result.Duration.Milliseconds()
+	}
+	return comparison
+}
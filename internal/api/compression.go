@@ -0,0 +1,128 @@
+package api
+
+import (
+	"compress/gzip"
+	"io"
+	"log"
+	"net/http"
+	"noteme/internal/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// incompressibleContentTypes are already compressed; gzipping them again only costs CPU
+var incompressibleContentTypes = []string{"audio/", "video/", "image/", "application/pdf", "application/zip", "application/gzip"}
+
+// GzipMiddleware compresses responses for clients sending "Accept-Encoding: gzip".
+// Audio and other already-compressed payloads, and responses that set their own
+// Content-Encoding (e.g. /metrics), are passed through unchanged.
+func GzipMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !acceptsGzip(c.Request) || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		w := &gzipResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		defer w.close()
+
+		c.Header("Vary", "Accept-Encoding")
+		c.Next()
+	}
+}
+
+// acceptsGzip reports whether the request's Accept-Encoding allows gzip
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(enc), ";")
+		if strings.EqualFold(strings.TrimSpace(name), "gzip") {
+			return strings.ReplaceAll(params, " ", "") != "q=0"
+		}
+	}
+	return false
+}
+
+// gzipResponseWriter decides on the first body write whether to compress,
+// once the handler has set Content-Type
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	gz      *gzip.Writer
+	decided bool
+}
+
+func (w *gzipResponseWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+
+	h := w.Header()
+	if w.ResponseWriter.Written() || h.Get("Content-Encoding") != "" || !compressible(h.Get("Content-Type")) {
+		return
+	}
+	h.Set("Content-Encoding", "gzip")
+	h.Del("Content-Length")
+	w.gz = gzip.NewWriter(w.ResponseWriter)
+}
+
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	w.decide()
+	if w.gz != nil {
+		return w.gz.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *gzipResponseWriter) close() {
+	if w.gz == nil {
+		return
+	}
+	if err := w.gz.Close(); err != nil {
+		log.Printf("Warning: Failed to finish gzip response: %v", err)
+	}
+}
+
+// compressible reports whether a response of this content type is worth compressing
+func compressible(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, prefix := range incompressibleContentTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// gzipRequestMiddleware transparently decompresses request bodies sent with
+// "Content-Encoding: gzip". Size limits applied by the handler (http.MaxBytesReader)
+// then count decompressed bytes, so a small gzip body cannot bypass them.
+func gzipRequestMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.EqualFold(strings.TrimSpace(c.GetHeader("Content-Encoding")), "gzip") {
+			c.Next()
+			return
+		}
+
+		gz, err := gzip.NewReader(c.Request.Body)
+		if err != nil {
+			utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "invalid gzip request body")
+			c.Abort()
+			return
+		}
+		defer gz.Close()
+
+		c.Request.Body = struct {
+			io.Reader
+			io.Closer
+		}{gz, c.Request.Body}
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.ContentLength = -1
+		c.Next()
+	}
+}
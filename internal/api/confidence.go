@@ -0,0 +1,35 @@
+package api
+
+import (
+	"noteme/internal/stt"
+)
+
+// minConfidence returns MIN_CONFIDENCE (0-1). 0 or unset disables the low-confidence check.
+func minConfidence() float64 {
+	return appConfig.MinConfidence
+}
+
+// isLowConfidence reports whether an STT confidence is below MIN_CONFIDENCE.
+// Unscored transcripts (stt.ConfidenceUnavailable) are not flagged: there is nothing to compare.
+func isLowConfidence(confidence float64) bool {
+	threshold := minConfidence()
+	return threshold > 0 && stt.HasConfidence(confidence) && confidence < threshold
+}
+
+// cleanSkipConfidence returns CLEAN_SKIP_CONFIDENCE (0-1). 0 or unset always cleans.
+func cleanSkipConfidence() float64 {
+	return appConfig.CleanSkipConfidence
+}
+
+// isHighConfidence reports whether an STT confidence reaches CLEAN_SKIP_CONFIDENCE, so the
+// transcript is clean enough to skip AI cleaning. Unscored transcripts are always cleaned.
+func isHighConfidence(confidence float64) bool {
+	threshold := cleanSkipConfidence()
+	return threshold > 0 && stt.HasConfidence(confidence) && confidence >= threshold
+}
+
+// skipAIOnLowConfidence returns LOW_CONFIDENCE_SKIP_AI; when true, low-confidence
+// transcripts are not sent to AI cleaning or analysis
+func skipAIOnLowConfidence() bool {
+	return appConfig.LowConfidenceSkipAI
+}
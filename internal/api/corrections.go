@@ -0,0 +1,111 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"noteme/internal/model"
+	"noteme/internal/repository"
+	"noteme/internal/storage"
+	"noteme/internal/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxCorrectionsPerRequest caps the pairs accepted by one /corrections call
+const maxCorrectionsPerRequest = 50
+
+// CorrectionsRequest is the body of POST /api/v1/recordings/:recording_id/corrections
+type CorrectionsRequest struct {
+	Corrections []CorrectionPair `json:"corrections"`
+}
+
+// CorrectionPair is a word or phrase the user fixed in a transcript
+type CorrectionPair struct {
+	Wrong string `json:"wrong"`
+	Right string `json:"right"`
+}
+
+// submitCorrections handles POST /api/v1/recordings/:recording_id/corrections.
+// The user's fixes are kept on the recording (metadata.user_corrections) and saved to their
+// glossary, so later cleaning applies them. A wrong spelling the glossary already maps to another
+// term is remapped to the new one.
+func submitCorrections(c *gin.Context) {
+	id := c.Param("recording_id")
+	if !requireGlossaryRepo(c) {
+		return
+	}
+
+	var req CorrectionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.Corrections) == 0 {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "corrections is required")
+		return
+	}
+	if len(req.Corrections) > maxCorrectionsPerRequest {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest,
+			fmt.Sprintf("too many corrections (max %d)", maxCorrectionsPerRequest))
+		return
+	}
+
+	// Only the owner may correct a recording: the corrections land in their glossary and the DB row
+	userID := requestUserID(c)
+	rec, ok := storage.GetRecording(id)
+	if !ok || rec.UserID != userID.String() {
+		utils.Error(c, http.StatusNotFound, utils.CodeRecordingNotFound, "recording not found")
+		return
+	}
+	if rec.Transcript == "" {
+		utils.Error(c, http.StatusBadRequest, utils.CodeTranscriptNotAvailable, "recording has no transcript to correct")
+		return
+	}
+
+	// Validate every pair before storing any, dropping repeats of the same wrong spelling
+	entries := make([]model.GlossaryEntry, 0, len(req.Corrections))
+	seen := make(map[string]bool)
+	for i, pair := range req.Corrections {
+		if strings.TrimSpace(pair.Wrong) == "" {
+			utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, fmt.Sprintf("corrections[%d]: wrong is required", i))
+			return
+		}
+		entry, err := newGlossaryEntry(pair.Right, pair.Wrong)
+		if err != nil {
+			utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, fmt.Sprintf("corrections[%d]: %v", i, err))
+			return
+		}
+		if key := strings.ToLower(*entry.Wrong); !seen[key] {
+			seen[key] = true
+			entries = append(entries, *entry)
+		}
+	}
+
+	added, updated, err := sttRepo.SaveGlossaryCorrections(c.Request.Context(), userID, entries, appConfig.GlossaryMaxEntries)
+	if errors.Is(err, repository.ErrGlossaryFull) {
+		utils.Error(c, http.StatusUnprocessableEntity, utils.CodeGlossaryFull,
+			fmt.Sprintf("glossary is full (max %d entries), remove entries before adding corrections", appConfig.GlossaryMaxEntries))
+		return
+	}
+	if err != nil {
+		log.Printf("Error adding corrections of recording %s to glossary: %v", id, err)
+		utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to store corrections")
+		return
+	}
+
+	corrections := make([]string, len(entries))
+	for i, e := range entries {
+		corrections[i] = *e.Wrong + " → " + e.Term
+	}
+	storage.AddUserCorrections(id, corrections)
+	syncToDatabase(id, userID, rec.Provider)
+	log.Printf("Stored %d corrections for recording %s, %d new and %d updated in the glossary of user %s",
+		len(corrections), id, len(added), len(updated), userID)
+
+	utils.Success(c, gin.H{
+		"recording_id":       id,
+		"corrections":        corrections,
+		"glossary_added":     added,
+		"glossary_updated":   updated,
+		"glossary_unchanged": len(entries) - len(added) - len(updated),
+	})
+}
@@ -2,10 +2,15 @@ package api
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"log"
 	"noteme/internal/ai"
 	"noteme/internal/model"
+	"noteme/internal/repository"
 	"noteme/internal/storage"
+	"noteme/internal/stt"
 	"sync"
 	"time"
 
@@ -39,11 +44,17 @@ func syncToDatabase(recordingID string, userID uuid.UUID, providerName string) u
 	dbUUID, exists := recordingIDToDBUUIDMap[recordingID]
 	mapMu.Unlock()
 
+	// The map is lost on restart; fall back to the recording_id stored in metadata
+	if !exists {
+		dbUUID, exists = lookupDBUUID(ctx, recordingID)
+	}
+
 	if exists {
 		// Update existing record
 		updateReq := &model.STTRequest{
-			ID:     dbUUID,
-			Status: rec.Status,
+			ID:       dbUUID,
+			Status:   rec.Status,
+			Provider: providerName,
 		}
 
 		// Set audio_duration_ms (convert from seconds to milliseconds)
@@ -59,9 +70,50 @@ func syncToDatabase(recordingID string, userID uuid.UUID, providerName string) u
 		}
 
 		// Set transcript and confidence if available
+		if rec.Language != "" {
+			updateReq.Language = &rec.Language
+		}
 		if rec.Transcript != "" {
 			updateReq.Transcript = &rec.Transcript
-			updateReq.Confidence = &rec.Confidence
+			// Unscored transcripts keep a NULL confidence column; the raw value stays in metadata
+			if stt.HasConfidence(rec.Confidence) {
+				updateReq.Confidence = &rec.Confidence
+			}
+			updateReq.Metadata = map[string]interface{}{
+				"low_confidence":       rec.LowConfidence,
+				"raw_confidence":       rec.RawConfidence,
+				"confidence_available": stt.HasConfidence(rec.Confidence),
+			}
+			if rec.CleaningTime > 0 {
+				updateReq.Metadata["ai_cleaning_time_ms"] = rec.CleaningTime
+			}
+			addCleaningSkipMetadata(updateReq.Metadata, rec)
+			if len(rec.Segments) > 0 {
+				updateReq.Metadata["segments"] = rec.Segments
+			}
+			if len(rec.Sentences) > 0 {
+				updateReq.Metadata["sentences"] = rec.Sentences
+			}
+			if len(rec.DecodedWords) > 0 {
+				updateReq.Metadata["decoded_words"] = rec.DecodedWords
+			}
+			if len(rec.Corrections) > 0 {
+				updateReq.Metadata["user_corrections"] = rec.Corrections
+			}
+			if rec.RawTranscript != "" {
+				updateReq.Metadata["raw_transcript"] = rec.RawTranscript
+				updateReq.Metadata["profanity_filtered"] = true
+			}
+			if rec.OriginalTranscript != nil {
+				updateReq.Metadata["original_transcript"] = rec.OriginalTranscript
+			}
+			addPromptVariantMetadata(updateReq.Metadata, rec)
+		}
+
+		// Set STT processing time
+		if rec.ProcessingTime > 0 {
+			processingMs := rec.ProcessingTime
+			updateReq.ProcessingTimeMs = &processingMs
 		}
 
 		// Set error message if failed
@@ -90,6 +142,14 @@ func syncToDatabase(recordingID string, userID uuid.UUID, providerName string) u
 		},
 	}
 
+	// Persist upload dedupe keys so retries can be matched later
+	if rec.IdempotencyKey != "" {
+		sttReq.Metadata["idempotency_key"] = rec.IdempotencyKey
+	}
+	if rec.ContentHash != "" {
+		sttReq.Metadata["content_sha256"] = rec.ContentHash
+	}
+
 	// Set audio format
 	if rec.Path != "" {
 		format := getAudioFormatFromPath(rec.Path)
@@ -119,9 +179,44 @@ func syncToDatabase(recordingID string, userID uuid.UUID, providerName string) u
 	}
 
 	// Set transcript and confidence if available
+	if rec.Language != "" {
+		sttReq.Language = &rec.Language
+	}
 	if rec.Transcript != "" {
 		sttReq.Transcript = &rec.Transcript
-		sttReq.Confidence = &rec.Confidence
+		if stt.HasConfidence(rec.Confidence) {
+			sttReq.Confidence = &rec.Confidence
+		}
+		sttReq.Metadata["low_confidence"] = rec.LowConfidence
+		sttReq.Metadata["raw_confidence"] = rec.RawConfidence
+		sttReq.Metadata["confidence_available"] = stt.HasConfidence(rec.Confidence)
+		if rec.CleaningTime > 0 {
+			sttReq.Metadata["ai_cleaning_time_ms"] = rec.CleaningTime
+		}
+		addCleaningSkipMetadata(sttReq.Metadata, rec)
+		if len(rec.Segments) > 0 {
+			sttReq.Metadata["segments"] = rec.Segments
+		}
+		if len(rec.Sentences) > 0 {
+			sttReq.Metadata["sentences"] = rec.Sentences
+		}
+		if len(rec.DecodedWords) > 0 {
+			sttReq.Metadata["decoded_words"] = rec.DecodedWords
+		}
+		if len(rec.Corrections) > 0 {
+			sttReq.Metadata["user_corrections"] = rec.Corrections
+		}
+		if rec.RawTranscript != "" {
+			sttReq.Metadata["raw_transcript"] = rec.RawTranscript
+			sttReq.Metadata["profanity_filtered"] = true
+		}
+		addPromptVariantMetadata(sttReq.Metadata, rec)
+	}
+
+	// Set STT processing time
+	if rec.ProcessingTime > 0 {
+		processingMs := rec.ProcessingTime
+		sttReq.ProcessingTimeMs = &processingMs
 	}
 
 	// Set error message if failed
@@ -131,6 +226,11 @@ func syncToDatabase(recordingID string, userID uuid.UUID, providerName string) u
 
 	// Create record
 	if err := sttRepo.Create(ctx, sttReq); err != nil {
+		// A concurrent sync may have created the row first (unique recording_id index)
+		if existingUUID, found := lookupDBUUID(ctx, recordingID); found {
+			log.Printf("Recording %s was already synced concurrently (UUID: %s)", recordingID, existingUUID)
+			return existingUUID
+		}
 		log.Printf("Error: Failed to create recording %s in database: %v", recordingID, err)
 		return uuid.Nil
 	}
@@ -144,6 +244,41 @@ func syncToDatabase(recordingID string, userID uuid.UUID, providerName string) u
 	return sttReq.ID
 }
 
+// addPromptVariantMetadata records the A/B prompt experiment variant of a recording, if it has one
+func addPromptVariantMetadata(metadata map[string]interface{}, rec *storage.Recording) {
+	if rec.PromptVariant == "" {
+		return
+	}
+	metadata["prompt_experiment"] = rec.PromptExperiment
+	metadata["prompt_variant"] = rec.PromptVariant
+}
+
+// addCleaningSkipMetadata records whether AI cleaning ran on the transcript and, if not, why
+func addCleaningSkipMetadata(metadata map[string]interface{}, rec *storage.Recording) {
+	metadata["ai_cleaning_skipped"] = rec.CleaningSkip != ""
+	if rec.CleaningSkip != "" {
+		metadata["ai_cleaning_skip_reason"] = rec.CleaningSkip
+	}
+}
+
+// lookupDBUUID finds the DB row for a recording by metadata.recording_id and caches the mapping
+func lookupDBUUID(ctx context.Context, recordingID string) (uuid.UUID, bool) {
+	existing, err := sttRepo.GetByRecordingID(ctx, recordingID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Warning: Failed to look up recording %s in database: %v", recordingID, err)
+		}
+		return uuid.Nil, false
+	}
+
+	mapMu.Lock()
+	recordingIDToDBUUIDMap[recordingID] = existing.ID
+	mapMu.Unlock()
+
+	log.Printf("Found existing database row for recording %s (UUID: %s)", recordingID, existing.ID)
+	return existing.ID, true
+}
+
 // syncAnalysisToDatabase syncs AI analysis to database metadata
 func syncAnalysisToDatabase(recordingID string, analysis *ai.AnalysisResult) {
 	if sttRepo == nil {
@@ -157,6 +292,9 @@ func syncAnalysisToDatabase(recordingID string, analysis *ai.AnalysisResult) {
 	dbUUID, exists := recordingIDToDBUUIDMap[recordingID]
 	mapMu.Unlock()
 
+	if !exists {
+		dbUUID, exists = lookupDBUUID(ctx, recordingID)
+	}
 	if !exists {
 		log.Printf("Warning: No DB UUID found for recording %s, skipping analysis sync", recordingID)
 		return
@@ -167,13 +305,36 @@ func syncAnalysisToDatabase(recordingID string, analysis *ai.AnalysisResult) {
 		"recording_id": recordingID,
 		"ai_analysis": map[string]interface{}{
 			"context":      analysis.Context,
+			"title":        analysis.Title,
+			"language":     analysis.Language,
 			"summary":      analysis.Summary,
 			"key_points":   analysis.KeyPoints,
 			"action_items": analysis.ActionItems,
 			"zalo_brief":   analysis.ZaloBrief,
 			"questions":    analysis.Questions,
+			"entities":     analysis.Entities,
 		},
 	}
+	if analysis.Chunks > 0 || analysis.Truncated {
+		aiAnalysis := metadata["ai_analysis"].(map[string]interface{})
+		aiAnalysis["chunks"] = analysis.Chunks
+		aiAnalysis["truncated"] = analysis.Truncated
+	}
+	if rec, ok := storage.GetRecording(recordingID); ok {
+		addPromptVariantMetadata(metadata, rec)
+	}
+	if analysis.Version > 0 {
+		aiAnalysis := metadata["ai_analysis"].(map[string]interface{})
+		aiAnalysis["analysis_version"] = analysis.Version
+		aiAnalysis["transcript_hash"] = analysis.TranscriptHash
+	}
+	if analysis.PromptTemplate != "" {
+		aiAnalysis := metadata["ai_analysis"].(map[string]interface{})
+		aiAnalysis["prompt_template"] = analysis.PromptTemplate
+		if analysis.SystemPrompt != "" {
+			aiAnalysis["system_prompt"] = analysis.SystemPrompt
+		}
+	}
 
 	// Update metadata, title, and status in database
 	updateReq := &model.STTRequest{
@@ -181,26 +342,194 @@ func syncAnalysisToDatabase(recordingID string, analysis *ai.AnalysisResult) {
 		Status:   "success", // Set status to success when analysis completes
 		Metadata: metadata,
 	}
-	// Convert string title to *string
-	if analysis.Title != "" {
-		title := analysis.Title
-		updateReq.Title = &title
-	}
 
-	if err := sttRepo.UpdateResult(ctx, updateReq); err != nil {
+	// Store the analysis and its title together so readers never see one without the other
+	needsGeneratedTitle := false
+	err := sttRepo.WithTx(ctx, func(repo repository.STTRepository) error {
+		if err := repo.UpdateResult(ctx, updateReq); err != nil {
+			return err
+		}
+
+		// Only set a title when none is set, so user-set titles are preserved
+		existing, err := repo.GetByID(ctx, dbUUID)
+		if err != nil {
+			return err
+		}
+		if existing.Title != nil && *existing.Title != "" {
+			return nil
+		}
+		if analysis.Title == "" {
+			needsGeneratedTitle = true
+			return nil
+		}
+		return repo.UpdateTitle(ctx, dbUUID, analysis.Title)
+	})
+	if err != nil {
 		log.Printf("Warning: Failed to sync analysis for recording %s to database: %v", recordingID, err)
 		return
 	}
 
 	log.Printf("Synced analysis for recording %s to database with status=success", recordingID)
+
+	// The AI fallback runs outside the transaction to avoid holding row locks during the API call
+	if needsGeneratedTitle {
+		syncGeneratedTitle(ctx, recordingID, dbUUID, analysis)
+	}
+}
+
+// syncGeneratedTitle asks the AI for a title when the analysis has none and stores it
+func syncGeneratedTitle(ctx context.Context, recordingID string, dbUUID uuid.UUID, analysis *ai.AnalysisResult) {
+	title, err := ai.GenerateTitle(ctx, analysis.Summary)
+	if err != nil {
+		log.Printf("Warning: Failed to generate title for recording %s: %v", recordingID, err)
+		return
+	}
+
+	if err := sttRepo.UpdateTitle(ctx, dbUUID, title); err != nil {
+		log.Printf("Warning: Failed to store generated title for recording %s: %v", recordingID, err)
+		return
+	}
+
+	log.Printf("Generated title for recording %s: %s", recordingID, title)
+}
+
+// purgeInMemoryRecording drops a deleted DB record's in-memory recording, analysis,
+// retrieval embedding and ID mapping so it no longer shows up in Ask Anything.
+// recordingID may be empty; the mapping is also searched by DB UUID.
+func purgeInMemoryRecording(dbUUID uuid.UUID, recordingID string) {
+	mapMu.Lock()
+	for recID, mappedUUID := range recordingIDToDBUUIDMap {
+		if mappedUUID == dbUUID {
+			recordingID = recID
+			delete(recordingIDToDBUUIDMap, recID)
+		}
+	}
+	if recordingID != "" {
+		delete(recordingIDToDBUUIDMap, recordingID)
+	}
+	mapMu.Unlock()
+
+	if recordingID == "" {
+		log.Printf("No in-memory recording mapped to %s, nothing to purge", dbUUID)
+		return
+	}
+
+	storage.DeleteRecording(recordingID)
+	storage.DeleteAnalysis(recordingID)
+	ai.RemoveFromIndex(recordingID)
+	log.Printf("Purged in-memory data for recording %s (UUID: %s)", recordingID, dbUUID)
 }
 
-// getDefaultUserID returns a default user ID for MVP
-// In production, this should come from authentication
-func getDefaultUserID() uuid.UUID {
-	// Use a fixed UUID for MVP
-	// In production, get from JWT token or session
-	return uuid.MustParse("00000000-0000-0000-0000-000000000001")
+// syncAudioDeletedToDatabase clears audio_url of a recording whose audio was removed after processing
+func syncAudioDeletedToDatabase(recordingID string) {
+	if sttRepo == nil {
+		return // No database, skip
+	}
+
+	ctx := context.Background()
+
+	mapMu.Lock()
+	dbUUID, exists := recordingIDToDBUUIDMap[recordingID]
+	mapMu.Unlock()
+
+	if !exists {
+		dbUUID, exists = lookupDBUUID(ctx, recordingID)
+	}
+	if !exists {
+		log.Printf("Warning: No DB UUID found for recording %s, skipping audio deletion sync", recordingID)
+		return
+	}
+
+	if err := sttRepo.ClearAudio(ctx, dbUUID); err != nil {
+		log.Printf("Warning: Failed to clear audio_url for recording %s in database: %v", recordingID, err)
+	}
+}
+
+// syncPeaksToDatabase caches computed waveform peaks in metadata.waveform_peaks
+func syncPeaksToDatabase(recordingID string, peaks []float64) {
+	if sttRepo == nil {
+		return // No database, skip
+	}
+
+	ctx := context.Background()
+
+	mapMu.Lock()
+	dbUUID, exists := recordingIDToDBUUIDMap[recordingID]
+	mapMu.Unlock()
+
+	if !exists {
+		dbUUID, exists = lookupDBUUID(ctx, recordingID)
+	}
+	if !exists {
+		log.Printf("Warning: No DB UUID found for recording %s, skipping peaks sync", recordingID)
+		return
+	}
+
+	if err := sttRepo.UpdatePeaks(ctx, dbUUID, peaks); err != nil {
+		log.Printf("Warning: Failed to cache peaks for recording %s in database: %v", recordingID, err)
+	}
+}
+
+// loadPeaksFromDatabase reads waveform peaks cached in metadata.waveform_peaks
+func loadPeaksFromDatabase(recordingID string) ([]float64, bool) {
+	if sttRepo == nil {
+		return nil, false
+	}
+
+	existing, err := sttRepo.GetByRecordingID(context.Background(), recordingID)
+	if err != nil {
+		return nil, false
+	}
+	raw, ok := existing.Metadata["waveform_peaks"].([]interface{})
+	if !ok || len(raw) == 0 {
+		return nil, false
+	}
+
+	peaks := make([]float64, 0, len(raw))
+	for _, v := range raw {
+		p, ok := v.(float64)
+		if !ok {
+			log.Printf("Warning: Ignoring malformed waveform_peaks of recording %s", recordingID)
+			return nil, false
+		}
+		peaks = append(peaks, p)
+	}
+	return peaks, true
+}
+
+// forgetEvictedRecording drops the ID mapping and embedding of a recording evicted from memory.
+// The DB row is kept; analyses can still be loaded back from metadata.
+func forgetEvictedRecording(recordingID string) {
+	mapMu.Lock()
+	delete(recordingIDToDBUUIDMap, recordingID)
+	mapMu.Unlock()
+	ai.RemoveFromIndex(recordingID)
+}
+
+// loadAnalysisFromDatabase reads a persisted analysis back from metadata.ai_analysis
+// and re-populates the in-memory store, so analyses survive a restart
+func loadAnalysisFromDatabase(recordingID string) (*ai.AnalysisResult, bool) {
+	if sttRepo == nil {
+		return nil, false
+	}
+
+	analysisJSON, err := sttRepo.GetAnalysisJSON(context.Background(), recordingID)
+	if err != nil {
+		log.Printf("No persisted analysis for recording %s: %v", recordingID, err)
+		return nil, false
+	}
+
+	var result ai.AnalysisResult
+	if err := json.Unmarshal(analysisJSON, &result); err != nil {
+		log.Printf("Warning: Failed to decode persisted analysis for recording %s: %v", recordingID, err)
+		return nil, false
+	}
+
+	storage.SaveAnalysis(recordingID, &result)
+	log.Printf("Loaded analysis for recording %s from database", recordingID)
+
+	resultCopy := result
+	return &resultCopy, true
 }
 
 func getAudioFormatFromPath(path string) *string {
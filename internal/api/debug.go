@@ -0,0 +1,25 @@
+package api
+
+import (
+	"noteme/internal/storage"
+	"noteme/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// debugEndpointsEnabled returns ENABLE_DEBUG_ENDPOINTS (admin-only diagnostics, off by default)
+func debugEndpointsEnabled() bool {
+	return appConfig.EnableDebugEndpoints
+}
+
+// getStorageStats reports in-memory map sizes to help spot leaks
+func getStorageStats(c *gin.Context) {
+	mapMu.Lock()
+	mappings := len(recordingIDToDBUUIDMap)
+	mapMu.Unlock()
+
+	utils.Success(c, gin.H{
+		"storage":         storage.GetStats(),
+		"db_uuid_mapping": mappings,
+	})
+}
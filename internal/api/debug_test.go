@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDebugStorageIsAdminOnly(t *testing.T) {
+	withAdminToken(t)
+	appConfig.EnableDebugEndpoints = true
+	t.Cleanup(func() { appConfig.EnableDebugEndpoints = false })
+	r := newTestRouter()
+
+	tests := []struct {
+		name   string
+		apiKey string
+		token  string
+		status int
+	}{
+		{"user without admin token", testAPIKey, "", http.StatusForbidden},
+		{"user with a wrong admin token", testAPIKey, "wrong", http.StatusForbidden},
+		{"no credential", "", testAdminToken, http.StatusUnauthorized},
+		{"admin", testAPIKey, testAdminToken, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/debug/storage", nil)
+			if tt.token != "" {
+				req.Header.Set("X-Admin-Token", tt.token)
+			}
+			w, resp := doRequest(t, r, req, tt.apiKey)
+			if w.Code != tt.status {
+				t.Fatalf("status %d, want %d, body %s", w.Code, tt.status, w.Body.String())
+			}
+			if tt.status == http.StatusOK {
+				if _, ok := resp.Data["storage"]; !ok {
+					t.Errorf("no storage stats in %s", w.Body.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"noteme/internal/ai"
+	"noteme/internal/storage"
+	"noteme/internal/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultDigestDays is the range used when a digest request gives no from date
+const defaultDigestDays = 7
+
+// DigestRequest represents a request for a digest over a date range
+type DigestRequest struct {
+	From           string   `json:"from,omitempty"`            // RFC3339 or YYYY-MM-DD (inclusive), default 7 days before to
+	To             string   `json:"to,omitempty"`              // RFC3339 or YYYY-MM-DD (inclusive), default now
+	OutputLanguage string   `json:"output_language,omitempty"` // vi (default) or en
+	Temperature    *float64 `json:"temperature,omitempty"`     // optional, 0-1.5 (default 0.3)
+	MaxTokens      int      `json:"max_tokens,omitempty"`      // optional, 16-4096
+}
+
+// digestRecordings handles POST /api/v1/ai/digest.
+// Consolidates the analyses of the caller's recordings in the date range into one summary with
+// highlights, decisions and merged action items.
+func digestRecordings(c *gin.Context) {
+	var req DigestRequest
+	if err := bindOptionalJSON(c, &req); err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	scope := &askScope{to: time.Now()}
+	var err error
+	if req.To != "" {
+		if scope.to, err = parseAskDate(req.To, true); err != nil {
+			utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "invalid to: "+err.Error())
+			return
+		}
+	}
+	if req.From != "" {
+		if scope.from, err = parseAskDate(req.From, false); err != nil {
+			utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "invalid from: "+err.Error())
+			return
+		}
+	} else {
+		scope.from = scope.to.AddDate(0, 0, -defaultDigestDays)
+	}
+	if scope.to.Before(scope.from) {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "to must not be before from")
+		return
+	}
+
+	outputLanguage, err := ai.NormalizeOutputLanguage(req.OutputLanguage)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	ctx, err := generationContext(c.Request.Context(), req.Temperature, req.MaxTokens)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	analysisContexts, _ := scope.apply(buildAnalysisContexts(storage.GetAnalysesByUser(requestUserID(c).String())))
+	if len(analysisContexts) == 0 {
+		utils.Error(c, http.StatusBadRequest, utils.CodeNoAnalysisData, "no analysis data in the given date range")
+		return
+	}
+
+	log.Printf("Digest request: %d analyses from %s to %s", len(analysisContexts),
+		scope.from.Format(time.RFC3339), scope.to.Format(time.RFC3339))
+
+	digest, err := ai.BuildDigest(ctx, analysisContexts, outputLanguage)
+	if err != nil {
+		log.Printf("Digest error: %v", err)
+		if errors.Is(err, ai.ErrOpenAITimeout) {
+			utils.Error(c, http.StatusGatewayTimeout, utils.CodeAITimeout, "failed to build digest: "+err.Error())
+			return
+		}
+		if errors.Is(err, ai.ErrAINotConfigured) {
+			utils.Error(c, http.StatusServiceUnavailable, utils.CodeAINotConfigured, err.Error())
+			return
+		}
+		utils.Error(c, http.StatusInternalServerError, utils.CodeAIFailed, "failed to build digest: "+err.Error())
+		return
+	}
+
+	utils.Success(c, gin.H{
+		"from":   scope.from.Format(time.RFC3339),
+		"to":     scope.to.Format(time.RFC3339),
+		"digest": digest,
+	})
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"noteme/internal/export"
+	"noteme/internal/model"
+	"noteme/internal/repository"
+	"noteme/internal/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportAllPageSize is how many rows are loaded per query while streaming a bulk export
+const exportAllPageSize = 50
+
+// exportManifest is written as manifest.json at the end of a bulk export
+type exportManifest struct {
+	UserID     string                `json:"user_id"`
+	Format     string                `json:"format"`
+	ExportedAt time.Time             `json:"exported_at"`
+	Files      []exportManifestEntry `json:"files"`
+	Skipped    []exportManifestEntry `json:"skipped"`
+}
+
+// exportManifestEntry describes one record of a bulk export
+type exportManifestEntry struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	File      string    `json:"file,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// exportAllSTT handles GET /api/stt/export/all?format=markdown|json|pdf.
+// Streams a ZIP with one file per analyzed recording of the user plus manifest.json;
+// rows are read page by page so memory stays bounded.
+func exportAllSTT(c *gin.Context) {
+	userID, ok := historyUserID(c)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "markdown")
+	renderer, err := export.RendererFor(format)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Fail before any ZIP bytes are sent when the history cannot be read at all
+	page, err := sttRepo.ListByUser(c.Request.Context(), userID, exportAllPageSize, 0, repository.ListOptions{})
+	if err != nil {
+		log.Printf("Error listing STT requests for bulk export: %v", err)
+		utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to retrieve history")
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="noteme_export_%s.zip"`, time.Now().Format("20060102")))
+	c.Status(http.StatusOK)
+
+	zw := zip.NewWriter(c.Writer)
+	manifest := exportManifest{
+		UserID:     userID.String(),
+		Format:     format,
+		ExportedAt: time.Now().UTC(),
+		Files:      []exportManifestEntry{},
+		Skipped:    []exportManifestEntry{},
+	}
+
+	for offset := 0; ; offset += exportAllPageSize {
+		if offset > 0 {
+			page, err = sttRepo.ListByUser(c.Request.Context(), userID, exportAllPageSize, offset, repository.ListOptions{})
+			if err != nil {
+				// Headers are already sent: stop here, the truncated ZIP fails to open on the client
+				log.Printf("Error listing STT requests for bulk export of user %s: %v", userID, err)
+				c.Abort()
+				return
+			}
+		}
+
+		for i := range page {
+			entry, err := writeExportEntry(zw, renderer, &page[i])
+			if err != nil {
+				log.Printf("Error writing bulk export of user %s: %v", userID, err)
+				c.Abort()
+				return
+			}
+			if entry.File != "" {
+				manifest.Files = append(manifest.Files, entry)
+			} else {
+				manifest.Skipped = append(manifest.Skipped, entry)
+			}
+		}
+		c.Writer.Flush()
+
+		if len(page) < exportAllPageSize {
+			break
+		}
+	}
+
+	if err := writeExportManifest(zw, &manifest); err != nil {
+		log.Printf("Error writing bulk export manifest of user %s: %v", userID, err)
+		c.Abort()
+		return
+	}
+	if err := zw.Close(); err != nil {
+		log.Printf("Error finishing bulk export of user %s: %v", userID, err)
+		return
+	}
+
+	log.Printf("Bulk exported %d analyses of user %s (%s, %d skipped)",
+		len(manifest.Files), userID, format, len(manifest.Skipped))
+}
+
+// writeExportEntry renders one record into the ZIP. Records without an analysis, or whose
+// analysis cannot be rendered, are returned without File and listed as skipped.
+func writeExportEntry(zw *zip.Writer, renderer export.Renderer, req *model.STTRequest) (exportManifestEntry, error) {
+	entry := exportManifestEntry{ID: req.ID.String(), CreatedAt: req.CreatedAt}
+	if req.Title != nil {
+		entry.Title = *req.Title
+	}
+
+	doc, err := export.FromSTTRequest(req)
+	if err != nil {
+		if errors.Is(err, export.ErrNoAnalysis) {
+			entry.Reason = "no analysis"
+		} else {
+			log.Printf("Warning: skipping %s in bulk export: %v", req.ID, err)
+			entry.Reason = "invalid analysis"
+		}
+		return entry, nil
+	}
+
+	data, err := renderer.Render(doc)
+	if err != nil {
+		log.Printf("Warning: skipping %s in bulk export: %v", req.ID, err)
+		entry.Reason = "render failed"
+		return entry, nil
+	}
+
+	name := export.Filename(doc, renderer)
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: req.CreatedAt})
+	if err != nil {
+		return entry, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return entry, err
+	}
+
+	entry.File = name
+	return entry, nil
+}
+
+// writeExportManifest adds manifest.json to the ZIP
+func writeExportManifest(zw *zip.Writer, manifest *exportManifest) error {
+	w, err := zw.Create("manifest.json")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(manifest)
+}
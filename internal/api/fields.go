@@ -0,0 +1,70 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Fields each endpoint accepts in ?fields=, i.e. every key its response objects can have
+var (
+	sttDetailFields = []string{
+		"id", "user_id", "audio_url", "status", "created_at", "title", "audio_format", "audio_duration_ms",
+		"audio_size_bytes", "transcript", "confidence", "error_message", "processing_time_ms", "language",
+		"segments", "labeled_transcript", "sentences", "tags", "metadata",
+	}
+	sttHistoryFields = []string{
+		"id", "created_at", "status", "title", "audio_url", "audio_format", "audio_duration_ms",
+		"confidence", "transcript_preview", "tags",
+	}
+	sttSearchFields = []string{
+		"id", "created_at", "status", "title", "audio_url", "audio_format", "audio_duration_ms",
+		"transcript_preview", "summary", "action_items",
+	}
+)
+
+// parseFields reads ?fields=id,title,status (sparse fieldsets). It returns nil when the parameter
+// is absent, meaning all fields, and an error naming the first field not in known.
+func parseFields(c *gin.Context, known []string) (map[string]bool, error) {
+	v := strings.TrimSpace(c.Query("fields"))
+	if v == "" {
+		return nil, nil
+	}
+
+	allowed := make(map[string]bool, len(known))
+	for _, field := range known {
+		allowed[field] = true
+	}
+
+	fields := make(map[string]bool)
+	for _, field := range strings.Split(v, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !allowed[field] {
+			return nil, fmt.Errorf("unknown field %q. Supported: %s", field, strings.Join(known, ", "))
+		}
+		fields[field] = true
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("fields must list at least one field")
+	}
+	return fields, nil
+}
+
+// projectFields keeps only the requested keys of obj; nil fields keeps everything.
+// Optional keys missing from obj stay absent.
+func projectFields(obj gin.H, fields map[string]bool) gin.H {
+	if fields == nil {
+		return obj
+	}
+	out := make(gin.H, len(fields))
+	for key := range fields {
+		if value, ok := obj[key]; ok {
+			out[key] = value
+		}
+	}
+	return out
+}
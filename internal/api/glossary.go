@@ -0,0 +1,195 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"noteme/internal/ai"
+	"noteme/internal/model"
+	"noteme/internal/repository"
+	"noteme/internal/utils"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxGlossaryTermRunes caps each term and wrong spelling of a glossary entry
+const maxGlossaryTermRunes = 100
+
+// GlossaryEntryRequest is the body of POST and PUT /api/v1/glossary: a preserved term,
+// or a correction when wrong is set ("wrong" is rewritten to "term" when cleaning)
+type GlossaryEntryRequest struct {
+	Term  string `json:"term"`
+	Wrong string `json:"wrong"`
+}
+
+// listGlossary handles GET /api/v1/glossary
+func listGlossary(c *gin.Context) {
+	if !requireGlossaryRepo(c) {
+		return
+	}
+
+	entries, err := sttRepo.ListGlossary(c.Request.Context(), requestUserID(c))
+	if err != nil {
+		log.Printf("Error listing glossary: %v", err)
+		utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to retrieve glossary")
+		return
+	}
+	utils.Success(c, gin.H{
+		"entries":     entries,
+		"count":       len(entries),
+		"max_entries": appConfig.GlossaryMaxEntries,
+	})
+}
+
+// createGlossaryEntry handles POST /api/v1/glossary
+func createGlossaryEntry(c *gin.Context) {
+	if !requireGlossaryRepo(c) {
+		return
+	}
+	entry, ok := bindGlossaryEntry(c)
+	if !ok {
+		return
+	}
+
+	added, err := sttRepo.AddGlossaryEntries(c.Request.Context(), entry.UserID, []model.GlossaryEntry{*entry}, appConfig.GlossaryMaxEntries)
+	switch {
+	case errors.Is(err, repository.ErrGlossaryFull):
+		utils.Error(c, http.StatusUnprocessableEntity, utils.CodeGlossaryFull,
+			fmt.Sprintf("glossary is full (max %d entries)", appConfig.GlossaryMaxEntries))
+		return
+	case err != nil:
+		log.Printf("Error adding glossary entry: %v", err)
+		utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to add glossary entry")
+		return
+	case len(added) == 0:
+		utils.Error(c, http.StatusConflict, utils.CodeAlreadyExists, "glossary already has this entry")
+		return
+	}
+	utils.SuccessWithStatus(c, http.StatusCreated, gin.H{"entry": added[0]})
+}
+
+// updateGlossaryEntry handles PUT /api/v1/glossary/:id
+func updateGlossaryEntry(c *gin.Context) {
+	if !requireGlossaryRepo(c) {
+		return
+	}
+	entry, ok := bindGlossaryEntry(c)
+	if !ok {
+		return
+	}
+	entry.ID = uuidParam(c, "id")
+
+	err := sttRepo.UpdateGlossaryEntry(c.Request.Context(), entry)
+	switch {
+	case errors.Is(err, repository.ErrGlossaryEntryNotFound):
+		utils.Error(c, http.StatusNotFound, utils.CodeNotFound, "glossary entry not found")
+		return
+	case errors.Is(err, repository.ErrGlossaryDuplicate):
+		utils.Error(c, http.StatusConflict, utils.CodeAlreadyExists, "glossary already has this entry")
+		return
+	case err != nil:
+		log.Printf("Error updating glossary entry %s: %v", entry.ID, err)
+		utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to update glossary entry")
+		return
+	}
+	utils.Success(c, gin.H{"entry": entry})
+}
+
+// deleteGlossaryEntry handles DELETE /api/v1/glossary/:id
+func deleteGlossaryEntry(c *gin.Context) {
+	if !requireGlossaryRepo(c) {
+		return
+	}
+	id := uuidParam(c, "id")
+
+	err := sttRepo.DeleteGlossaryEntry(c.Request.Context(), requestUserID(c), id)
+	if errors.Is(err, repository.ErrGlossaryEntryNotFound) {
+		utils.Error(c, http.StatusNotFound, utils.CodeNotFound, "glossary entry not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error deleting glossary entry %s: %v", id, err)
+		utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to delete glossary entry")
+		return
+	}
+	utils.Success(c, gin.H{"id": id.String(), "status": "deleted"})
+}
+
+// requireGlossaryRepo answers 503 when no database is configured; glossaries are only stored there
+func requireGlossaryRepo(c *gin.Context) bool {
+	if sttRepo == nil {
+		utils.Error(c, http.StatusServiceUnavailable, utils.CodeInternal, "glossary requires a database (DATABASE_URL)")
+		return false
+	}
+	return true
+}
+
+// bindGlossaryEntry parses and validates a GlossaryEntryRequest for the requesting user
+func bindGlossaryEntry(c *gin.Context) (*model.GlossaryEntry, bool) {
+	var req GlossaryEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "invalid request body")
+		return nil, false
+	}
+	entry, err := newGlossaryEntry(req.Term, req.Wrong)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return nil, false
+	}
+	entry.UserID = requestUserID(c)
+	return entry, true
+}
+
+// newGlossaryEntry validates a term and optional wrong spelling. Both are trimmed, must fit in
+// maxGlossaryTermRunes and stay on one line, since they are pasted into the cleaning prompt.
+func newGlossaryEntry(term string, wrong string) (*model.GlossaryEntry, error) {
+	term, wrong = strings.TrimSpace(term), strings.TrimSpace(wrong)
+	if term == "" {
+		return nil, fmt.Errorf("term is required")
+	}
+	for _, f := range []struct{ name, value string }{{"term", term}, {"wrong", wrong}} {
+		if utf8.RuneCountInString(f.value) > maxGlossaryTermRunes {
+			return nil, fmt.Errorf("%s is too long (max %d characters)", f.name, maxGlossaryTermRunes)
+		}
+		if strings.IndexFunc(f.value, unicode.IsControl) >= 0 {
+			return nil, fmt.Errorf("%s must be a single line", f.name)
+		}
+	}
+
+	entry := &model.GlossaryEntry{Term: term}
+	if wrong != "" {
+		if strings.EqualFold(wrong, term) {
+			return nil, fmt.Errorf("wrong must differ from term")
+		}
+		entry.Wrong = &wrong
+	}
+	return entry, nil
+}
+
+// userGlossary returns a user's glossary for the cleaning prompt; empty without a database
+// or when it cannot be loaded, since cleaning still works without it
+func userGlossary(ctx context.Context, userID uuid.UUID) ai.Glossary {
+	var g ai.Glossary
+	if sttRepo == nil || userID == uuid.Nil {
+		return g
+	}
+	entries, err := sttRepo.ListGlossary(ctx, userID)
+	if err != nil {
+		log.Printf("Warning: failed to load glossary of user %s: %v", userID, err)
+		return g
+	}
+	for _, e := range entries {
+		if e.Wrong != nil {
+			g.Corrections = append(g.Corrections, ai.GlossaryCorrection{Wrong: *e.Wrong, Right: e.Term})
+		} else {
+			g.Terms = append(g.Terms, e.Term)
+		}
+	}
+	return g
+}
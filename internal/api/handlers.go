@@ -1,30 +1,50 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"noteme/internal/ai"
+	"noteme/internal/audio"
+	"noteme/internal/config"
+	"noteme/internal/events"
 	"noteme/internal/storage"
 	"noteme/internal/stt"
 	"noteme/internal/utils"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 var (
+	// appConfig holds the settings of the handlers and the STT providers they create.
+	// It starts at the defaults and is replaced by Configure at startup.
+	appConfig = config.Default()
+
 	sttProvider     stt.Provider
 	sttProviderOnce sync.Once
 )
 
+// Configure applies the loaded configuration. It must be called before RegisterRoutes.
+func Configure(cfg *config.Config) {
+	appConfig = cfg
+	SetPagination(cfg.DefaultPageSize, cfg.MaxPageSize)
+}
+
 // getSTTProvider returns the STT provider (singleton)
 func getSTTProvider() (stt.Provider, error) {
 	var err error
 	sttProviderOnce.Do(func() {
-		sttProvider, err = stt.CreateProvider()
+		sttProvider, err = stt.CreateProvider(appConfig)
 		if err != nil {
 			log.Printf("Failed to create STT provider: %v", err)
 		} else {
@@ -34,30 +54,120 @@ func getSTTProvider() (stt.Provider, error) {
 	return sttProvider, err
 }
 
+var (
+	namedSTTProviders   = make(map[string]stt.Provider)
+	namedSTTProvidersMu sync.Mutex
+)
+
+// getNamedSTTProvider returns a provider by name for ?provider= overrides, created once and reused
+func getNamedSTTProvider(name string) (stt.Provider, error) {
+	namedSTTProvidersMu.Lock()
+	defer namedSTTProvidersMu.Unlock()
+	if provider, ok := namedSTTProviders[name]; ok {
+		return provider, nil
+	}
+	provider, err := stt.CreateNamedProvider(appConfig, name)
+	if err != nil {
+		log.Printf("Failed to create STT provider %s: %v", name, err)
+		return nil, err
+	}
+	namedSTTProviders[name] = provider
+	log.Printf("STT provider override initialized: %s", provider.Name())
+	return provider, nil
+}
+
+// isSupportedProvider reports whether name is a single provider known to the factory
+func isSupportedProvider(name string) bool {
+	for _, supported := range stt.SupportedProviders() {
+		if name == supported {
+			return true
+		}
+	}
+	return false
+}
+
 func RegisterRoutes(r *gin.Engine) {
+	// Drop DB mappings and embeddings of recordings evicted from memory
+	storage.OnEvict(forgetEvictedRecording)
+
 	// Health check
 	r.GET("/health", healthCheck)
 
+	// Async STT callbacks come from the provider, authenticated by their signed URL rather than an API key
+	r.POST("/api/v1/stt/callback/:recording_id", sttCallback)
+
 	// API v1
-	v1 := r.Group("/api/v1")
+	v1 := r.Group("/api/v1", authMiddleware())
 	{
 		v1.POST("/recordings", uploadRecording)
-		v1.POST("/process/:recording_id", processRecording)
-		v1.GET("/recordings/:recording_id", getRecording)
-		v1.GET("/recordings/:recording_id/status", getRecordingStatus)
-		v1.POST("/ai/analyze/:recording_id", analyzeRecording)
-		v1.GET("/ai/analyze/:recording_id", getAnalysis)
-		v1.POST("/ai/ask", askAnything)
+		v1.POST("/recordings/base64", gzipRequestMiddleware(), uploadRecordingBase64)
+		v1.POST("/uploads", createResumableUpload)
+		v1.GET("/uploads/:id", getResumableUpload)
+		v1.PATCH("/uploads/:id", appendResumableUpload)
+		v1.POST("/uploads/:id/complete", finalizeResumableUpload)
+		v1.POST("/process/:recording_id", recordingOwnerMiddleware(), processRecording)
+
+		// Routes addressing one recording: only its owner may use them
+		recording := v1.Group("/recordings/:recording_id", recordingOwnerMiddleware())
+		recording.GET("", getRecording)
+		recording.GET("/status", getRecordingStatus)
+		recording.GET("/audio", getRecordingAudio)
+		recording.GET("/peaks", getRecordingPeaks)
+		recording.POST("/append", appendRecording)
+		recording.POST("/compare", compareProviders)
+		recording.POST("/corrections", submitCorrections)
+
+		v1.GET("/stt/providers", listSTTProviders)
+		v1.POST("/webhooks", createWebhook)
+		v1.GET("/webhooks", listWebhooks)
+		v1.DELETE("/webhooks/:id", deleteWebhook)
+		v1.GET("/glossary", listGlossary)
+		v1.POST("/glossary", createGlossaryEntry)
+		v1.PUT("/glossary/:id", uuidParamMiddleware("id"), updateGlossaryEntry)
+		v1.DELETE("/glossary/:id", uuidParamMiddleware("id"), deleteGlossaryEntry)
+
+		// Internal diagnostics, only when explicitly enabled and for admins
+		if debugEndpointsEnabled() {
+			v1.GET("/debug/storage", adminOnlyMiddleware(), getStorageStats)
+		}
+	}
+
+	// AI endpoints (rate limited per user)
+	aiGroup := v1.Group("/ai", aiRateLimitMiddleware())
+	{
+		aiGroup.POST("/analyze/batch", analyzeBatch)
+		aiGroup.POST("/analyze/:recording_id", recordingOwnerMiddleware(), analyzeRecording)
+		aiGroup.GET("/analyze/:recording_id", recordingOwnerMiddleware(), getAnalysis)
+		aiGroup.POST("/clean", cleanTranscript)
+		aiGroup.POST("/clean/batch", cleanTranscriptBatch)
+		aiGroup.POST("/ask", askAnything)
+		aiGroup.POST("/digest", digestRecordings)
+		aiGroup.GET("/context/:recording_id", recordingOwnerMiddleware(), getRecordingContext)
 	}
 
 	// STT API (new endpoints for database-backed history)
-	stt := r.Group("/api/stt")
+	stt := r.Group("/api/stt", authMiddleware())
 	{
 		stt.GET("/history", getSTTHistory)
 		stt.GET("/search", searchSTT)
-		stt.PATCH("/:id/title", updateSTTTitle)
-		stt.GET("/:id", getSTTDetail)
-		stt.DELETE("/:id", deleteSTT)
+		stt.GET("/export/all", exportAllSTT)
+		stt.GET("/stats", getSTTStats)
+
+		// Routes addressing one row: the :id UUID and its owner are checked once by the group middleware
+		byID := stt.Group("/:id", uuidParamMiddleware("id"), sttRequestOwnerMiddleware())
+		byID.PATCH("/title", updateSTTTitle)
+		byID.PATCH("/tags", updateSTTTags)
+		byID.GET("/export", exportSTT)
+		byID.GET("", getSTTDetail)
+		byID.DELETE("", deleteSTT)
+	}
+
+	// Admin endpoints (require X-Admin-Token matching ADMIN_TOKEN)
+	admin := r.Group("/api/admin", adminOnlyMiddleware())
+	{
+		admin.DELETE("/stt/:id", uuidParamMiddleware("id"), purgeSTT)
+		admin.DELETE("/users/:user_id/data", uuidParamMiddleware("user_id"), purgeUserData)
+		admin.PUT("/users/:user_id/retention", uuidParamMiddleware("user_id"), setUserRetention)
 	}
 }
 
@@ -79,7 +189,11 @@ func uploadRecording(c *gin.Context) {
 	if c.Request.MultipartForm == nil {
 		if err := c.Request.ParseMultipartForm(32 << 20); err != nil { // 32MB max
 			log.Printf("[Upload] Failed to parse multipart form: %v", err)
-			utils.Error(c, http.StatusBadRequest, "failed to parse multipart form: "+err.Error())
+			if isBodyTooLarge(err) {
+				utils.Error(c, http.StatusRequestEntityTooLarge, utils.CodePayloadTooLarge, "request body exceeds upload size limit")
+				return
+			}
+			utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "failed to parse multipart form: "+err.Error())
 			return
 		}
 	}
@@ -96,16 +210,60 @@ func uploadRecording(c *gin.Context) {
 		// Try alternative field names
 		if file, err = c.FormFile("audio"); err != nil {
 			if file, err = c.FormFile("file"); err != nil {
-				utils.Error(c, http.StatusBadRequest, "audio_file is required. Error: "+err.Error())
+				utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "audio_file is required. Error: "+err.Error())
 				return
 			}
 		}
 	}
 
-	// Validate file extension
+	if err := validateAudioUpload(file.Filename, file.Size); err != nil {
+		utils.Error(c, uploadErrorStatus(err), uploadErrorCode(err), err.Error())
+		return
+	}
+
+	// Return the original recording when a client retries with the same Idempotency-Key
+	userID := requestUserID(c)
+	idempotencyKey := strings.TrimSpace(c.GetHeader("Idempotency-Key"))
+	if _, ok := reserveIdempotencyKey(c, userID, idempotencyKey); !ok {
+		return
+	}
+	// Give the key up unless the upload completes, so the client can retry
+	defer storage.ReleaseIdempotencyKey(userID.String(), idempotencyKey)
+
+	contentHash, err := storage.HashAudioFile(file)
+	if err != nil {
+		log.Printf("[Upload] Warning: failed to hash audio file: %v", err)
+	}
+
+	// Optionally dedupe identical audio uploaded under a different key
+	if c.Query("dedupe") == "true" {
+		if existingID, ok := duplicateByContentHash(c.Request.Context(), userID, contentHash); ok {
+			respondDuplicateUpload(c, existingID, "content_hash")
+			return
+		}
+	}
+
+	recordingID, err := storage.SaveAudio(file, userID.String())
+	if err != nil {
+		log.Printf("Error saving audio: %v", err)
+		utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to save audio file")
+		return
+	}
+
+	completeUpload(c, recordingID, idempotencyKey, contentHash)
+}
+
+// maxUploadBytes is the largest accepted audio file
+const maxUploadBytes = 25 * 1024 * 1024
+
+// validateAudioUpload checks the extension and size shared by all upload endpoints
+func validateAudioUpload(filename string, size int64) error {
+	// Validate file extension; the content itself is checked with ffprobe once saved (see completeUpload)
 	// iPhone supports: M4A (default), CAF, WAV, AIFF, MP3 (via third-party apps)
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	allowedExts := []string{".m4a", ".mp3", ".wav", ".aac", ".ogg", ".caf", ".aiff", ".aif"}
+	// Android recorders often produce AMR or 3GP; providers get those transcoded to WAV
+	ext := strings.ToLower(filepath.Ext(filename))
+	allowedExts := []string{".m4a", ".mp3", ".wav", ".aac", ".ogg", ".caf", ".aiff", ".aif",
+		".amr", ".3gp", ".3gpp", ".flac", ".webm", ".opus", ".mp4", ".wma"}
 	valid := false
 	for _, allowed := range allowedExts {
 		if ext == allowed {
@@ -114,36 +272,60 @@ func uploadRecording(c *gin.Context) {
 		}
 	}
 	if !valid {
-		utils.Error(c, http.StatusBadRequest, "unsupported audio format. Supported: m4a, mp3, wav, aac, ogg, caf, aiff")
-		return
+		return errUnsupportedAudioFormat
 	}
 
 	// Validate file size (max 25MB)
-	if file.Size > 25*1024*1024 {
-		utils.Error(c, http.StatusBadRequest, "file size exceeds 25MB limit")
-		return
+	if size > maxUploadBytes {
+		return errAudioTooLarge
 	}
+	return nil
+}
 
-	recordingID, err := storage.SaveAudio(file)
-	if err != nil {
-		log.Printf("Error saving audio: %v", err)
-		utils.Error(c, http.StatusInternalServerError, "failed to save audio file")
-		return
+var (
+	errUnsupportedAudioFormat = errors.New("unsupported audio format. Supported: m4a, mp3, wav, aac, ogg, caf, aiff, amr, 3gp, flac, webm, opus, mp4, wma")
+	errAudioTooLarge          = errors.New("file size exceeds 25MB limit")
+)
+
+// uploadErrorStatus maps a validateAudioUpload error to its HTTP status: 413 for oversized audio
+func uploadErrorStatus(err error) int {
+	if errors.Is(err, errAudioTooLarge) {
+		return http.StatusRequestEntityTooLarge
 	}
+	return http.StatusBadRequest
+}
 
-	// Get user ID from header or use default
-	userIDStr := c.GetHeader("X-User-ID")
-	var userID uuid.UUID
-	if userIDStr != "" {
-		if parsedID, err := uuid.Parse(userIDStr); err == nil {
-			userID = parsedID
-		} else {
-			userID = getDefaultUserID()
+// uploadErrorCode maps a validateAudioUpload error to its error code
+func uploadErrorCode(err error) utils.ErrorCode {
+	switch {
+	case errors.Is(err, errUnsupportedAudioFormat):
+		return utils.CodeUnsupportedAudioFormat
+	case errors.Is(err, errAudioTooLarge):
+		return utils.CodeAudioTooLarge
+	default:
+		return utils.CodeInvalidRequest
+	}
+}
+
+// completeUpload verifies the content with ffprobe, registers dedupe keys, probes duration,
+// syncs to the DB and responds for a recording that has just been saved
+func completeUpload(c *gin.Context, recordingID, idempotencyKey, contentHash string) {
+	if rec, ok := storage.GetRecording(recordingID); ok {
+		if err := verifyUploadedAudio(c.Request.Context(), rec.Path); err != nil {
+			storage.DeleteRecording(recordingID)
+			removeUploadedAudio(rec.Path)
+			utils.Error(c, http.StatusBadRequest, utils.CodeInvalidAudio, err.Error())
+			return
 		}
-	} else {
-		userID = getDefaultUserID()
 	}
 
+	userID := requestUserID(c)
+	storage.SaveUploadKeys(recordingID, userID.String(), idempotencyKey, contentHash)
+	applyDeleteAudioOption(c, recordingID)
+
+	// Detect audio duration (best effort, upload must not fail on probe errors)
+	detectDuration(recordingID)
+
 	// Get STT provider name
 	providerName := "fpt" // default
 	if provider, err := getSTTProvider(); err == nil {
@@ -160,154 +342,460 @@ func uploadRecording(c *gin.Context) {
 	})
 }
 
+// respondDuplicateUpload returns the original recording for a deduplicated upload
+func respondDuplicateUpload(c *gin.Context, recordingID string, matchedBy string) {
+	status := "uploaded"
+	if rec, ok := storage.GetRecording(recordingID); ok {
+		status = rec.Status
+	}
+
+	log.Printf("[Upload] Duplicate upload detected (by %s), returning recording: %s", matchedBy, recordingID)
+	utils.Success(c, gin.H{
+		"recording_id": recordingID,
+		"status":       status,
+		"duplicate":    true,
+	})
+}
+
+// detectDuration probes the stored audio file and records its duration in seconds
+func detectDuration(recordingID string) {
+	rec, ok := storage.GetRecording(recordingID)
+	if !ok {
+		return
+	}
+
+	duration, err := audio.ProbeDuration(rec.Path)
+	if err != nil {
+		log.Printf("[Upload] Warning: failed to detect duration for %s: %v", recordingID, err)
+		return
+	}
+
+	seconds := int(math.Round(duration))
+	if !storage.UpdateDuration(recordingID, rec.AudioVersion, seconds) {
+		log.Printf("[Upload] Audio of %s changed while probing, dropping duration %.2fs", recordingID, duration)
+		return
+	}
+	log.Printf("[Upload] Detected duration for %s: %.2fs", recordingID, duration)
+}
+
+// sttBusyRetryAfter is the Retry-After sent when every STT slot stayed busy (see STT_MAX_CONCURRENT)
+const sttBusyRetryAfter = 10 * time.Second
+
 // processRecording processes audio file through STT
 func processRecording(c *gin.Context) {
 	id := c.Param("recording_id")
 	if id == "" {
-		utils.Error(c, http.StatusBadRequest, "recording_id is required")
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "recording_id is required")
+		return
+	}
+
+	// Optional output language for the cleaned transcript and STT options
+	var processReq ProcessRequest
+	if err := bindOptionalJSON(c, &processReq); err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if processReq.SpeakerCount < 0 {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "speaker_count must not be negative")
+		return
+	}
+	outputLanguage, err := ai.NormalizeOutputLanguage(processReq.OutputLanguage)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Optional one-off provider override (?provider=google), e.g. to retry a bad transcript without re-uploading
+	providerOverride := strings.ToLower(strings.TrimSpace(c.Query("provider")))
+	if providerOverride != "" && !isSupportedProvider(providerOverride) {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, fmt.Sprintf("unsupported provider: %s. Supported: %s",
+			providerOverride, strings.Join(stt.SupportedProviders(), ", ")))
 		return
 	}
 
 	rec, ok := storage.GetRecording(id)
 	if !ok {
-		utils.Error(c, http.StatusNotFound, "recording not found")
+		utils.Error(c, http.StatusNotFound, utils.CodeRecordingNotFound, "recording not found")
 		return
 	}
 
-	// Check if already processing or processed
-	if rec.Status == "processing" {
-		utils.Error(c, http.StatusConflict, "recording is already being processed")
+	// Check if already processing or processed (an async job that never called back may be retried)
+	if rec.Status == "processing" && !asyncJobExpired(id) {
+		utils.Error(c, http.StatusConflict, utils.CodeAlreadyProcessing, "recording is already being processed")
 		return
 	}
 
-	if rec.Status == "processed" {
+	if rec.Status == "processed" && providerOverride == "" {
 		// Return existing transcript if available
 		if rec.Transcript != "" {
 			utils.Success(c, gin.H{
-				"recording_id": id,
-				"status":       "processed",
-				"language":     "vi",
-				"transcript":   rec.Transcript,
-				"confidence":   rec.Confidence,
+				"recording_id":         id,
+				"status":               "processed",
+				"language":             transcriptLanguage(rec.Language),
+				"transcript":           rec.Transcript,
+				"confidence":           rec.Confidence,
+				"confidence_available": stt.HasConfidence(rec.Confidence),
 			})
 			return
 		}
 	}
 
-	storage.UpdateStatus(id, "processing")
+	// Transcript-only recordings cannot be transcribed again
+	if rec.AudioDeleted {
+		utils.Error(c, http.StatusGone, utils.CodeAudioDeleted, errAudioDeleted.Error())
+		return
+	}
+
+	// A concurrent process or append request may have claimed the recording since it was read
+	if !storage.TryBeginProcessing(id, rec.Status) {
+		utils.Error(c, http.StatusConflict, utils.CodeAlreadyProcessing, "recording is already being processed")
+		return
+	}
 	log.Printf("Processing recording: %s", id)
 
+	// Reject empty/silent audio before spending provider quota
+	if err := checkAudioContent(rec.Path); err != nil {
+		log.Printf("Audio check failed for recording %s: %v", id, err)
+		storage.UpdateStatus(id, "failed")
+		storage.UpdateError(id, err.Error())
+		utils.Error(c, http.StatusBadRequest, audioCheckErrorCode(err), err.Error())
+		return
+	}
+
 	// Get STT provider
 	provider, err := getSTTProvider()
+	if providerOverride != "" {
+		provider, err = getNamedSTTProvider(providerOverride)
+	}
 	if err != nil {
 		log.Printf("STT provider error for recording %s: %v", id, err)
 		storage.UpdateStatus(id, "failed")
 		storage.UpdateError(id, "STT provider not available: "+err.Error())
-		utils.Error(c, http.StatusInternalServerError, "STT provider not available: "+err.Error())
+		utils.Error(c, http.StatusInternalServerError, utils.CodeSTTProviderUnavailable, "STT provider not available: "+err.Error())
+		return
+	}
+
+	sttCtx := stt.WithOptions(c.Request.Context(), stt.Options{
+		Diarization:     processReq.Diarization,
+		SpeakerCount:    processReq.SpeakerCount,
+		FilterProfanity: processReq.FilterProfanity,
+	})
+	opts := transcriptionOptions{
+		OutputLanguage:   outputLanguage,
+		FilterProfanity:  processReq.FilterProfanity,
+		UseFastClean:     processReq.UseFastClean,
+		ProviderOverride: providerOverride,
+		UserID:           requestUserID(c),
+	}
+
+	// Providers listed in STT_ASYNC_PROVIDERS transcribe in the background and call back when done
+	if asyncProvider, ok := stt.AsyncMode(provider); ok {
+		submitAsyncTranscription(c, sttCtx, rec, asyncProvider, opts)
 		return
 	}
 
 	// Transcribe audio
-	result, err := provider.Transcribe(rec.Path)
+	sttStart := time.Now()
+	result, err := provider.Transcribe(sttCtx, rec.Path)
+	if errors.Is(err, stt.ErrBusy) {
+		// Not the recording's fault: restore its status so the client can simply retry
+		log.Printf("STT queue full for recording %s (provider: %s)", id, provider.Name())
+		storage.UpdateStatus(id, rec.Status)
+		c.Header("Retry-After", strconv.Itoa(int(sttBusyRetryAfter.Seconds())))
+		utils.Error(c, http.StatusTooManyRequests, utils.CodeRateLimited, err.Error())
+		return
+	}
 	if err != nil {
 		log.Printf("STT error for recording %s (provider: %s): %v", id, provider.Name(), err)
 		storage.UpdateStatus(id, "failed")
 		storage.UpdateError(id, err.Error())
-		utils.Error(c, http.StatusBadRequest, err.Error())
+		utils.Error(c, http.StatusBadRequest, utils.CodeSTTFailed, err.Error())
+		return
+	}
+
+	sttDuration := result.Duration
+	if sttDuration == 0 {
+		sttDuration = time.Since(sttStart)
+	}
+	response, err := finishTranscription(c.Request.Context(), rec, provider.Name(), result, sttDuration, opts)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeNoSpeechDetected, err.Error())
 		return
 	}
+	utils.Success(c, response)
+}
+
+// errEmptyTranscript is returned by finishTranscription when STT found no speech
+var errEmptyTranscript = errors.New("no speech detected in audio")
 
+// transcriptionOptions are the /process settings applied after STT. Async jobs keep them
+// until the provider calls back (see storage.STTJob).
+type transcriptionOptions struct {
+	OutputLanguage   string
+	FilterProfanity  bool
+	UseFastClean     bool
+	ProviderOverride string
+	UserID           uuid.UUID
+}
+
+// finishTranscription stores an STT result on a recording: cleans the transcript with AI, updates
+// the recording, syncs it to the database and publishes transcription.completed. sttName is the
+// provider that was asked (the result may name the winner of a best-of run). It returns the /process
+// response, or errEmptyTranscript after marking the recording failed.
+func finishTranscription(ctx context.Context, rec *storage.Recording, sttName string, result *stt.Result,
+	sttDuration time.Duration, opts transcriptionOptions) (gin.H, error) {
+	id := rec.ID
 	text := result.Transcript
 	conf := result.Confidence
-	log.Printf("STT transcription successful (provider: %s): confidence=%.2f, length=%d",
-		provider.Name(), conf, len(text))
+	confAvailable := stt.HasConfidence(conf)
+	log.Printf("STT transcription successful (provider: %s): confidence=%.2f (raw %.2f), length=%d, duration=%v",
+		sttName, conf, result.RawConfidence, len(text), sttDuration)
+	if !confAvailable {
+		log.Printf("Warning: STT provider %s returned no confidence score for recording %s", sttName, id)
+	}
+
+	// Provider-reported language, else STT_LANGUAGE, else detected from the text (vi-VN when uncertain)
+	language := stt.ResolveLanguage(result)
+	storage.UpdateLanguage(id, language)
+	// Always replace segments so a re-run without diarization drops stale ones
+	storage.UpdateSegments(id, result.Segments)
+	storage.UpdateSentences(id, stt.GroupSentences(result.Words))
 
 	// Validate transcript is not empty
 	if text == "" {
 		log.Printf("Empty transcript for recording %s", id)
 		storage.UpdateStatus(id, "failed")
 		storage.UpdateError(id, "empty transcript")
-		utils.Error(c, http.StatusBadRequest, "no speech detected in audio")
-		return
+		return nil, errEmptyTranscript
 	}
 
-	// Clean transcript with AI (minimize/optimize)
-	log.Printf("Cleaning transcript with AI for recording: %s", id)
-	cleanedText, err := ai.CleanTranscriptWithAI(text)
-	if err != nil {
-		log.Printf("Warning: Failed to clean transcript with AI: %v. Using original transcript.", err)
-		// Continue with original transcript if cleaning fails
-		cleanedText = text
+	// Flag unreliable transcripts so the app can ask the user to re-record
+	lowConfidence := isLowConfidence(conf)
+	storage.UpdateLowConfidence(id, lowConfidence)
+	if lowConfidence {
+		log.Printf("Warning: Low STT confidence for recording %s: %.2f < MIN_CONFIDENCE %.2f", id, conf, minConfidence())
+	}
+
+	cleanedText := text
+	decodedWords := []string{}
+	var cleaningDuration time.Duration
+	cleaningSkipReason := ""
+	if lowConfidence && skipAIOnLowConfidence() {
+		log.Printf("Skipping AI cleaning for low-confidence recording: %s", id)
+		cleaningSkipReason = storage.CleaningSkippedLowConfidence
+	} else if isHighConfidence(conf) {
+		log.Printf("Skipping AI cleaning for recording %s: confidence %.2f >= CLEAN_SKIP_CONFIDENCE %.2f", id, conf, cleanSkipConfidence())
+		cleaningSkipReason = storage.CleaningSkippedHighConfidence
+	} else if !ai.Available() {
+		log.Printf("Skipping AI cleaning for recording %s: AI is not configured", id)
+		cleaningSkipReason = storage.CleaningSkippedAINotConfigured
 	} else {
-		log.Printf("Transcript cleaned successfully. Original: %d chars, Cleaned: %d chars", len(text), len(cleanedText))
+		// Clean transcript with AI (minimize/optimize)
+		log.Printf("Cleaning transcript with AI for recording: %s", id)
+		cleanStart := time.Now()
+		cleanCtx := withPromptVariant(ctx, id)
+		cleaned, err := ai.CleanTranscriptDetailed(cleanCtx, text, opts.OutputLanguage,
+			ai.CleanOptions{FilterProfanity: opts.FilterProfanity, FastClean: opts.UseFastClean, Glossary: userGlossary(ctx, opts.UserID)})
+		cleaningDuration = time.Since(cleanStart)
+		if err != nil {
+			log.Printf("Warning: Failed to clean transcript with AI: %v. Using original transcript.", err)
+			// Continue with original transcript if cleaning fails
+			cleanedText = text
+		} else {
+			cleanedText = cleaned.CleanedText
+			if len(cleaned.DecodedWords) > 0 {
+				decodedWords = cleaned.DecodedWords
+			}
+			log.Printf("Transcript cleaned successfully. Original: %d chars, Cleaned: %d chars", len(text), len(cleanedText))
+		}
+	}
+
+	// Keep the STT text (before AI redaction) when filtering, so it stays available in metadata.
+	// A provider that masked profanity itself never returned the raw text, so there is none to keep.
+	if opts.FilterProfanity && !result.ProfanityMasked {
+		storage.UpdateRawTranscript(id, text)
+	}
+
+	// In best-of mode the result names the provider that actually won
+	providerName := sttName
+	if result.Provider != "" {
+		providerName = result.Provider
+	}
+
+	// A re-run with another provider replaces the transcript; keep the first one for comparison
+	if opts.ProviderOverride != "" {
+		storage.KeepOriginalTranscript(id, appConfig.STTProvider)
 	}
 
-	// Update transcript with cleaned version
+	// Update transcript with cleaned version and the corrections the AI made
 	storage.UpdateTranscript(id, cleanedText, conf)
+	storage.UpdateRawConfidence(id, result.RawConfidence)
+	storage.UpdateProvider(id, providerName)
+	storage.UpdateDecodedWords(id, decodedWords)
+	storage.UpdateProcessingTime(id, int(sttDuration.Milliseconds()), int(cleaningDuration.Milliseconds()))
+	storage.UpdateCleaningSkipReason(id, cleaningSkipReason)
 	storage.UpdateStatus(id, "processed")
 	log.Printf("Recording processed successfully: %s (confidence: %.2f, original length: %d, cleaned length: %d)",
 		id, conf, len(text), len(cleanedText))
 
-	// Get user ID from header or use default
-	userIDStr := c.GetHeader("X-User-ID")
-	var userID uuid.UUID
-	if userIDStr != "" {
-		if parsedID, err := uuid.Parse(userIDStr); err == nil {
-			userID = parsedID
-		} else {
-			userID = getDefaultUserID()
-		}
-	} else {
-		userID = getDefaultUserID()
-	}
-
 	// Sync to database (update transcript and confidence)
-	syncToDatabase(id, userID, provider.Name())
+	syncToDatabase(id, opts.UserID, providerName)
 
-	utils.Success(c, gin.H{
-		"recording_id": id,
-		"status":       "processed",
-		"language":     "vi",
-		"transcript":   cleanedText,
-		"confidence":   conf,
+	// Transcript-only mode: the audio is no longer needed once the transcript is stored
+	discardProcessedAudio(rec)
+
+	events.Publish(events.TypeTranscriptionCompleted, rec.UserID, id, map[string]interface{}{
+		"transcript":           cleanedText,
+		"language":             transcriptLanguage(language),
+		"confidence":           conf,
+		"confidence_available": confAvailable,
+		"low_confidence":       lowConfidence,
+		"provider":             providerName,
 	})
+
+	response := gin.H{
+		"recording_id":         id,
+		"status":               "processed",
+		"language":             transcriptLanguage(language),
+		"transcript":           cleanedText,
+		"decoded_words":        decodedWords,
+		"cleaning_skipped":     cleaningSkipReason != "",
+		"confidence":           conf,
+		"confidence_available": confAvailable,
+		"low_confidence":       lowConfidence,
+		"provider":             providerName,
+		"processing_time_ms":   sttDuration.Milliseconds(),
+	}
+	if updated, ok := storage.GetRecording(id); ok && updated.OriginalTranscript != nil {
+		response["original_transcript"] = updated.OriginalTranscript
+	}
+	addSegments(response, result.Segments)
+	return response, nil
+}
+
+// segmentsFromMetadata decodes diarization segments stored in DB metadata
+func segmentsFromMetadata(metadata map[string]interface{}) []stt.Segment {
+	raw, ok := metadata["segments"]
+	if !ok || raw == nil {
+		return nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var segments []stt.Segment
+	if err := json.Unmarshal(data, &segments); err != nil {
+		log.Printf("Warning: invalid segments in metadata: %v", err)
+		return nil
+	}
+	return segments
+}
+
+// addSegments adds diarization segments and the speaker-labeled transcript to a response
+func addSegments(response gin.H, segments []stt.Segment) {
+	if len(segments) == 0 {
+		return
+	}
+	response["segments"] = segments
+	response["labeled_transcript"] = stt.LabeledTranscript(segments)
+}
+
+// sentencesFromMetadata decodes timed sentences stored in DB metadata
+func sentencesFromMetadata(metadata map[string]interface{}) []stt.Sentence {
+	raw, ok := metadata["sentences"]
+	if !ok || raw == nil {
+		return nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var sentences []stt.Sentence
+	if err := json.Unmarshal(data, &sentences); err != nil {
+		log.Printf("Warning: invalid sentences in metadata: %v", err)
+		return nil
+	}
+	return sentences
+}
+
+// addSentences adds timed sentences for ?segments=true. Transcripts without word timings
+// (e.g. FPT) come back as a single sentence spanning the audio.
+func addSentences(c *gin.Context, response gin.H, sentences []stt.Sentence, transcript string, durationSeconds float64) {
+	if c.Query("segments") != "true" {
+		return
+	}
+	if len(sentences) == 0 {
+		sentences = stt.WholeTranscript(transcript, durationSeconds)
+	}
+	if sentences == nil {
+		sentences = []stt.Sentence{}
+	}
+	response["sentences"] = sentences
 }
 
 // getRecording returns recording information
 func getRecording(c *gin.Context) {
 	id := c.Param("recording_id")
 	if id == "" {
-		utils.Error(c, http.StatusBadRequest, "recording_id is required")
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "recording_id is required")
 		return
 	}
 
 	rec, ok := storage.GetRecording(id)
 	if !ok {
-		utils.Error(c, http.StatusNotFound, "recording not found")
+		utils.Error(c, http.StatusNotFound, utils.CodeRecordingNotFound, "recording not found")
 		return
 	}
 
-	utils.Success(c, gin.H{
-		"recording_id": rec.ID,
-		"status":       rec.Status,
-		"created_at":   rec.CreatedAt,
-		"duration":     rec.Duration,
-		"transcript":   rec.Transcript,
-		"confidence":   rec.Confidence,
-	})
+	response := gin.H{
+		"recording_id":       rec.ID,
+		"status":             rec.Status,
+		"created_at":         rec.CreatedAt,
+		"duration":           rec.Duration, // seconds, kept for existing clients
+		"audio_duration_ms":  rec.Duration * 1000,
+		"transcript":         rec.Transcript,
+		"confidence":         rec.Confidence,
+		"low_confidence":     rec.LowConfidence,
+		"processing_time_ms": rec.ProcessingTime,
+		"language":           transcriptLanguage(rec.Language),
+		"audio_size_bytes":   rec.Size,
+	}
+	if rec.Transcript != "" {
+		response["confidence_available"] = stt.HasConfidence(rec.Confidence)
+	}
+	if len(rec.DecodedWords) > 0 {
+		response["decoded_words"] = rec.DecodedWords
+	}
+	if len(rec.Corrections) > 0 {
+		response["user_corrections"] = rec.Corrections
+	}
+	if rec.AudioDeleted {
+		response["audio_deleted"] = true
+	}
+	addSegments(response, rec.Segments)
+	addSentences(c, response, rec.Sentences, rec.Transcript, float64(rec.Duration))
+	utils.Success(c, response)
+}
+
+// transcriptLanguage returns the short language code ("vi", "en") used in API responses
+func transcriptLanguage(language string) string {
+	if language == stt.LanguageEnglish {
+		return "en"
+	}
+	return "vi"
 }
 
 // getRecordingStatus returns only the status of a recording
 func getRecordingStatus(c *gin.Context) {
 	id := c.Param("recording_id")
 	if id == "" {
-		utils.Error(c, http.StatusBadRequest, "recording_id is required")
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "recording_id is required")
 		return
 	}
 
 	rec, ok := storage.GetRecording(id)
 	if !ok {
-		utils.Error(c, http.StatusNotFound, "recording not found")
+		utils.Error(c, http.StatusNotFound, utils.CodeRecordingNotFound, "recording not found")
 		return
 	}
 
@@ -317,41 +805,157 @@ func getRecordingStatus(c *gin.Context) {
 	})
 }
 
+// ProcessRequest represents the optional process request body
+type ProcessRequest struct {
+	OutputLanguage  string `json:"output_language"`  // vi (default) or en
+	Diarization     bool   `json:"diarization"`      // label speakers (Google STT only, costs more)
+	SpeakerCount    int    `json:"speaker_count"`    // expected speakers for diarization, 0 = auto
+	FilterProfanity bool   `json:"filter_profanity"` // redact profanity in the cleaned transcript (default off)
+	UseFastClean    bool   `json:"use_fast_clean"`   // strip greetings/mic tests/fillers before AI cleaning (saves tokens)
+}
+
+// AnalyzeRequest represents the optional analyze request body
+type AnalyzeRequest struct {
+	OutputLanguage string   `json:"output_language"` // vi (default) or en
+	Temperature    *float64 `json:"temperature"`     // optional, 0-1.5 (default 0.3)
+	MaxTokens      int      `json:"max_tokens"`      // optional, 16-4096
+	SystemPrompt   string   `json:"system_prompt"`   // optional, replaces the default analysis system prompt
+	UseFastClean   bool     `json:"use_fast_clean"`  // strip greetings/mic tests/fillers before analysis (saves tokens)
+}
+
+// generationContext validates optional temperature/max_tokens and attaches them to ctx
+func generationContext(ctx context.Context, temperature *float64, maxTokens int) (context.Context, error) {
+	if temperature == nil && maxTokens == 0 {
+		return ctx, nil
+	}
+	params, err := ai.NewGenerationParams(temperature, maxTokens)
+	if err != nil {
+		return nil, err
+	}
+	return ai.WithGenerationParams(ctx, params), nil
+}
+
+// bindOptionalJSON binds a JSON body if one was sent; an empty body is not an error
+func bindOptionalJSON(c *gin.Context, obj interface{}) error {
+	if c.Request.ContentLength == 0 {
+		return nil
+	}
+	return c.ShouldBindJSON(obj)
+}
+
 // analyzeRecording analyzes transcript using AI
 func analyzeRecording(c *gin.Context) {
 	id := c.Param("recording_id")
 	if id == "" {
-		utils.Error(c, http.StatusBadRequest, "recording_id is required")
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "recording_id is required")
+		return
+	}
+
+	var req AnalyzeRequest
+	if err := bindOptionalJSON(c, &req); err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	outputLanguage, err := ai.NormalizeOutputLanguage(req.OutputLanguage)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	ctx, err := generationContext(c.Request.Context(), req.Temperature, req.MaxTokens)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
 		return
 	}
 
+	// A custom system prompt changes the analysis style; the JSON output schema is still enforced
+	if req.SystemPrompt != "" {
+		systemPrompt, err := ai.NewSystemPrompt(req.SystemPrompt)
+		if err != nil {
+			utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+			return
+		}
+		ctx = ai.WithSystemPrompt(ctx, systemPrompt)
+	}
+	if req.UseFastClean {
+		ctx = ai.WithFastClean(ctx)
+	}
+
+	// Return the prompts that would be sent instead of calling OpenAI
+	if c.Query("dry_run") == "true" {
+		previewAnalysis(c, ctx, id, outputLanguage)
+		return
+	}
+
+	result, err := performAnalysis(ctx, id, outputLanguage, false)
+	if err != nil {
+		switch {
+		case errors.Is(err, errRecordingNotFound):
+			utils.Error(c, http.StatusNotFound, utils.CodeRecordingNotFound, err.Error())
+		case errors.Is(err, errTranscriptNotAvailable):
+			utils.Error(c, http.StatusBadRequest, utils.CodeTranscriptNotAvailable, err.Error())
+		case errors.Is(err, errLowConfidenceTranscript):
+			utils.Error(c, http.StatusBadRequest, utils.CodeLowConfidence, err.Error())
+		case errors.Is(err, ai.ErrOpenAITimeout):
+			utils.Error(c, http.StatusGatewayTimeout, utils.CodeAITimeout, "AI analysis timed out: "+err.Error())
+		case errors.Is(err, ai.ErrAINotConfigured):
+			utils.Error(c, http.StatusServiceUnavailable, utils.CodeAINotConfigured, err.Error())
+		default:
+			utils.Error(c, http.StatusInternalServerError, utils.CodeAIFailed, "AI analysis failed: "+err.Error())
+		}
+		return
+	}
+
+	// Return result
+	response := gin.H{
+		"recording_id": id,
+		"context":      result.Context,
+		"title":        result.Title,
+		"summary":      result.Summary,
+		"action_items": result.ActionItems,
+		"key_points":   result.KeyPoints,
+		"zalo_brief":   result.ZaloBrief,
+		"questions":    result.Questions,
+		"entities":     result.Entities,
+	}
+	addAnalysisVersion(response, id, result)
+	utils.Success(c, response)
+}
+
+var (
+	errRecordingNotFound       = errors.New("recording not found")
+	errTranscriptNotAvailable  = errors.New("transcript not available. Please process recording first")
+	errLowConfidenceTranscript = errors.New("transcript confidence is too low for analysis. Please re-record")
+)
+
+// performAnalysis analyzes a recording's transcript, returning the stored analysis
+// when one already exists in the requested language and prompt template (unless force is set)
+func performAnalysis(ctx context.Context, id string, outputLanguage string, force bool) (*ai.AnalysisResult, error) {
+	ctx = withPromptVariant(ctx, id)
+
+	// Check if analysis already exists in the requested language (memory, then database).
+	// An analysis of an older transcript is redone, so analyzing is idempotent per transcript.
+	existing, hasExisting := getStoredAnalysis(id)
+	if !force && hasExisting && analysisLanguage(existing) == outputLanguage &&
+		analysisPromptTemplate(existing) == ai.PromptTemplateFromContext(ctx) && !analysisStale(id, existing) {
+		log.Printf("Returning existing analysis for recording: %s", id)
+		return existing, nil
+	}
+
 	// Get recording
 	rec, ok := storage.GetRecording(id)
 	if !ok {
-		utils.Error(c, http.StatusNotFound, "recording not found")
-		return
+		return nil, errRecordingNotFound
 	}
 
 	// Check if transcript exists
 	if rec.Transcript == "" {
-		utils.Error(c, http.StatusBadRequest, "transcript not available. Please process recording first")
-		return
+		return nil, errTranscriptNotAvailable
 	}
 
-	// Check if analysis already exists
-	if existing, ok := storage.GetAnalysis(id); ok {
-		log.Printf("Returning existing analysis for recording: %s", id)
-		utils.Success(c, gin.H{
-			"recording_id": id,
-			"context":      existing.Context,
-			"title":        existing.Title,
-			"summary":      existing.Summary,
-			"action_items": existing.ActionItems,
-			"key_points":   existing.KeyPoints,
-			"zalo_brief":   existing.ZaloBrief,
-			"questions":    existing.Questions,
-		})
-		return
+	if rec.LowConfidence && skipAIOnLowConfidence() && !force {
+		return nil, errLowConfidenceTranscript
 	}
 
 	log.Printf("Analyzing recording: %s", id)
@@ -361,48 +965,150 @@ func analyzeRecording(c *gin.Context) {
 	log.Printf("Detected context: %s", detectedContext)
 
 	// Analyze transcript
-	result, err := ai.AnalyzeTranscript(rec.Transcript, detectedContext)
+	result, err := ai.AnalyzeTranscript(ctx, rec.Transcript, detectedContext, outputLanguage)
 	if err != nil {
 		log.Printf("AI analysis error for recording %s: %v", id, err)
-		utils.Error(c, http.StatusInternalServerError, "AI analysis failed: "+err.Error())
-		return
+		return nil, err
+	}
+
+	result.TranscriptHash = storage.HashTranscript(rec.Transcript)
+	result.Version = 1
+	if hasExisting {
+		result.Version = analysisVersion(existing) + 1
 	}
 
 	// Save analysis
 	storage.SaveAnalysis(id, result)
 	log.Printf("Analysis saved for recording: %s", id)
 
+	// Index for Ask Anything retrieval in the background (best effort, outlives the request)
+	go func(transcript string) {
+		if err := ai.IndexAnalysis(context.Background(), id, result, transcript); err != nil {
+			log.Printf("[Retrieval] Failed to index recording %s: %v", id, err)
+		}
+	}(rec.Transcript)
+
 	// Sync analysis to database
 	syncAnalysisToDatabase(id, result)
 
-	// Return result
-	utils.Success(c, gin.H{
-		"recording_id": id,
+	events.Publish(events.TypeAnalysisCompleted, rec.UserID, id, map[string]interface{}{
 		"context":      result.Context,
 		"title":        result.Title,
+		"language":     analysisLanguage(result),
 		"summary":      result.Summary,
 		"action_items": result.ActionItems,
-		"key_points":   result.KeyPoints,
-		"zalo_brief":   result.ZaloBrief,
-		"questions":    result.Questions,
 	})
+
+	return result, nil
 }
 
-// getAnalysis retrieves analysis result for a recording
+// withPromptVariant assigns a recording to its A/B prompt variant when an experiment is running,
+// records the variant on the recording and returns ctx carrying it
+func withPromptVariant(ctx context.Context, recordingID string) context.Context {
+	variant := ai.AssignPromptVariant(recordingID)
+	if variant == "" {
+		return ctx
+	}
+	storage.UpdatePromptVariant(recordingID, ai.PromptExperimentName(), variant)
+	return ai.WithPromptVariant(ctx, variant)
+}
+
+// analysisLanguage returns the output language of a stored analysis (vi if unset)
+func analysisLanguage(result *ai.AnalysisResult) string {
+	if result.Language == "" {
+		return ai.LanguageVietnamese
+	}
+	return result.Language
+}
+
+// analysisPromptTemplate returns the prompt template of a stored analysis (default if unset)
+func analysisPromptTemplate(result *ai.AnalysisResult) string {
+	if result.PromptTemplate == "" {
+		return ai.PromptTemplateDefault
+	}
+	return result.PromptTemplate
+}
+
+// analysisVersion returns the version of a stored analysis; analyses saved before versioning count as 1
+func analysisVersion(result *ai.AnalysisResult) int {
+	if result.Version == 0 {
+		return 1
+	}
+	return result.Version
+}
+
+// analysisStale reports whether an analysis was derived from a different transcript than the
+// recording's current one. Analyses saved before transcript hashes were recorded, and recordings
+// whose transcript cannot be found, are never reported stale.
+func analysisStale(id string, result *ai.AnalysisResult) bool {
+	if result.TranscriptHash == "" {
+		return false
+	}
+	transcript, ok := currentTranscript(id)
+	if !ok {
+		return false
+	}
+	return storage.HashTranscript(transcript) != result.TranscriptHash
+}
+
+// currentTranscript returns the recording's transcript from memory, falling back to the database row
+func currentTranscript(id string) (string, bool) {
+	if rec, ok := storage.GetRecording(id); ok {
+		return rec.Transcript, true
+	}
+	if sttRepo == nil {
+		return "", false
+	}
+	req, err := sttRepo.GetByRecordingID(context.Background(), id)
+	if err != nil || req.Transcript == nil {
+		return "", false
+	}
+	return *req.Transcript, true
+}
+
+// addAnalysisVersion adds analysis_version, transcript_hash and stale to an analysis response
+func addAnalysisVersion(response gin.H, id string, result *ai.AnalysisResult) {
+	response["analysis_version"] = analysisVersion(result)
+	response["stale"] = analysisStale(id, result)
+	if result.TranscriptHash != "" {
+		response["transcript_hash"] = result.TranscriptHash
+	}
+}
+
+// getStoredAnalysis returns the in-memory analysis, falling back to the copy persisted in the database
+func getStoredAnalysis(id string) (*ai.AnalysisResult, bool) {
+	if result, ok := storage.GetAnalysis(id); ok {
+		return result, true
+	}
+	return loadAnalysisFromDatabase(id)
+}
+
+// getAnalysis retrieves analysis result for a recording.
+// ?format=v1 returns the structured V1 schema instead (see getAnalysisV1).
 func getAnalysis(c *gin.Context) {
 	id := c.Param("recording_id")
 	if id == "" {
-		utils.Error(c, http.StatusBadRequest, "recording_id is required")
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "recording_id is required")
 		return
 	}
 
-	result, ok := storage.GetAnalysis(id)
+	switch format := c.DefaultQuery("format", analysisFormatLegacy); format {
+	case analysisFormatLegacy:
+	case analysisFormatV1:
+		getAnalysisV1(c, id)
+		return
+	default:
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "unsupported format: "+format+" (use legacy or v1)")
+		return
+	}
+
+	result, ok := getStoredAnalysis(id)
 	if !ok {
-		utils.Error(c, http.StatusNotFound, "analysis not found. Please analyze recording first")
+		utils.Error(c, http.StatusNotFound, utils.CodeAnalysisNotFound, "analysis not found. Please analyze recording first")
 		return
 	}
 
-	utils.Success(c, gin.H{
+	response := gin.H{
 		"recording_id": id,
 		"context":      result.Context,
 		"title":        result.Title,
@@ -411,46 +1117,62 @@ func getAnalysis(c *gin.Context) {
 		"key_points":   result.KeyPoints,
 		"zalo_brief":   result.ZaloBrief,
 		"questions":    result.Questions,
-	})
-}
-
-// AskRequest represents the ask anything request
-type AskRequest struct {
-	Question string `json:"question" binding:"required"`
+		"entities":     result.Entities,
+	}
+	addAnalysisVersion(response, id, result)
+	utils.Success(c, response)
 }
 
-// askAnything answers questions based on all analyzed data
-func askAnything(c *gin.Context) {
-	var req AskRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.Error(c, http.StatusBadRequest, "question is required")
+// getRecordingContext returns a provisional context label and scores using only the rule-based detector
+func getRecordingContext(c *gin.Context) {
+	id := c.Param("recording_id")
+	if id == "" {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "recording_id is required")
 		return
 	}
 
-	if req.Question == "" {
-		utils.Error(c, http.StatusBadRequest, "question cannot be empty")
+	rec, ok := storage.GetRecording(id)
+	if !ok {
+		utils.Error(c, http.StatusNotFound, utils.CodeRecordingNotFound, "recording not found")
 		return
 	}
 
-	log.Printf("Ask Anything request: %s", req.Question)
-
-	// Get all analyses
-	allAnalyses := storage.GetAllAnalyses()
-	if len(allAnalyses) == 0 {
-		utils.Error(c, http.StatusBadRequest, "no analysis data available. Please analyze some recordings first")
+	if rec.Transcript == "" {
+		utils.Error(c, http.StatusBadRequest, utils.CodeTranscriptNotAvailable, "transcript not available. Please process recording first")
 		return
 	}
 
-	log.Printf("Found %d analyses to use as context", len(allAnalyses))
+	detection := ai.DetectContextWithCounts(rec.Transcript)
 
-	// Build analysis contexts with recording info
-	analysisContexts := make([]ai.AnalysisContext, 0, len(allAnalyses))
-	for recordingID, analysis := range allAnalyses {
+	utils.Success(c, gin.H{
+		"recording_id":  id,
+		"context":       detection.Context,
+		"meeting_count": detection.MeetingCount,
+		"lecture_count": detection.LectureCount,
+		"meeting_score": detection.MeetingScore,
+		"lecture_score": detection.LectureScore,
+	})
+}
+
+// AskRequest represents the ask anything request
+type AskRequest struct {
+	Question     string   `json:"question" binding:"required"`
+	RecordingIDs []string `json:"recording_ids,omitempty"` // only use these recordings as context
+	From         string   `json:"from,omitempty"`          // RFC3339 or YYYY-MM-DD (inclusive)
+	To           string   `json:"to,omitempty"`            // RFC3339 or YYYY-MM-DD (inclusive)
+	Temperature  *float64 `json:"temperature,omitempty"`   // optional, 0-1.5 (default 0.3)
+	MaxTokens    int      `json:"max_tokens,omitempty"`    // optional, 16-4096 (default 500)
+}
+
+// buildAnalysisContexts pairs stored analyses with their recording's date and transcript
+func buildAnalysisContexts(analyses map[string]*ai.AnalysisResult) []ai.AnalysisContext {
+	contexts := make([]ai.AnalysisContext, 0, len(analyses))
+	for recordingID, analysis := range analyses {
 		// Get recording info for context
 		rec, ok := storage.GetRecording(recordingID)
 		if !ok {
 			// Skip if recording not found, but still use analysis
-			analysisContexts = append(analysisContexts, ai.AnalysisContext{
+			contexts = append(contexts, ai.AnalysisContext{
 				RecordingID: recordingID,
 				Context:     analysis.Context,
 				Summary:     analysis.Summary,
@@ -460,7 +1182,7 @@ func askAnything(c *gin.Context) {
 			continue
 		}
 
-		analysisContexts = append(analysisContexts, ai.AnalysisContext{
+		contexts = append(contexts, ai.AnalysisContext{
 			RecordingID: recordingID,
 			CreatedAt:   rec.CreatedAt,
 			Context:     analysis.Context,
@@ -470,19 +1192,94 @@ func askAnything(c *gin.Context) {
 			Transcript:  rec.Transcript,
 		})
 	}
+	return contexts
+}
+
+// askAnything answers questions based on the analyzed recordings of the calling user
+func askAnything(c *gin.Context) {
+	var req AskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "question is required")
+		return
+	}
+
+	if req.Question == "" {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "question cannot be empty")
+		return
+	}
+
+	scope, err := parseAskScope(req)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	askCtx, err := generationContext(c.Request.Context(), req.Temperature, req.MaxTokens)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	log.Printf("Ask Anything request: %s", req.Question)
+
+	// Read the version before the data so a concurrent save can only make the key stale, never wrong
+	analysesVersion := storage.AnalysesVersion()
+
+	// Get the analyses of the caller's recordings
+	allAnalyses := storage.GetAnalysesByUser(requestUserID(c).String())
+	if len(allAnalyses) == 0 {
+		utils.Error(c, http.StatusBadRequest, utils.CodeNoAnalysisData, "no analysis data available. Please analyze some recordings first")
+		return
+	}
+
+	log.Printf("Found %d analyses to use as context", len(allAnalyses))
+
+	// Build analysis contexts with recording info
+	analysisContexts := buildAnalysisContexts(allAnalyses)
+
+	// Restrict context to the requested recordings/date range (or the most recent N)
+	analysisContexts, truncatedFrom := scope.apply(analysisContexts)
+	if len(analysisContexts) == 0 {
+		utils.Error(c, http.StatusBadRequest, utils.CodeNoAnalysisData, "no analysis data matches the given filters")
+		return
+	}
+	scopedCount := len(analysisContexts)
+	log.Printf("Using %d analyses as context after filtering", scopedCount)
+
+	// Keep only the analyses most relevant to the question so the prompt stays within limits
+	analysisContexts = ai.SelectRelevantAnalyses(c.Request.Context(), req.Question, analysisContexts)
 
 	// Call AI to answer
-	answer, err := ai.AskAnything(req.Question, analysisContexts)
+	cacheKey := askContextCacheKey(requestUserID(c).String(), analysesVersion, analysisContexts)
+	result, err := ai.AskAnything(askCtx, req.Question, analysisContexts, cacheKey)
 	if err != nil {
 		log.Printf("Ask Anything error: %v", err)
-		utils.Error(c, http.StatusInternalServerError, "failed to get answer: "+err.Error())
+		if errors.Is(err, ai.ErrOpenAITimeout) {
+			utils.Error(c, http.StatusGatewayTimeout, utils.CodeAITimeout, "failed to get answer: "+err.Error())
+			return
+		}
+		if errors.Is(err, ai.ErrAINotConfigured) {
+			utils.Error(c, http.StatusServiceUnavailable, utils.CodeAINotConfigured, err.Error())
+			return
+		}
+		utils.Error(c, http.StatusInternalServerError, utils.CodeAIFailed, "failed to get answer: "+err.Error())
 		return
 	}
 
+	answer := result.Answer
+	if truncatedFrom > 0 {
+		answer += fmt.Sprintf("\n\n(Chỉ dùng %d bản ghi gần nhất trong tổng số %d. Hãy chọn recording_ids hoặc khoảng thời gian from/to để hỏi về các bản ghi khác.)",
+			scopedCount, truncatedFrom)
+	}
+
 	log.Printf("Ask Anything answer: %s", answer)
 
 	utils.Success(c, gin.H{
-		"question": req.Question,
-		"answer":   answer,
+		"question":       req.Question,
+		"answer":         answer,
+		"sources":        result.Sources,
+		"sources_cited":  result.Cited,
+		"analyses_used":  len(analysisContexts),
+		"context_capped": truncatedFrom > 0,
 	})
 }
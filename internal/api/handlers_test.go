@@ -0,0 +1,262 @@
+package api
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"noteme/internal/ai"
+	"noteme/internal/config"
+	"noteme/internal/storage"
+	"noteme/internal/stt"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// API keys of the two users the handler tests act as
+const (
+	testAPIKey  = "test-key-owner"
+	otherAPIKey = "test-key-other"
+)
+
+func TestMain(m *testing.M) {
+	uploadDir, err := os.MkdirTemp("", "noteme-api-test-")
+	if err != nil {
+		log.Fatalf("failed to create upload dir: %v", err)
+	}
+
+	// Offline setup, like STT_PROVIDER=mock AI_PROVIDER=mock with two API keys
+	cfg := config.Default()
+	cfg.STTProvider = "mock"
+	cfg.AIProvider = "mock"
+	cfg.UploadDir = uploadDir
+	cfg.APIKeys = map[string]string{
+		testAPIKey:  "11111111-1111-1111-1111-111111111111",
+		otherAPIKey: "22222222-2222-2222-2222-222222222222",
+	}
+	ai.Configure(cfg)
+	stt.Configure(cfg)
+	Configure(cfg)
+	ai.SetAnalyzer(ai.MockAnalyzer{})
+	if err := storage.InitUploadDir(uploadDir); err != nil {
+		log.Fatalf("failed to prepare upload dir: %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+
+	code := m.Run()
+	os.RemoveAll(uploadDir)
+	os.Exit(code)
+}
+
+// newTestRouter returns an engine with the API routes registered
+func newTestRouter() *gin.Engine {
+	r := gin.New()
+	RegisterRoutes(r)
+	return r
+}
+
+// testResponse is the response envelope written by utils.Success and utils.Error
+type testResponse struct {
+	Success bool                   `json:"success"`
+	Data    map[string]interface{} `json:"data"`
+	Error   struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// doRequest serves one request as the user of apiKey (none when empty) and decodes the envelope
+func doRequest(t *testing.T, r *gin.Engine, req *http.Request, apiKey string) (*httptest.ResponseRecorder, testResponse) {
+	t.Helper()
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp testResponse
+	if w.Body.Len() > 0 {
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s %s: invalid JSON response %q: %v", req.Method, req.URL, w.Body.String(), err)
+		}
+	}
+	return w, resp
+}
+
+// multipartUpload builds a POST /api/v1/recordings request carrying data in field
+func multipartUpload(t *testing.T, field, filename string, data []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if field != "" {
+		part, err := mw.CreateFormFile(field, filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(data)
+	} else {
+		mw.WriteField("title", filename)
+	}
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/recordings", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+// testWAV returns a silent mono 16kHz PCM WAV file of size bytes (at least the 44-byte header),
+// 32000 bytes of samples per second
+func testWAV(size int) []byte {
+	dataSize := size - 44
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(36+dataSize))
+	b.WriteString("WAVEfmt ")
+	binary.Write(&b, binary.LittleEndian, uint32(16))
+	binary.Write(&b, binary.LittleEndian, uint16(1))     // PCM
+	binary.Write(&b, binary.LittleEndian, uint16(1))     // mono
+	binary.Write(&b, binary.LittleEndian, uint32(16000)) // sample rate
+	binary.Write(&b, binary.LittleEndian, uint32(32000)) // byte rate
+	binary.Write(&b, binary.LittleEndian, uint16(2))     // block align
+	binary.Write(&b, binary.LittleEndian, uint16(16))    // bits per sample
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, uint32(dataSize))
+	b.Write(make([]byte, dataSize))
+	return b.Bytes()
+}
+
+// uploadTestRecording uploads a two-second WAV file as the user of apiKey and returns its recording ID
+func uploadTestRecording(t *testing.T, r *gin.Engine, apiKey string) string {
+	t.Helper()
+	w, resp := doRequest(t, r, multipartUpload(t, "audio_file", "note.wav", testWAV(44+2*32000)), apiKey)
+	if w.Code != http.StatusOK {
+		t.Fatalf("upload: status %d, body %s", w.Code, w.Body.String())
+	}
+	id, _ := resp.Data["recording_id"].(string)
+	if id == "" {
+		t.Fatalf("upload: no recording_id in %s", w.Body.String())
+	}
+	return id
+}
+
+func TestUploadProcessGetAnalyze(t *testing.T) {
+	r := newTestRouter()
+	id := uploadTestRecording(t, r, testAPIKey)
+
+	w, resp := doRequest(t, r, httptest.NewRequest(http.MethodPost, "/api/v1/process/"+id, nil), testAPIKey)
+	if w.Code != http.StatusOK {
+		t.Fatalf("process: status %d, body %s", w.Code, w.Body.String())
+	}
+	if resp.Data["status"] != "processed" || resp.Data["provider"] != "mock" {
+		t.Errorf("process: status %v, provider %v, want processed by mock", resp.Data["status"], resp.Data["provider"])
+	}
+	if transcript, _ := resp.Data["transcript"].(string); transcript == "" {
+		t.Errorf("process: empty transcript in %s", w.Body.String())
+	}
+
+	w, resp = doRequest(t, r, httptest.NewRequest(http.MethodGet, "/api/v1/recordings/"+id, nil), testAPIKey)
+	if w.Code != http.StatusOK {
+		t.Fatalf("get: status %d, body %s", w.Code, w.Body.String())
+	}
+	if resp.Data["recording_id"] != id || resp.Data["status"] != "processed" {
+		t.Errorf("get: recording_id %v, status %v, want %s processed", resp.Data["recording_id"], resp.Data["status"], id)
+	}
+	if resp.Data["confidence_available"] != true {
+		t.Errorf("get: confidence_available = %v, want true", resp.Data["confidence_available"])
+	}
+
+	w, resp = doRequest(t, r, httptest.NewRequest(http.MethodPost, "/api/v1/ai/analyze/"+id, nil), testAPIKey)
+	if w.Code != http.StatusOK {
+		t.Fatalf("analyze: status %d, body %s", w.Code, w.Body.String())
+	}
+	if _, ok := resp.Data["summary"]; !ok {
+		t.Errorf("analyze: no summary in %s", w.Body.String())
+	}
+}
+
+func TestRecordingRoutesErrors(t *testing.T) {
+	r := newTestRouter()
+	owned := uploadTestRecording(t, r, testAPIKey)
+	spoofedMP3 := bytes.Repeat([]byte("not audio "), 200)
+
+	tests := []struct {
+		name   string
+		req    func() *http.Request
+		apiKey string
+		status int
+		code   string
+	}{
+		{
+			name:   "missing credential",
+			req:    func() *http.Request { return httptest.NewRequest(http.MethodGet, "/api/v1/recordings/"+owned, nil) },
+			status: http.StatusUnauthorized,
+			code:   "UNAUTHORIZED",
+		},
+		{
+			name:   "missing file",
+			req:    func() *http.Request { return multipartUpload(t, "", "note.wav", nil) },
+			apiKey: testAPIKey,
+			status: http.StatusBadRequest,
+			code:   "INVALID_REQUEST",
+		},
+		{
+			name:   "unsupported format",
+			req:    func() *http.Request { return multipartUpload(t, "audio_file", "note.txt", []byte("hello")) },
+			apiKey: testAPIKey,
+			status: http.StatusBadRequest,
+			code:   "UNSUPPORTED_AUDIO_FORMAT",
+		},
+		{
+			name:   "mp3 name with non-audio bytes",
+			req:    func() *http.Request { return multipartUpload(t, "audio_file", "song.mp3", spoofedMP3) },
+			apiKey: testAPIKey,
+			status: http.StatusBadRequest,
+			code:   "INVALID_AUDIO",
+		},
+		{
+			name:   "unknown recording",
+			req:    func() *http.Request { return httptest.NewRequest(http.MethodGet, "/api/v1/recordings/rec_0", nil) },
+			apiKey: testAPIKey,
+			status: http.StatusNotFound,
+			code:   "RECORDING_NOT_FOUND",
+		},
+		{
+			name:   "process unknown recording",
+			req:    func() *http.Request { return httptest.NewRequest(http.MethodPost, "/api/v1/process/rec_0", nil) },
+			apiKey: testAPIKey,
+			status: http.StatusNotFound,
+			code:   "RECORDING_NOT_FOUND",
+		},
+		{
+			name:   "recording of another user",
+			req:    func() *http.Request { return httptest.NewRequest(http.MethodGet, "/api/v1/recordings/"+owned, nil) },
+			apiKey: otherAPIKey,
+			status: http.StatusNotFound,
+			code:   "RECORDING_NOT_FOUND",
+		},
+		{
+			name:   "process recording of another user",
+			req:    func() *http.Request { return httptest.NewRequest(http.MethodPost, "/api/v1/process/"+owned, nil) },
+			apiKey: otherAPIKey,
+			status: http.StatusNotFound,
+			code:   "RECORDING_NOT_FOUND",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, resp := doRequest(t, r, tt.req(), tt.apiKey)
+			if w.Code != tt.status {
+				t.Fatalf("status %d, want %d, body %s", w.Code, tt.status, w.Body.String())
+			}
+			if resp.Success || resp.Error.Code != tt.code {
+				t.Errorf("error code %q, want %q", resp.Error.Code, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"noteme/internal/storage"
+	"noteme/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// reserveIdempotencyKey claims the Idempotency-Key of an upload for its user before the audio is
+// saved, so concurrent retries cannot both create a recording. It returns false after responding
+// when the key already belongs to a recording (the original, whose ID is returned too) or to an
+// upload still in flight (409). The caller releases a claimed key with storage.ReleaseIdempotencyKey when the upload
+// fails; completeUpload turns it into the recording's key.
+func reserveIdempotencyKey(c *gin.Context, userID uuid.UUID, key string) (string, bool) {
+	if key == "" {
+		return "", true
+	}
+
+	existingID, reserved := storage.ReserveIdempotencyKey(userID.String(), key)
+	if reserved {
+		// The in-memory keys are lost on restart; the DB keeps the key of every synced recording
+		if existingID = persistedUploadKey(c.Request.Context(), userID, "idempotency_key", key); existingID == "" {
+			return "", true
+		}
+		storage.ReleaseIdempotencyKey(userID.String(), key)
+	}
+
+	if existingID == "" {
+		utils.Error(c, http.StatusConflict, utils.CodeAlreadyProcessing, "an upload with this Idempotency-Key is still in progress")
+		return "", false
+	}
+	respondDuplicateUpload(c, existingID, "idempotency_key")
+	return existingID, false
+}
+
+// duplicateByContentHash returns the first recording of the user uploaded with the same audio
+func duplicateByContentHash(ctx context.Context, userID uuid.UUID, contentHash string) (string, bool) {
+	if contentHash == "" {
+		return "", false
+	}
+	if existingID, ok := storage.GetRecordingIDByContentHash(userID.String(), contentHash); ok {
+		return existingID, true
+	}
+	existingID := persistedUploadKey(ctx, userID, "content_sha256", contentHash)
+	return existingID, existingID != ""
+}
+
+// persistedUploadKey looks up the recording ID of a user's DB row by an upload dedupe key, or ""
+func persistedUploadKey(ctx context.Context, userID uuid.UUID, key string, value string) string {
+	if sttRepo == nil {
+		return ""
+	}
+	req, err := sttRepo.GetByUploadKey(ctx, userID, key, value)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("[Upload] Warning: failed to look up %s in database: %v", key, err)
+		}
+		return ""
+	}
+	recordingID, _ := req.Metadata["recording_id"].(string)
+	return recordingID
+}
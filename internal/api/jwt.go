@@ -0,0 +1,108 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// jwtLeeway tolerates clock skew between the token issuer and this server
+const jwtLeeway = time.Minute
+
+var errInvalidToken = errors.New("invalid token")
+
+// jwtClaims are the registered claims read from a bearer token
+type jwtClaims struct {
+	Subject   string      `json:"sub"`
+	Issuer    string      `json:"iss"`
+	Audience  jwtAudience `json:"aud"`
+	ExpiresAt *int64      `json:"exp"`
+	NotBefore *int64      `json:"nbf"`
+}
+
+// jwtAudience accepts both forms of the aud claim: a string or an array of strings
+type jwtAudience []string
+
+func (a *jwtAudience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = jwtAudience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// verifyJWT checks an HS256 token against JWT_SECRET and returns the user in its sub claim.
+// exp is required; nbf, and iss/aud when JWT_ISSUER/JWT_AUDIENCE are set, are enforced.
+func verifyJWT(token string, now time.Time) (uuid.UUID, error) {
+	secret := appConfig.JWTSecret
+	if secret == "" {
+		return uuid.Nil, errors.New("bearer tokens are not enabled")
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return uuid.Nil, errInvalidToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return uuid.Nil, errInvalidToken
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return uuid.Nil, errInvalidToken
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return uuid.Nil, errInvalidToken
+	}
+
+	var claims jwtClaims
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return uuid.Nil, errInvalidToken
+	}
+	if claims.ExpiresAt == nil || now.After(time.Unix(*claims.ExpiresAt, 0).Add(jwtLeeway)) {
+		return uuid.Nil, errors.New("token expired")
+	}
+	if claims.NotBefore != nil && now.Add(jwtLeeway).Before(time.Unix(*claims.NotBefore, 0)) {
+		return uuid.Nil, errors.New("token not valid yet")
+	}
+	if appConfig.JWTIssuer != "" && claims.Issuer != appConfig.JWTIssuer {
+		return uuid.Nil, errInvalidToken
+	}
+	if appConfig.JWTAudience != "" && !slices.Contains(claims.Audience, appConfig.JWTAudience) {
+		return uuid.Nil, errInvalidToken
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return uuid.Nil, errors.New("token subject must be a user UUID")
+	}
+	return userID, nil
+}
+
+// decodeJWTPart decodes one base64url JSON segment of a token
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Page sizes used until SetPagination is called (DEFAULT_PAGE_SIZE / MAX_PAGE_SIZE)
+var (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// SetPagination sets the page size used when ?limit= is missing or invalid and the largest
+// page a client may request. Values are validated by config.Load.
+func SetPagination(defaultSize, maxSize int) {
+	defaultPageSize = defaultSize
+	maxPageSize = maxSize
+}
+
+// parsePagination reads ?limit= and ?offset=. Invalid values fall back to the defaults
+// and limit is capped to the maximum page size.
+func parsePagination(c *gin.Context) (limit, offset int) {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	offset, err = strconv.Atoi(c.Query("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"net/http"
+	"noteme/internal/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// uuidParamKey is the gin context key a validated path UUID is stored under
+func uuidParamKey(name string) string {
+	return "uuid_param:" + name
+}
+
+// uuidParamMiddleware parses the :name path parameter once for every route in a group,
+// rejecting malformed IDs with a uniform 400 before the handler runs
+func uuidParamMiddleware(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param(name))
+		if err != nil {
+			utils.Error(c, http.StatusBadRequest, utils.CodeInvalidID, "invalid id format")
+			c.Abort()
+			return
+		}
+		c.Set(uuidParamKey(name), id)
+		c.Next()
+	}
+}
+
+// uuidParam returns the path UUID validated by uuidParamMiddleware
+func uuidParam(c *gin.Context, name string) uuid.UUID {
+	return c.MustGet(uuidParamKey(name)).(uuid.UUID)
+}
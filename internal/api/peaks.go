@@ -0,0 +1,83 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"noteme/internal/audio"
+	"noteme/internal/storage"
+	"noteme/internal/utils"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPeakBuckets = 100
+	// maxPeakBuckets is also the resolution cached per recording; smaller requests are derived from it
+	maxPeakBuckets = 1000
+)
+
+// getRecordingPeaks handles GET /api/v1/recordings/:recording_id/peaks?buckets=N.
+// Returns N (default 100, max 1000) amplitude peaks in [0, 1] for drawing a waveform. Peaks are
+// computed once with ffmpeg at full resolution and cached in memory and metadata.waveform_peaks.
+func getRecordingPeaks(c *gin.Context) {
+	id := c.Param("recording_id")
+
+	buckets := defaultPeakBuckets
+	if v := c.Query("buckets"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPeakBuckets {
+			utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "buckets must be between 1 and "+strconv.Itoa(maxPeakBuckets))
+			return
+		}
+		buckets = n
+	}
+
+	rec, ok := storage.GetRecording(id)
+	if !ok {
+		utils.Error(c, http.StatusNotFound, utils.CodeRecordingNotFound, "recording not found")
+		return
+	}
+	if rec.AudioDeleted {
+		utils.Error(c, http.StatusNotFound, utils.CodeAudioDeleted, errAudioDeleted.Error())
+		return
+	}
+	if _, err := os.Stat(rec.Path); err != nil {
+		log.Printf("Audio file of recording %s is missing: %v", id, err)
+		utils.Error(c, http.StatusNotFound, utils.CodeNotFound, "audio file not found")
+		return
+	}
+
+	peaks := rec.Peaks
+	if len(peaks) == 0 {
+		if cached, ok := loadPeaksFromDatabase(id); ok {
+			peaks = cached
+			storage.SetPeaks(id, peaks)
+		}
+	}
+	if len(peaks) == 0 {
+		computed, err := audio.ComputePeaks(c.Request.Context(), rec.Path, maxPeakBuckets)
+		if err != nil {
+			log.Printf("Failed to compute peaks for recording %s: %v", id, err)
+			if errors.Is(err, audio.ErrFFmpegUnavailable) {
+				utils.Error(c, http.StatusServiceUnavailable, utils.CodeInternal, "waveform generation is not available")
+				return
+			}
+			utils.Error(c, http.StatusInternalServerError, utils.CodeInvalidAudio, "failed to decode audio")
+			return
+		}
+		peaks = computed
+		storage.SetPeaks(id, peaks)
+		syncPeaksToDatabase(id, peaks)
+		log.Printf("Computed %d waveform peaks for recording %s", len(peaks), id)
+	}
+
+	peaks = audio.DownsamplePeaks(peaks, buckets)
+	utils.Success(c, gin.H{
+		"recording_id": id,
+		"buckets":      len(peaks),
+		"peaks":        peaks,
+	})
+}
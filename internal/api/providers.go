@@ -0,0 +1,25 @@
+package api
+
+import (
+	"noteme/internal/stt"
+	"noteme/internal/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listSTTProviders reports which STT providers are configured and reachable.
+// ?check=false skips the connectivity probe.
+func listSTTProviders(c *gin.Context) {
+	checkConnectivity := true
+	if v := c.Query("check"); v != "" {
+		if parsed, err := strconv.ParseBool(v); err == nil {
+			checkConnectivity = parsed
+		}
+	}
+
+	utils.Success(c, gin.H{
+		"default":   appConfig.STTProvider,
+		"providers": stt.ProviderStatuses(appConfig, checkConnectivity),
+	})
+}
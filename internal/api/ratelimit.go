@@ -0,0 +1,117 @@
+package api
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"noteme/internal/utils"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tokenBucket is a simple per-key token bucket
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// rateLimiter keeps token buckets in memory.
+// NOTE: buckets are per-instance; with multiple replicas each one enforces its own limit.
+type rateLimiter struct {
+	mu       sync.Mutex
+	buckets  map[string]*tokenBucket
+	capacity float64
+	rate     float64 // tokens per second
+}
+
+func newRateLimiter(perMinute int) *rateLimiter {
+	rl := &rateLimiter{
+		buckets:  make(map[string]*tokenBucket),
+		capacity: float64(perMinute),
+		rate:     float64(perMinute) / 60.0,
+	}
+	go rl.cleanupLoop(5 * time.Minute)
+	return rl
+}
+
+// allow consumes a token for key, returning the wait time when the bucket is empty
+func (rl *rateLimiter) allow(key string) (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: rl.capacity, lastSeen: now}
+		rl.buckets[key] = b
+	}
+
+	// Refill based on elapsed time
+	b.tokens = math.Min(rl.capacity, b.tokens+now.Sub(b.lastSeen).Seconds()*rl.rate)
+	b.lastSeen = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := time.Duration((1 - b.tokens) / rl.rate * float64(time.Second))
+	return false, wait
+}
+
+// cleanupLoop drops buckets that have been idle long enough to be full again
+func (rl *rateLimiter) cleanupLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		rl.mu.Lock()
+		for key, b := range rl.buckets {
+			if time.Since(b.lastSeen) > interval {
+				delete(rl.buckets, key)
+			}
+		}
+		rl.mu.Unlock()
+	}
+}
+
+// aiRateLimitMiddleware limits requests per user on the expensive AI endpoints.
+// Configured via AI_RATE_LIMIT_PER_MIN (default 20, 0 disables).
+func aiRateLimitMiddleware() gin.HandlerFunc {
+	perMinute := appConfig.AIRateLimitPerMin
+
+	if perMinute == 0 {
+		log.Printf("[RateLimit] AI rate limiting disabled")
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	log.Printf("[RateLimit] AI endpoints limited to %d requests/min per user (per instance)", perMinute)
+	limiter := newRateLimiter(perMinute)
+
+	return func(c *gin.Context) {
+		key := rateLimitKey(c)
+		ok, wait := limiter.allow(key)
+		if !ok {
+			retryAfter := int(math.Ceil(wait.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			log.Printf("[RateLimit] Rate limit exceeded for %s on %s", key, c.FullPath())
+			utils.Error(c, http.StatusTooManyRequests, utils.CodeRateLimited, "rate limit exceeded, retry after "+strconv.Itoa(retryAfter)+"s")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimitKey buckets a request by its authenticated user. Requests without a verified API key
+// or JWT (REQUIRE_AUTH=false) all run as the default user, so they are bucketed by client IP.
+func rateLimitKey(c *gin.Context) string {
+	method, _ := c.Get(authMethodContextKey)
+	if method == authMethodAPIKey || method == authMethodJWT {
+		return "user:" + requestUserID(c).String()
+	}
+	return "ip:" + c.ClientIP()
+}
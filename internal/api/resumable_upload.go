@@ -0,0 +1,195 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"noteme/internal/storage"
+	"noteme/internal/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateUploadRequest starts a resumable upload
+type CreateUploadRequest struct {
+	Filename  string `json:"filename" binding:"required"`
+	TotalSize int64  `json:"total_size"` // optional, can also be sent in Content-Range
+}
+
+// createResumableUpload handles POST /uploads
+func createResumableUpload(c *gin.Context) {
+	var req CreateUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "invalid request: "+err.Error())
+		return
+	}
+	if req.TotalSize < 0 {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "total_size must not be negative")
+		return
+	}
+	if err := validateAudioUpload(req.Filename, req.TotalSize); err != nil {
+		utils.Error(c, uploadErrorStatus(err), uploadErrorCode(err), err.Error())
+		return
+	}
+
+	upload, err := storage.CreateUpload(req.Filename, req.TotalSize, maxUploadBytes, requestUserID(c).String())
+	if err != nil {
+		log.Printf("[Upload] Failed to create resumable upload: %v", err)
+		utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to create upload")
+		return
+	}
+
+	log.Printf("[Upload] Started resumable upload %s (%s, %d bytes)", upload.ID, upload.Filename, upload.TotalSize)
+	utils.SuccessWithStatus(c, http.StatusCreated, uploadStatus(upload))
+}
+
+// getResumableUpload handles GET /uploads/:id so clients can resume from the received offset
+func getResumableUpload(c *gin.Context) {
+	upload, ok := storage.GetUpload(c.Param("id"), requestUserID(c).String())
+	if !ok {
+		utils.Error(c, http.StatusNotFound, utils.CodeUploadNotFound, storage.ErrUploadNotFound.Error())
+		return
+	}
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Received, 10))
+	utils.Success(c, uploadStatus(upload))
+}
+
+// appendResumableUpload handles PATCH /uploads/:id with a "Content-Range: bytes start-end/total" header
+func appendResumableUpload(c *gin.Context) {
+	id := c.Param("id")
+	userID := requestUserID(c).String()
+
+	start, end, total, err := parseContentRange(c.GetHeader("Content-Range"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+	if end >= maxUploadBytes || total > maxUploadBytes {
+		utils.Error(c, http.StatusRequestEntityTooLarge, utils.CodeAudioTooLarge, "file size exceeds 25MB limit")
+		return
+	}
+
+	chunk := http.MaxBytesReader(c.Writer, c.Request.Body, end-start+1)
+	upload, err := storage.AppendUploadChunk(id, userID, start, total, chunk)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrUploadNotFound):
+			utils.Error(c, http.StatusNotFound, utils.CodeUploadNotFound, err.Error())
+		case errors.Is(err, storage.ErrUploadOffsetMismatch):
+			if current, ok := storage.GetUpload(id, userID); ok {
+				c.Header("Upload-Offset", strconv.FormatInt(current.Received, 10))
+				utils.Error(c, http.StatusConflict, utils.CodeUploadOffsetMismatch, fmt.Sprintf("%s: expected offset %d", err.Error(), current.Received))
+				return
+			}
+			utils.Error(c, http.StatusConflict, utils.CodeUploadOffsetMismatch, err.Error())
+		case errors.Is(err, storage.ErrUploadTooLarge):
+			utils.Error(c, http.StatusRequestEntityTooLarge, utils.CodeAudioTooLarge, "file size exceeds 25MB limit")
+		default:
+			log.Printf("[Upload] Failed to append chunk to %s: %v", id, err)
+			utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		}
+		return
+	}
+
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Received, 10))
+	utils.Success(c, uploadStatus(upload))
+}
+
+// finalizeResumableUpload handles POST /uploads/:id/complete and turns the upload into a recording
+func finalizeResumableUpload(c *gin.Context) {
+	id := c.Param("id")
+	userID := requestUserID(c)
+
+	// Return the original recording when a client retries with the same Idempotency-Key
+	idempotencyKey := strings.TrimSpace(c.GetHeader("Idempotency-Key"))
+	if existingID, ok := reserveIdempotencyKey(c, userID, idempotencyKey); !ok {
+		if existingID != "" {
+			storage.DeleteUpload(id, userID.String())
+		}
+		return
+	}
+	// Give the key up unless the upload completes, so the client can retry
+	defer storage.ReleaseIdempotencyKey(userID.String(), idempotencyKey)
+
+	contentHash, ok := storage.UploadContentHash(id, userID.String())
+	if !ok {
+		utils.Error(c, http.StatusNotFound, utils.CodeUploadNotFound, storage.ErrUploadNotFound.Error())
+		return
+	}
+
+	// Optionally dedupe identical audio uploaded under a different key
+	if c.Query("dedupe") == "true" {
+		if existingID, ok := duplicateByContentHash(c.Request.Context(), userID, contentHash); ok {
+			storage.DeleteUpload(id, userID.String())
+			respondDuplicateUpload(c, existingID, "content_hash")
+			return
+		}
+	}
+
+	recordingID, err := storage.FinalizeUpload(id, userID.String())
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrUploadNotFound):
+			utils.Error(c, http.StatusNotFound, utils.CodeUploadNotFound, err.Error())
+		case errors.Is(err, storage.ErrUploadIncomplete):
+			utils.Error(c, http.StatusConflict, utils.CodeUploadIncomplete, err.Error())
+		default:
+			log.Printf("Error finalizing upload %s: %v", id, err)
+			utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to save audio file")
+		}
+		return
+	}
+
+	log.Printf("[Upload] Finalized resumable upload %s as recording %s", id, recordingID)
+	completeUpload(c, recordingID, idempotencyKey, contentHash)
+}
+
+// parseContentRange parses "bytes start-end/total" where total may be "*"
+func parseContentRange(header string) (start, end, total int64, err error) {
+	if header == "" {
+		return 0, 0, 0, errors.New("Content-Range header is required")
+	}
+
+	spec, ok := strings.CutPrefix(strings.TrimSpace(header), "bytes ")
+	if !ok {
+		return 0, 0, 0, errors.New("Content-Range must use bytes unit")
+	}
+	rangePart, totalPart, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, 0, errors.New("invalid Content-Range format, expected bytes start-end/total")
+	}
+	startStr, endStr, ok := strings.Cut(rangePart, "-")
+	if !ok {
+		return 0, 0, 0, errors.New("invalid Content-Range format, expected bytes start-end/total")
+	}
+
+	if start, err = strconv.ParseInt(startStr, 10, 64); err != nil || start < 0 {
+		return 0, 0, 0, errors.New("invalid Content-Range start")
+	}
+	if end, err = strconv.ParseInt(endStr, 10, 64); err != nil || end < start {
+		return 0, 0, 0, errors.New("invalid Content-Range end")
+	}
+	if totalPart != "*" {
+		if total, err = strconv.ParseInt(totalPart, 10, 64); err != nil || total <= end {
+			return 0, 0, 0, errors.New("invalid Content-Range total")
+		}
+	}
+	return start, end, total, nil
+}
+
+// uploadStatus renders the resumable upload state for responses
+func uploadStatus(upload *storage.PartialUpload) gin.H {
+	complete := upload.TotalSize > 0 && upload.Received == upload.TotalSize
+	return gin.H{
+		"upload_id":  upload.ID,
+		"filename":   upload.Filename,
+		"received":   upload.Received,
+		"total_size": upload.TotalSize,
+		"complete":   complete,
+		"expires_at": upload.UpdatedAt.Add(storage.UploadTTL()).Format(time.RFC3339),
+	}
+}
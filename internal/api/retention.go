@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"noteme/internal/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// retentionBatchSize is how many expired rows one retention query deletes
+const retentionBatchSize = 100
+
+// RetentionOptions configures the retention job
+type RetentionOptions struct {
+	Days     int           // default retention in days, 0 = keep forever (per-user overrides still apply)
+	Purge    bool          // permanently delete expired rows instead of soft deleting them
+	Interval time.Duration // time between runs
+}
+
+// StartRetentionJob deletes expired recordings now and then every opts.Interval until ctx is done.
+// It does nothing without a database.
+func StartRetentionJob(ctx context.Context, opts RetentionOptions) {
+	if sttRepo == nil {
+		log.Printf("[Retention] No database, retention job disabled")
+		return
+	}
+
+	log.Printf("[Retention] Retention job started: default %d days (0 = keep forever), purge=%v, every %v",
+		opts.Days, opts.Purge, opts.Interval)
+
+	go func() {
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+		for {
+			runRetention(ctx, opts)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// runRetention deletes expired rows in batches, together with their audio files and in-memory state
+func runRetention(ctx context.Context, opts RetentionOptions) int {
+	total := 0
+	for ctx.Err() == nil {
+		expired, err := sttRepo.DeleteExpired(ctx, opts.Days, opts.Purge, retentionBatchSize)
+		if err != nil {
+			log.Printf("[Retention] Error deleting expired recordings: %v", err)
+			break
+		}
+
+		for _, rec := range expired {
+			cleanupPurgedRecord(rec)
+		}
+		total += len(expired)
+
+		if len(expired) < retentionBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		action := "soft deleted"
+		if opts.Purge {
+			action = "purged"
+		}
+		log.Printf("[Audit] Retention job %s %d expired STT requests", action, total)
+	}
+	return total
+}
+
+// RetentionRequest is the PUT /api/admin/users/:user_id/retention request body
+type RetentionRequest struct {
+	RetentionDays *int `json:"retention_days"` // 0 = keep this user's recordings forever
+}
+
+// setUserRetention handles PUT /api/admin/users/:user_id/retention: overrides RETENTION_DAYS for one user
+func setUserRetention(c *gin.Context) {
+	userID := uuidParam(c, "user_id")
+
+	var req RetentionRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.RetentionDays == nil || *req.RetentionDays < 0 {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "retention_days must be a non-negative number")
+		return
+	}
+
+	if err := sttRepo.SetRetentionDays(c.Request.Context(), userID, *req.RetentionDays); err != nil {
+		log.Printf("Error setting retention for user %s: %v", userID, err)
+		utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to set retention")
+		return
+	}
+	log.Printf("[Audit] Set retention of user %s to %d days by admin from %s", userID, *req.RetentionDays, c.ClientIP())
+
+	utils.Success(c, gin.H{
+		"user_id":        userID.String(),
+		"retention_days": *req.RetentionDays,
+	})
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"noteme/internal/storage"
+	"noteme/internal/stt"
+	"noteme/internal/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// submitAsyncTranscription hands a recording to an async provider and answers 202. The recording
+// stays "processing" until the provider POSTs the transcript to sttCallback.
+func submitAsyncTranscription(c *gin.Context, ctx context.Context, rec *storage.Recording, provider stt.AsyncProvider, opts transcriptionOptions) {
+	id := rec.ID
+	token, err := newCallbackToken()
+	if err != nil {
+		log.Printf("Failed to create callback token for recording %s: %v", id, err)
+		storage.UpdateStatus(id, rec.Status)
+		utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to start transcription")
+		return
+	}
+
+	jobID, err := provider.Submit(ctx, rec.Path, callbackURL(id, token))
+	if err != nil {
+		log.Printf("STT job submission failed for recording %s (provider: %s): %v", id, provider.Name(), err)
+		storage.UpdateStatus(id, "failed")
+		storage.UpdateError(id, err.Error())
+		utils.Error(c, http.StatusBadRequest, utils.CodeSTTFailed, err.Error())
+		return
+	}
+
+	storage.SaveSTTJob(&storage.STTJob{
+		RecordingID:      id,
+		Provider:         provider.Name(),
+		JobID:            jobID,
+		Token:            token,
+		SubmittedAt:      time.Now(),
+		OutputLanguage:   opts.OutputLanguage,
+		FilterProfanity:  opts.FilterProfanity,
+		UseFastClean:     opts.UseFastClean,
+		ProviderOverride: opts.ProviderOverride,
+		UserID:           opts.UserID.String(),
+	})
+	log.Printf("Submitted async STT job %s for recording %s (provider: %s)", jobID, id, provider.Name())
+
+	syncToDatabase(id, opts.UserID, provider.Name())
+
+	utils.SuccessWithStatus(c, http.StatusAccepted, gin.H{
+		"recording_id": id,
+		"status":       "processing",
+		"async":        true,
+		"job_id":       jobID,
+		"provider":     provider.Name(),
+	})
+}
+
+// asyncJobExpired reports whether the recording's async job went without a callback for longer
+// than STT_ASYNC_TIMEOUT, dropping the job so the recording can be processed again
+func asyncJobExpired(id string) bool {
+	job, ok := storage.GetSTTJob(id)
+	if !ok || time.Since(job.SubmittedAt) < appConfig.STTAsyncTimeout {
+		return false
+	}
+	log.Printf("Async STT job %s for recording %s got no callback within %v, allowing a retry", job.JobID, id, appConfig.STTAsyncTimeout)
+	storage.DeleteSTTJob(id)
+	return true
+}
+
+// sttCallback handles POST /api/v1/stt/callback/:recording_id, where async providers deliver
+// transcripts. It is authenticated by the signed callback URL instead of an API key.
+func sttCallback(c *gin.Context) {
+	id := c.Param("recording_id")
+	token, sig := c.Query("token"), c.Query("sig")
+	if appConfig.STTCallbackSecret == "" {
+		utils.Error(c, http.StatusNotFound, utils.CodeNotFound, "async transcription is not enabled")
+		return
+	}
+	if !hmac.Equal([]byte(sig), []byte(callbackSignature(id, token))) {
+		log.Printf("Rejected STT callback for recording %s: invalid signature", id)
+		utils.Error(c, http.StatusUnauthorized, utils.CodeUnauthorized, "invalid callback signature")
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "failed to read callback body")
+		return
+	}
+
+	// Taking the job makes the callback URL single-use
+	job, ok := storage.TakeSTTJob(id, token)
+	if !ok {
+		utils.Error(c, http.StatusNotFound, utils.CodeNotFound, "no pending transcription for this callback")
+		return
+	}
+	rec, ok := storage.GetRecording(id)
+	if !ok {
+		utils.Error(c, http.StatusNotFound, utils.CodeRecordingNotFound, "recording not found")
+		return
+	}
+	userID, _ := uuid.Parse(job.UserID)
+
+	result, err := parseSTTCallback(job.Provider, body)
+	if err != nil {
+		// The provider delivered; acknowledge so it does not retry, and record the failure
+		log.Printf("Async STT job %s for recording %s failed (provider: %s): %v", job.JobID, id, job.Provider, err)
+		storage.UpdateStatus(id, "failed")
+		storage.UpdateError(id, err.Error())
+		syncToDatabase(id, userID, job.Provider)
+		utils.Success(c, gin.H{"recording_id": id, "status": "failed"})
+		return
+	}
+
+	log.Printf("Received async STT result for recording %s (job %s)", id, job.JobID)
+	opts := transcriptionOptions{
+		OutputLanguage:   job.OutputLanguage,
+		FilterProfanity:  job.FilterProfanity,
+		UseFastClean:     job.UseFastClean,
+		ProviderOverride: job.ProviderOverride,
+		UserID:           userID,
+	}
+	if _, err := finishTranscription(c.Request.Context(), rec, job.Provider, result, time.Since(job.SubmittedAt), opts); err != nil {
+		// No speech: finishTranscription marked the recording failed
+		syncToDatabase(id, userID, job.Provider)
+		utils.Success(c, gin.H{"recording_id": id, "status": "failed"})
+		return
+	}
+	utils.Success(c, gin.H{"recording_id": id, "status": "processed"})
+}
+
+// parseSTTCallback parses a callback body with the async mode of the named provider
+func parseSTTCallback(providerName string, body []byte) (*stt.Result, error) {
+	provider, err := getNamedSTTProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+	asyncProvider, ok := stt.AsyncMode(provider)
+	if !ok {
+		return nil, fmt.Errorf("STT provider %s has no async mode enabled", providerName)
+	}
+	return asyncProvider.ParseCallback(body)
+}
+
+// callbackURL builds the signed URL an async provider calls back for a recording
+func callbackURL(id, token string) string {
+	query := url.Values{"token": {token}, "sig": {callbackSignature(id, token)}}
+	return appConfig.STTCallbackBaseURL + "/api/v1/stt/callback/" + url.PathEscape(id) + "?" + query.Encode()
+}
+
+// callbackSignature is the hex HMAC-SHA256 of "<recording id>.<token>" with STT_CALLBACK_SECRET
+func callbackSignature(id, token string) string {
+	mac := hmac.New(sha256.New, []byte(appConfig.STTCallbackSecret))
+	mac.Write([]byte(id + "." + token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newCallbackToken returns a random per-job token for the callback URL
+func newCallbackToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -1,12 +1,18 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"noteme/internal/export"
 	"noteme/internal/model"
+	"noteme/internal/repository"
 	"noteme/internal/utils"
-	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -14,48 +20,66 @@ import (
 
 // sttRepo is declared in repository.go (shared across package)
 
-// getSTTHistory handles GET /api/stt/history
-func getSTTHistory(c *gin.Context) {
-	// Get user_id from query parameter (for MVP, we'll use a default or require it)
-	userIDStr := c.Query("user_id")
-	if userIDStr == "" {
-		// For MVP, use a default user ID if not provided
-		// In production, this should come from authentication
-		userIDStr = c.GetHeader("X-User-ID")
-		if userIDStr == "" {
-			utils.Error(c, http.StatusBadRequest, "user_id is required (query parameter or X-User-ID header)")
-			return
-		}
+// historyUserID resolves whose rows a history/search request reads: always the authenticated
+// user. A ?user_id= naming someone else is forbidden. It writes the error response when ok is false.
+func historyUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID := requestUserID(c)
+	if requested := c.Query("user_id"); requested != "" && requested != userID.String() {
+		utils.Error(c, http.StatusForbidden, utils.CodeForbidden, "cannot access another user's data")
+		return uuid.Nil, false
 	}
+	return userID, true
+}
 
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		utils.Error(c, http.StatusBadRequest, "invalid user_id format")
+// getSTTHistory handles GET /api/stt/history
+func getSTTHistory(c *gin.Context) {
+	userID, ok := historyUserID(c)
+	if !ok {
 		return
 	}
 
 	// Parse pagination parameters
-	limitStr := c.DefaultQuery("limit", "20")
-	offsetStr := c.DefaultQuery("offset", "0")
+	limit, offset := parsePagination(c)
+
+	// Optional tag filter
+	tag := strings.TrimSpace(c.Query("tag"))
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit < 1 {
-		limit = 20
+	// Optional entity filter (e.g. ?entity=Golang)
+	entity := strings.TrimSpace(c.Query("entity"))
+
+	// Optional ordering (?sort=created_at|duration|confidence|title&order=asc|desc)
+	sort := strings.TrimSpace(c.Query("sort"))
+	order := strings.ToLower(strings.TrimSpace(c.Query("order")))
+	if err := repository.ValidateSort(sort, order); err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
 	}
-	if limit > 100 {
-		limit = 100 // Max limit
+
+	// Optional status filter (deleted records are never listed)
+	status := strings.TrimSpace(c.Query("status"))
+	if status == "deleted" {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "invalid status filter")
+		return
 	}
 
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil || offset < 0 {
-		offset = 0
+	// Optional sparse fieldset (?fields=id,title,status)
+	fields, err := parseFields(c, sttHistoryFields)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
 	}
 
 	// Get records from repository
-	requests, err := sttRepo.ListByUser(c.Request.Context(), userID, limit, offset)
+	requests, err := sttRepo.ListByUser(c.Request.Context(), userID, limit, offset, repository.ListOptions{
+		Tag:    tag,
+		Status: status,
+		Entity: entity,
+		Sort:   sort,
+		Order:  order,
+	})
 	if err != nil {
 		log.Printf("Error listing STT history: %v", err)
-		utils.Error(c, http.StatusInternalServerError, "failed to retrieve history")
+		utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to retrieve history")
 		return
 	}
 
@@ -83,6 +107,9 @@ func getSTTHistory(c *gin.Context) {
 		if req.AudioDurationMs != nil {
 			item["audio_duration_ms"] = *req.AudioDurationMs
 		}
+		if req.Confidence != nil {
+			item["confidence"] = *req.Confidence
+		}
 
 		// Add transcript preview (first 100 chars)
 		if req.Transcript != nil && *req.Transcript != "" {
@@ -93,7 +120,12 @@ func getSTTHistory(c *gin.Context) {
 			item["transcript_preview"] = transcript
 		}
 
-		items = append(items, item)
+		// Add tags
+		if tags := tagsFromMetadata(req.Metadata); len(tags) > 0 {
+			item["tags"] = tags
+		}
+
+		items = append(items, projectFields(item, fields))
 	}
 
 	utils.Success(c, gin.H{
@@ -104,17 +136,38 @@ func getSTTHistory(c *gin.Context) {
 	})
 }
 
-// getSTTDetail handles GET /api/stt/:id
-func getSTTDetail(c *gin.Context) {
-	idStr := c.Param("id")
-	if idStr == "" {
-		utils.Error(c, http.StatusBadRequest, "id is required")
+// getSTTStats handles GET /api/stt/stats: record counts by status, total audio duration
+// and total action items of the user, for the app's home screen
+func getSTTStats(c *gin.Context) {
+	userID, ok := historyUserID(c)
+	if !ok {
 		return
 	}
 
-	id, err := uuid.Parse(idStr)
+	stats, err := sttRepo.Stats(c.Request.Context(), userID)
 	if err != nil {
-		utils.Error(c, http.StatusBadRequest, "invalid id format")
+		log.Printf("Error getting STT stats: %v", err)
+		utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to retrieve stats")
+		return
+	}
+
+	utils.Success(c, gin.H{
+		"total":              stats.Total,
+		"by_status":          stats.ByStatus,
+		"total_duration_ms":  stats.TotalDurationMs,
+		"total_hours":        math.Round(float64(stats.TotalDurationMs)/float64(time.Hour/time.Millisecond)*100) / 100,
+		"total_action_items": stats.TotalActionItems,
+	})
+}
+
+// getSTTDetail handles GET /api/stt/:id
+func getSTTDetail(c *gin.Context) {
+	id := uuidParam(c, "id")
+
+	// Optional sparse fieldset (?fields=id,title,status)
+	fields, err := parseFields(c, sttDetailFields)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
 		return
 	}
 
@@ -122,7 +175,7 @@ func getSTTDetail(c *gin.Context) {
 	req, err := sttRepo.GetByID(c.Request.Context(), id)
 	if err != nil {
 		log.Printf("Error getting STT detail: %v", err)
-		utils.Error(c, http.StatusNotFound, "STT request not found")
+		utils.Error(c, http.StatusNotFound, utils.CodeSTTRequestNotFound, "STT request not found")
 		return
 	}
 
@@ -166,51 +219,102 @@ func getSTTDetail(c *gin.Context) {
 		response["language"] = *req.Language
 	}
 
+	// Speaker-labeled transcript when the recording was processed with diarization
+	if segments := segmentsFromMetadata(req.Metadata); len(segments) > 0 {
+		addSegments(response, segments)
+	}
+
+	// Timed sentences for highlighting during playback (?segments=true)
+	var transcript string
+	if req.Transcript != nil {
+		transcript = *req.Transcript
+	}
+	var durationSeconds float64
+	if req.AudioDurationMs != nil {
+		durationSeconds = float64(*req.AudioDurationMs) / 1000
+	}
+	addSentences(c, response, sentencesFromMetadata(req.Metadata), transcript, durationSeconds)
+
+	// Add tags
+	response["tags"] = tagsFromMetadata(req.Metadata)
+
 	// Add metadata (including ai_analysis)
 	if len(req.Metadata) > 0 {
 		response["metadata"] = req.Metadata
 	}
 
-	utils.Success(c, response)
+	utils.Success(c, projectFields(response, fields))
 }
 
-// UpdateTitleRequest represents the request body for updating title
-type UpdateTitleRequest struct {
-	Title string `json:"title" binding:"required"`
-}
+// exportSTT handles GET /api/stt/:id/export?format=markdown|pdf
+func exportSTT(c *gin.Context) {
+	id := uuidParam(c, "id")
 
-// updateSTTTitle handles PATCH /api/stt/:id/title
-func updateSTTTitle(c *gin.Context) {
-	idStr := c.Param("id")
-	if idStr == "" {
-		utils.Error(c, http.StatusBadRequest, "id is required")
+	renderer, err := export.RendererFor(c.DefaultQuery("format", "markdown"))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Get record from repository
+	req, err := sttRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		log.Printf("Error getting STT request for export: %v", err)
+		utils.Error(c, http.StatusNotFound, utils.CodeSTTRequestNotFound, "STT request not found")
 		return
 	}
 
-	id, err := uuid.Parse(idStr)
+	doc, err := export.FromSTTRequest(req)
 	if err != nil {
-		utils.Error(c, http.StatusBadRequest, "invalid id format")
+		if errors.Is(err, export.ErrNoAnalysis) {
+			utils.Error(c, http.StatusNotFound, utils.CodeAnalysisNotFound, err.Error())
+		} else {
+			log.Printf("Error building export document: %v", err)
+			utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to build export")
+		}
 		return
 	}
 
+	data, err := renderer.Render(doc)
+	if err != nil {
+		log.Printf("Error rendering export: %v", err)
+		utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to render export")
+		return
+	}
+
+	log.Printf("Exported STT request %s (%s, %d bytes)", id.String(), renderer.ContentType(), len(data))
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.Filename(doc, renderer)))
+	c.Data(http.StatusOK, renderer.ContentType(), data)
+}
+
+// UpdateTitleRequest represents the request body for updating title
+type UpdateTitleRequest struct {
+	Title string `json:"title" binding:"required"`
+}
+
+// updateSTTTitle handles PATCH /api/stt/:id/title
+func updateSTTTitle(c *gin.Context) {
+	id := uuidParam(c, "id")
+
 	var req UpdateTitleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.Error(c, http.StatusBadRequest, "title is required")
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "title is required")
 		return
 	}
 
 	if req.Title == "" {
-		utils.Error(c, http.StatusBadRequest, "title cannot be empty")
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "title cannot be empty")
 		return
 	}
 
 	// Update title in repository
 	if err := sttRepo.UpdateTitle(c.Request.Context(), id, req.Title); err != nil {
 		log.Printf("Error updating title: %v", err)
-		if err.Error() == "STT request not found or already deleted" {
-			utils.Error(c, http.StatusNotFound, "STT request not found or already deleted")
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.Error(c, http.StatusNotFound, utils.CodeSTTRequestNotFound, "STT request not found or already deleted")
 		} else {
-			utils.Error(c, http.StatusInternalServerError, "failed to update title")
+			utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to update title")
 		}
 		return
 	}
@@ -224,33 +328,124 @@ func updateSTTTitle(c *gin.Context) {
 	})
 }
 
-// deleteSTT handles DELETE /api/stt/:id
-func deleteSTT(c *gin.Context) {
-	idStr := c.Param("id")
-	if idStr == "" {
-		utils.Error(c, http.StatusBadRequest, "id is required")
+const (
+	maxTagsPerRecording = 20
+	maxTagLength        = 50
+)
+
+// UpdateTagsRequest represents the request body for updating tags
+type UpdateTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// updateSTTTags handles PATCH /api/stt/:id/tags
+func updateSTTTags(c *gin.Context) {
+	id := uuidParam(c, "id")
+
+	var req UpdateTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Tags == nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "tags is required")
 		return
 	}
 
-	id, err := uuid.Parse(idStr)
+	tags, err := normalizeTags(req.Tags)
 	if err != nil {
-		utils.Error(c, http.StatusBadRequest, "invalid id format")
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+
+	// Update tags in repository
+	if err := sttRepo.UpdateTags(c.Request.Context(), id, tags); err != nil {
+		log.Printf("Error updating tags: %v", err)
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.Error(c, http.StatusNotFound, utils.CodeSTTRequestNotFound, "STT request not found or already deleted")
+		} else {
+			utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to update tags")
+		}
+		return
+	}
+
+	log.Printf("Tags updated for STT request: %s (%d tags)", id.String(), len(tags))
+
+	utils.Success(c, gin.H{
+		"id":      id.String(),
+		"tags":    tags,
+		"message": "Tags updated successfully",
+	})
+}
+
+// normalizeTags trims, dedupes, and validates tags
+func normalizeTags(raw []string) ([]string, error) {
+	tags := make([]string, 0, len(raw))
+	seen := make(map[string]bool)
+	for _, tag := range raw {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, fmt.Errorf("tag %q exceeds %d characters", tag, maxTagLength)
+		}
+		key := strings.ToLower(tag)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxTagsPerRecording {
+		return nil, fmt.Errorf("too many tags (max %d)", maxTagsPerRecording)
+	}
+	return tags, nil
+}
+
+// tagsFromMetadata extracts the tags list from record metadata
+func tagsFromMetadata(metadata map[string]interface{}) []string {
+	tags := []string{}
+	raw, ok := metadata["tags"].([]interface{})
+	if !ok {
+		return tags
+	}
+	for _, t := range raw {
+		if tag, ok := t.(string); ok {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// deleteSTT handles DELETE /api/stt/:id
+func deleteSTT(c *gin.Context) {
+	id := uuidParam(c, "id")
+
+	// Permanent deletion (GDPR) is admin-only; refuse instead of silently soft deleting
+	if c.Query("purge") == "true" {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "permanent deletion moved to DELETE /api/admin/stt/:id")
 		return
 	}
 
+	// Look up the in-memory recording ID before the row becomes invisible
+	var recordingID string
+	if existing, err := sttRepo.GetByID(c.Request.Context(), id); err == nil {
+		recordingID, _ = existing.Metadata["recording_id"].(string)
+	}
+
 	// Soft delete in repository
 	if err := sttRepo.Delete(c.Request.Context(), id); err != nil {
 		log.Printf("Error deleting STT request: %v", err)
-		if err.Error() == "STT request not found or already deleted" {
-			utils.Error(c, http.StatusNotFound, "STT request not found or already deleted")
+		if errors.Is(err, repository.ErrNotFound) {
+			utils.Error(c, http.StatusNotFound, utils.CodeSTTRequestNotFound, "STT request not found or already deleted")
 		} else {
-			utils.Error(c, http.StatusInternalServerError, "failed to delete STT request")
+			utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to delete STT request")
 		}
 		return
 	}
 
 	log.Printf("STT request deleted: %s", id.String())
 
+	// Cascade to in-memory storage so deleted data stops appearing in Ask Anything
+	purgeInMemoryRecording(id, recordingID)
+
 	utils.Success(c, gin.H{
 		"id":      id.String(),
 		"status":  "deleted",
@@ -260,53 +455,35 @@ func deleteSTT(c *gin.Context) {
 
 // searchSTT handles GET /api/stt/search
 func searchSTT(c *gin.Context) {
-	// Get user_id from query parameter or header
-	userIDStr := c.Query("user_id")
-	if userIDStr == "" {
-		userIDStr = c.GetHeader("X-User-ID")
-		if userIDStr == "" {
-			utils.Error(c, http.StatusBadRequest, "user_id is required (query parameter or X-User-ID header)")
-			return
-		}
-	}
-
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		utils.Error(c, http.StatusBadRequest, "invalid user_id format")
+	userID, ok := historyUserID(c)
+	if !ok {
 		return
 	}
 
 	// Get search query
 	searchQuery := c.Query("q")
 	if searchQuery == "" {
-		utils.Error(c, http.StatusBadRequest, "search query (q) is required")
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "search query (q) is required")
 		return
 	}
 
 	// Parse pagination parameters
-	limitStr := c.DefaultQuery("limit", "20")
-	offsetStr := c.DefaultQuery("offset", "0")
+	limit, offset := parsePagination(c)
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit < 1 {
-		limit = 20
-	}
-	if limit > 100 {
-		limit = 100 // Max limit
-	}
-
-	offset, err := strconv.Atoi(offsetStr)
-	if err != nil || offset < 0 {
-		offset = 0
+	// Optional sparse fieldset (?fields=id,title,status)
+	fields, err := parseFields(c, sttSearchFields)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
 	}
 
-	log.Printf("Search request: user=%s, query=%s, limit=%d, offset=%d", userIDStr, searchQuery, limit, offset)
+	log.Printf("Search request: user=%s, query=%s, limit=%d, offset=%d", userID, searchQuery, limit, offset)
 
 	// Search in repository
 	requests, err := sttRepo.Search(c.Request.Context(), userID, searchQuery, limit, offset)
 	if err != nil {
 		log.Printf("Error searching STT requests: %v", err)
-		utils.Error(c, http.StatusInternalServerError, "failed to search")
+		utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to search")
 		return
 	}
 
@@ -356,7 +533,7 @@ func searchSTT(c *gin.Context) {
 			}
 		}
 
-		items = append(items, item)
+		items = append(items, projectFields(item, fields))
 	}
 
 	log.Printf("Search returned %d results", len(items))
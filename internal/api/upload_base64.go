@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/base64"
+	"log"
+	"net/http"
+	"noteme/internal/storage"
+	"noteme/internal/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Base64UploadRequest represents a JSON audio upload
+type Base64UploadRequest struct {
+	Filename    string `json:"filename" binding:"required"`
+	AudioBase64 string `json:"audio_base64" binding:"required"`
+}
+
+// maxBase64BodyBytes caps the JSON body: the encoded audio plus room for the other fields
+var maxBase64BodyBytes = int64(base64.StdEncoding.EncodedLen(maxUploadBytes)) + 64*1024
+
+// uploadRecordingBase64 handles audio uploaded as a base64 string in a JSON body,
+// for clients that cannot easily send multipart forms
+func uploadRecordingBase64(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBase64BodyBytes)
+
+	var req Base64UploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if isBodyTooLarge(err) {
+			utils.Error(c, http.StatusRequestEntityTooLarge, utils.CodeAudioTooLarge, "file size exceeds 25MB limit")
+			return
+		}
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "invalid request: "+err.Error())
+		return
+	}
+
+	payload := stripDataURIPrefix(strings.TrimSpace(req.AudioBase64))
+
+	// Reject oversized payloads before decoding
+	if err := validateAudioUpload(req.Filename, int64(base64.StdEncoding.DecodedLen(len(payload)))); err != nil {
+		utils.Error(c, uploadErrorStatus(err), uploadErrorCode(err), err.Error())
+		return
+	}
+
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		log.Printf("[Upload] Invalid base64 payload for %s: %v", req.Filename, err)
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "audio_base64 is not valid base64")
+		return
+	}
+	if len(data) == 0 {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "audio_base64 is empty")
+		return
+	}
+
+	// Return the original recording when a client retries with the same Idempotency-Key
+	userID := requestUserID(c)
+	idempotencyKey := strings.TrimSpace(c.GetHeader("Idempotency-Key"))
+	if _, ok := reserveIdempotencyKey(c, userID, idempotencyKey); !ok {
+		return
+	}
+	// Give the key up unless the upload completes, so the client can retry
+	defer storage.ReleaseIdempotencyKey(userID.String(), idempotencyKey)
+
+	contentHash := storage.HashAudioBytes(data)
+
+	// Optionally dedupe identical audio uploaded under a different key
+	if c.Query("dedupe") == "true" {
+		if existingID, ok := duplicateByContentHash(c.Request.Context(), userID, contentHash); ok {
+			respondDuplicateUpload(c, existingID, "content_hash")
+			return
+		}
+	}
+
+	recordingID, err := storage.SaveAudioBytes(req.Filename, data, userID.String())
+	if err != nil {
+		log.Printf("Error saving audio: %v", err)
+		utils.Error(c, http.StatusInternalServerError, utils.CodeInternal, "failed to save audio file")
+		return
+	}
+
+	completeUpload(c, recordingID, idempotencyKey, contentHash)
+}
+
+// stripDataURIPrefix removes a "data:audio/...;base64," prefix if the client sent a data URI
+func stripDataURIPrefix(payload string) string {
+	if strings.HasPrefix(payload, "data:") {
+		if idx := strings.Index(payload, ","); idx != -1 {
+			return payload[idx+1:]
+		}
+	}
+	return payload
+}
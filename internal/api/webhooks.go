@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+	"noteme/internal/events"
+	"noteme/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateWebhookRequest subscribes a URL to event types
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events"` // transcription.completed, analysis.completed; empty = all
+}
+
+// createWebhook handles POST /api/v1/webhooks. The webhook receives only the caller's events.
+func createWebhook(c *gin.Context) {
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, "url is required")
+		return
+	}
+
+	sub, err := events.Subscribe(requestUserID(c).String(), req.URL, req.Events)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, utils.CodeInvalidRequest, err.Error())
+		return
+	}
+	utils.SuccessWithStatus(c, http.StatusCreated, gin.H{"webhook": sub})
+}
+
+// listWebhooks handles GET /api/v1/webhooks
+func listWebhooks(c *gin.Context) {
+	utils.Success(c, gin.H{
+		"webhooks":    events.ListSubscriptions(requestUserID(c).String()),
+		"event_types": events.Types,
+	})
+}
+
+// deleteWebhook handles DELETE /api/v1/webhooks/:id
+func deleteWebhook(c *gin.Context) {
+	id := c.Param("id")
+	if !events.Unsubscribe(requestUserID(c).String(), id) {
+		utils.Error(c, http.StatusNotFound, utils.CodeNotFound, "webhook not found")
+		return
+	}
+	utils.Success(c, gin.H{"id": id, "status": "deleted"})
+}
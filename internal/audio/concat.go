@@ -0,0 +1,114 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// concatFilter resamples both inputs to 16kHz mono before joining them, so clips recorded
+// with different codecs or settings can be concatenated
+const concatFilter = "[0:a]aresample=16000,aformat=channel_layouts=mono[a0];" +
+	"[1:a]aresample=16000,aformat=channel_layouts=mono[a1];" +
+	"[a0][a1]concat=n=2:v=0:a=1[out]"
+
+// ConcatAudio appends the audio of second to first and returns the path of the joined file,
+// written next to first as <first without extension>.concat<ext>; the caller moves or removes it.
+// Files with the same container, codec, sample rate and channels are joined without re-encoding
+// and keep their extension. Anything else is transcoded to 16kHz mono AAC (.m4a).
+func ConcatAudio(ctx context.Context, first, second string) (string, error) {
+	base := strings.TrimSuffix(first, filepath.Ext(first)) + ".concat"
+
+	if compatibleStreams(ctx, first, second) {
+		outputPath := base + filepath.Ext(first)
+		err := concatCopy(ctx, first, second, outputPath)
+		if err == nil {
+			return outputPath, nil
+		}
+		if errors.Is(err, ErrFFmpegUnavailable) {
+			return "", err
+		}
+		// The demuxer is picky about stream parameters ffprobe does not report; re-encode instead
+		os.Remove(outputPath)
+	}
+
+	outputPath := base + ".m4a"
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-hide_banner", "-v", "error", "-i", first, "-i", second,
+		"-filter_complex", concatFilter, "-map", "[out]", "-vn",
+		"-c:a", "aac", "-b:a", "64k", "-y", outputPath)
+	if err := runConcat(cmd, outputPath); err != nil {
+		return "", err
+	}
+	return outputPath, nil
+}
+
+// compatibleStreams reports whether two files can be joined by copying their audio streams
+func compatibleStreams(ctx context.Context, first, second string) bool {
+	if !strings.EqualFold(filepath.Ext(first), filepath.Ext(second)) {
+		return false
+	}
+	a, err := ProbeStreams(ctx, first)
+	if err != nil {
+		return false
+	}
+	b, err := ProbeStreams(ctx, second)
+	if err != nil {
+		return false
+	}
+	return *a == *b
+}
+
+// concatCopy joins two files with the concat demuxer without re-encoding
+func concatCopy(ctx context.Context, first, second, outputPath string) error {
+	list, err := os.CreateTemp(filepath.Dir(outputPath), "concat-*.txt")
+	if err != nil {
+		return fmt.Errorf("failed to create concat list: %w", err)
+	}
+	defer os.Remove(list.Name())
+
+	for _, path := range []string{first, second} {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			list.Close()
+			return err
+		}
+		// Single quotes inside a quoted path are written as '\''
+		fmt.Fprintf(list, "file '%s'\n", strings.ReplaceAll(abs, "'", `'\''`))
+	}
+	if err := list.Close(); err != nil {
+		return fmt.Errorf("failed to write concat list: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-hide_banner", "-v", "error",
+		"-f", "concat", "-safe", "0", "-i", list.Name(), "-vn", "-c", "copy", "-y", outputPath)
+	return runConcat(cmd, outputPath)
+}
+
+// runConcat runs an ffmpeg concat command and checks its output
+func runConcat(cmd *exec.Cmd, outputPath string) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		os.Remove(outputPath)
+		if errors.Is(err, exec.ErrNotFound) {
+			return ErrFFmpegUnavailable
+		}
+		return fmt.Errorf("ffmpeg concatenation failed: %w, stderr: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	info, err := os.Stat(outputPath)
+	if err != nil {
+		return fmt.Errorf("concatenated file not found: %w", err)
+	}
+	if err := CheckSize(info.Size()); err != nil {
+		os.Remove(outputPath)
+		return fmt.Errorf("concatenated file rejected, concatenation may have failed: %w", err)
+	}
+	return nil
+}
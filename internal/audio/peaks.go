@@ -0,0 +1,113 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"strings"
+)
+
+const (
+	// peakSampleRate is the rate audio is decoded at for peaks; a waveform needs no more detail
+	peakSampleRate = 8000
+	// peakWindowSamples is the resolution of the raw peaks (10ms at peakSampleRate)
+	peakWindowSamples = 80
+)
+
+// ComputePeaks decodes the file with ffmpeg and returns up to buckets amplitude peaks in [0, 1],
+// each the loudest sample of its slice of the audio. Files shorter than buckets*10ms return fewer.
+func ComputePeaks(ctx context.Context, path string, buckets int) ([]float64, error) {
+	if buckets < 1 {
+		return nil, fmt.Errorf("buckets must be positive, got %d", buckets)
+	}
+
+	// Mono 16-bit PCM on stdout, so memory only holds one peak per window
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-hide_banner", "-v", "error", "-i", path,
+		"-vn", "-ac", "1", "-ar", fmt.Sprint(peakSampleRate), "-f", "s16le", "-")
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, ErrFFmpegUnavailable
+		}
+		return nil, fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+
+	windows, readErr := readWindowPeaks(bufio.NewReader(stdout))
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("ffmpeg decoding failed: %w, stderr: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if readErr != nil {
+		return nil, fmt.Errorf("failed to read decoded audio: %w", readErr)
+	}
+
+	return DownsamplePeaks(windows, buckets), nil
+}
+
+// readWindowPeaks returns the normalized peak of every peakWindowSamples samples of s16le audio
+func readWindowPeaks(r io.Reader) ([]float64, error) {
+	var windows []float64
+	var sample [2]byte
+	peak, count := 0.0, 0
+	for {
+		if _, err := io.ReadFull(r, sample[:]); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			return nil, err
+		}
+		amplitude := math.Abs(float64(int16(binary.LittleEndian.Uint16(sample[:])))) / 32768
+		if amplitude > peak {
+			peak = amplitude
+		}
+		if count++; count == peakWindowSamples {
+			windows = append(windows, peak)
+			peak, count = 0, 0
+		}
+	}
+	if count > 0 {
+		windows = append(windows, peak)
+	}
+	return windows, nil
+}
+
+// DownsamplePeaks reduces peaks to at most buckets values, keeping the maximum of each group.
+// Values are rounded to 3 decimals to keep responses and cached metadata small.
+func DownsamplePeaks(peaks []float64, buckets int) []float64 {
+	if buckets >= len(peaks) {
+		out := make([]float64, len(peaks))
+		for i, p := range peaks {
+			out[i] = roundPeak(p)
+		}
+		return out
+	}
+
+	out := make([]float64, buckets)
+	for i := range out {
+		start := i * len(peaks) / buckets
+		end := (i + 1) * len(peaks) / buckets
+		max := 0.0
+		for _, p := range peaks[start:end] {
+			if p > max {
+				max = p
+			}
+		}
+		out[i] = roundPeak(max)
+	}
+	return out
+}
+
+func roundPeak(p float64) float64 {
+	return math.Round(p*1000) / 1000
+}
@@ -0,0 +1,125 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrDurationUnknown is returned by ProbeDuration when the container does not record its duration
+var ErrDurationUnknown = errors.New("audio duration is not recorded in the file")
+
+// ProbeDuration returns the duration of an audio file in seconds.
+// It uses ffprobe when available and falls back to parsing the header for WAV files.
+// Other files fail with ErrProbeUnavailable when ffprobe is not installed.
+func ProbeDuration(path string) (float64, error) {
+	duration, err := probeDurationFFprobe(path)
+	if err == nil {
+		return duration, nil
+	}
+
+	// ffprobe may not be installed (e.g. local development), WAV can still be parsed by hand
+	if strings.ToLower(filepath.Ext(path)) == ".wav" {
+		if wavDuration, wavErr := probeDurationWAV(path); wavErr == nil {
+			return wavDuration, nil
+		}
+	}
+
+	return 0, err
+}
+
+// probeDurationFFprobe reads the container duration using ffprobe
+func probeDurationFFprobe(path string) (float64, error) {
+	cmd := exec.Command("ffprobe",
+		"-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		path,
+	)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return 0, ErrProbeUnavailable
+		}
+		return 0, fmt.Errorf("ffprobe failed: %w, stderr: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	output := strings.TrimSpace(stdout.String())
+	if output == "" || output == "N/A" {
+		// e.g. streamed WebM without a duration in its header
+		return 0, ErrDurationUnknown
+	}
+	duration, err := strconv.ParseFloat(output, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse ffprobe duration %q: %w", output, err)
+	}
+
+	return duration, nil
+}
+
+// probeDurationWAV computes duration from the RIFF/WAVE header (data size / byte rate)
+func probeDurationWAV(path string) (float64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var riff [12]byte
+	if _, err := io.ReadFull(f, riff[:]); err != nil {
+		return 0, fmt.Errorf("failed to read WAV header: %w", err)
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return 0, fmt.Errorf("not a RIFF/WAVE file")
+	}
+
+	var byteRate uint32
+	for {
+		var chunkHeader [8]byte
+		if _, err := io.ReadFull(f, chunkHeader[:]); err != nil {
+			return 0, fmt.Errorf("data chunk not found: %w", err)
+		}
+		chunkID := string(chunkHeader[0:4])
+		chunkSize := binary.LittleEndian.Uint32(chunkHeader[4:8])
+
+		switch chunkID {
+		case "fmt ":
+			fmtChunk := make([]byte, chunkSize)
+			if _, err := io.ReadFull(f, fmtChunk); err != nil {
+				return 0, fmt.Errorf("failed to read fmt chunk: %w", err)
+			}
+			if len(fmtChunk) < 12 {
+				return 0, fmt.Errorf("fmt chunk too small")
+			}
+			byteRate = binary.LittleEndian.Uint32(fmtChunk[8:12])
+		case "data":
+			if byteRate == 0 {
+				return 0, fmt.Errorf("byte rate missing or zero")
+			}
+			return float64(chunkSize) / float64(byteRate), nil
+		default:
+			// Skip unknown chunk (chunks are padded to even size)
+			skip := int64(chunkSize) + int64(chunkSize%2)
+			if _, err := f.Seek(skip, io.SeekCurrent); err != nil {
+				return 0, err
+			}
+		}
+
+		if chunkID == "fmt " && chunkSize%2 == 1 {
+			if _, err := f.Seek(1, io.SeekCurrent); err != nil {
+				return 0, err
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package audio
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SilenceReport summarizes ffmpeg silencedetect output for an audio file
+type SilenceReport struct {
+	Duration      float64 // total duration in seconds
+	SilentSeconds float64 // total detected silence in seconds
+}
+
+// SpeechSeconds returns the amount of non-silent audio in seconds
+func (r *SilenceReport) SpeechSeconds() float64 {
+	speech := r.Duration - r.SilentSeconds
+	if speech < 0 {
+		return 0
+	}
+	return speech
+}
+
+var (
+	durationRe        = regexp.MustCompile(`Duration:\s*(\d+):(\d+):(\d+(?:\.\d+)?)`)
+	silenceStartRe    = regexp.MustCompile(`silence_start:\s*(-?\d+(?:\.\d+)?)`)
+	silenceDurationRe = regexp.MustCompile(`silence_duration:\s*(\d+(?:\.\d+)?)`)
+)
+
+// DetectSilence runs ffmpeg's silencedetect filter over the file.
+// thresholdDB is the noise floor (e.g. -50) below which audio is treated as silence.
+func DetectSilence(path string, thresholdDB float64) (*SilenceReport, error) {
+	filter := fmt.Sprintf("silencedetect=noise=%gdB:d=0.5", thresholdDB)
+	cmd := exec.Command("ffmpeg", "-hide_banner", "-nostats", "-i", path, "-af", filter, "-f", "null", "-")
+
+	// silencedetect writes its report to stderr
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg silencedetect failed: %w, stderr: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return parseSilenceOutput(stderr.String())
+}
+
+// parseSilenceOutput extracts total duration and silence from ffmpeg stderr
+func parseSilenceOutput(output string) (*SilenceReport, error) {
+	report := &SilenceReport{}
+
+	match := durationRe.FindStringSubmatch(output)
+	if match == nil {
+		return nil, fmt.Errorf("duration not found in ffmpeg output")
+	}
+	hours, _ := strconv.ParseFloat(match[1], 64)
+	minutes, _ := strconv.ParseFloat(match[2], 64)
+	seconds, _ := strconv.ParseFloat(match[3], 64)
+	report.Duration = hours*3600 + minutes*60 + seconds
+
+	openStart := -1.0
+	for _, line := range strings.Split(output, "\n") {
+		if m := silenceStartRe.FindStringSubmatch(line); m != nil {
+			openStart, _ = strconv.ParseFloat(m[1], 64)
+			continue
+		}
+		if m := silenceDurationRe.FindStringSubmatch(line); m != nil {
+			d, _ := strconv.ParseFloat(m[1], 64)
+			report.SilentSeconds += d
+			openStart = -1
+		}
+	}
+
+	// Older ffmpeg builds do not close a silence that runs until the end of the file
+	if openStart >= 0 && openStart < report.Duration {
+		report.SilentSeconds += report.Duration - openStart
+	}
+
+	return report, nil
+}
@@ -0,0 +1,34 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DefaultMinBytes is the smallest audio file accepted until SetMinBytes is called
+const DefaultMinBytes = 1000
+
+// minAudioBytes is MIN_AUDIO_BYTES, applied at startup with SetMinBytes
+var minAudioBytes int64 = DefaultMinBytes
+
+// ErrTooSmall is returned by CheckSize for files below MIN_AUDIO_BYTES
+var ErrTooSmall = errors.New("audio file too small, may be empty or corrupted")
+
+// SetMinBytes sets the smallest audio file accepted (MIN_AUDIO_BYTES, 0 disables the size check)
+func SetMinBytes(n int64) {
+	minAudioBytes = n
+}
+
+// MinBytes returns the smallest audio file accepted (default 1000, 0 disables the size check)
+func MinBytes() int64 {
+	return minAudioBytes
+}
+
+// CheckSize returns ErrTooSmall when size is below MinBytes. It is only a cheap first filter:
+// callers that can afford ffprobe should also check the duration (see ProbeDuration).
+func CheckSize(size int64) error {
+	if minBytes := MinBytes(); size < minBytes {
+		return fmt.Errorf("%w (%d bytes, minimum %d)", ErrTooSmall, size, minBytes)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package audio
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckSize(t *testing.T) {
+	defer SetMinBytes(MinBytes())
+
+	tests := []struct {
+		name     string
+		minBytes int64
+		size     int64
+		wantErr  bool
+	}{
+		{"below the default threshold", DefaultMinBytes, DefaultMinBytes - 1, true},
+		{"at the default threshold", DefaultMinBytes, DefaultMinBytes, false},
+		{"above the default threshold", DefaultMinBytes, DefaultMinBytes + 1, false},
+		{"empty file", DefaultMinBytes, 0, true},
+		{"below a configured threshold", 4096, 4095, true},
+		{"at a configured threshold", 4096, 4096, false},
+		{"small clip with a lowered threshold", 100, 500, false},
+		{"check disabled with 0", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetMinBytes(tt.minBytes)
+			err := CheckSize(tt.size)
+			if tt.wantErr {
+				if !errors.Is(err, ErrTooSmall) {
+					t.Errorf("CheckSize(%d) with MIN_AUDIO_BYTES=%d = %v, want ErrTooSmall", tt.size, tt.minBytes, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("CheckSize(%d) with MIN_AUDIO_BYTES=%d = %v, want nil", tt.size, tt.minBytes, err)
+			}
+		})
+	}
+}
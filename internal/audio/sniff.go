@@ -0,0 +1,104 @@
+package audio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// sniffHeaderSize is how many leading bytes SniffMatchesExtension reads
+const sniffHeaderSize = 64
+
+// signatureCheck reports whether a file header belongs to a container family
+type signatureCheck func(header []byte) bool
+
+func hasPrefix(prefix string) signatureCheck {
+	return func(header []byte) bool { return bytes.HasPrefix(header, []byte(prefix)) }
+}
+
+func isRIFFWave(header []byte) bool {
+	return len(header) >= 12 && string(header[0:4]) == "RIFF" && string(header[8:12]) == "WAVE"
+}
+
+func isAIFF(header []byte) bool {
+	return len(header) >= 12 && string(header[0:4]) == "FORM" && (string(header[8:12]) == "AIFF" || string(header[8:12]) == "AIFC")
+}
+
+// isISOBaseMedia matches MP4/M4A/3GP files, which carry "ftyp" at offset 4
+func isISOBaseMedia(header []byte) bool {
+	return len(header) >= 8 && string(header[4:8]) == "ftyp"
+}
+
+// isMPEGAudioFrame matches an MP3 frame sync (11 set bits, layer != reserved)
+func isMPEGAudioFrame(header []byte) bool {
+	return len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0 && header[1]&0x06 != 0
+}
+
+// isADTS matches a raw AAC ADTS frame (12-bit sync, layer 00)
+func isADTS(header []byte) bool {
+	return len(header) >= 2 && header[0] == 0xFF && header[1]&0xF6 == 0xF0
+}
+
+var (
+	isID3  = hasPrefix("ID3")
+	isOgg  = hasPrefix("OggS")
+	isFLAC = hasPrefix("fLaC")
+	isCAF  = hasPrefix("caff")
+	isAMR  = hasPrefix("#!AMR")
+	isEBML = hasPrefix("\x1A\x45\xDF\xA3")                 // WebM/Matroska
+	isASF  = hasPrefix("\x30\x26\xB2\x75\x8E\x66\xCF\x11") // WMA
+)
+
+// extensionSignatures lists the headers accepted for each upload extension.
+// Families are a little lenient where apps commonly mislabel (e.g. MP4 audio saved as .aac).
+var extensionSignatures = map[string][]signatureCheck{
+	".wav":  {isRIFFWave},
+	".aiff": {isAIFF},
+	".aif":  {isAIFF},
+	".mp3":  {isID3, isMPEGAudioFrame},
+	".aac":  {isADTS, isID3, hasPrefix("ADIF"), isISOBaseMedia},
+	".m4a":  {isISOBaseMedia},
+	".mp4":  {isISOBaseMedia},
+	".3gp":  {isISOBaseMedia},
+	".3gpp": {isISOBaseMedia},
+	".ogg":  {isOgg},
+	".opus": {isOgg},
+	".flac": {isFLAC, isID3},
+	".caf":  {isCAF},
+	".amr":  {isAMR},
+	".webm": {isEBML},
+	".wma":  {isASF},
+}
+
+// HeaderMatchesExtension reports whether header (the first bytes of a file) looks like the
+// container its extension claims. Extensions without a known signature always match.
+func HeaderMatchesExtension(ext string, header []byte) bool {
+	checks, ok := extensionSignatures[strings.ToLower(ext)]
+	if !ok {
+		return true
+	}
+	for _, check := range checks {
+		if check(header) {
+			return true
+		}
+	}
+	return false
+}
+
+// SniffMatchesExtension reads the start of a file and checks it with HeaderMatchesExtension
+func SniffMatchesExtension(path string, ext string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	header := make([]byte, sniffHeaderSize)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false, fmt.Errorf("failed to read file header: %w", err)
+	}
+	return HeaderMatchesExtension(ext, header[:n]), nil
+}
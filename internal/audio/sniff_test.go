@@ -0,0 +1,53 @@
+package audio
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHeaderMatchesExtension(t *testing.T) {
+	text := []byte("hello, this is a text file renamed to look like audio")
+	tests := []struct {
+		name   string
+		ext    string
+		header []byte
+		want   bool
+	}{
+		{"mp3 with ID3 tag", ".mp3", []byte("ID3\x04\x00\x00\x00\x00\x00\x00"), true},
+		{"mp3 frame sync", ".mp3", []byte{0xFF, 0xFB, 0x90, 0x64}, true},
+		{"mp3 upper-case extension", ".MP3", []byte("ID3\x03"), true},
+		{"text renamed to mp3", ".mp3", text, false},
+		{"wav header renamed to mp3", ".mp3", []byte("RIFF\x24\x00\x00\x00WAVEfmt "), false},
+		{"empty mp3", ".mp3", nil, false},
+		{"wav", ".wav", []byte("RIFF\x24\x00\x00\x00WAVEfmt "), true},
+		{"riff but not wave", ".wav", []byte("RIFF\x24\x00\x00\x00AVI LIST"), false},
+		{"m4a ftyp box", ".m4a", []byte("\x00\x00\x00\x20ftypM4A "), true},
+		{"text renamed to m4a", ".m4a", text, false},
+		{"ogg", ".ogg", []byte("OggS\x00\x02"), true},
+		{"unknown extension always matches", ".xyz", text, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HeaderMatchesExtension(tt.ext, tt.header); got != tt.want {
+				t.Errorf("HeaderMatchesExtension(%q, %q) = %v, want %v", tt.ext, tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSniffMatchesExtensionRejectsSpoofedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "song.mp3")
+	if err := os.WriteFile(path, []byte(strings.Repeat("not audio ", 200)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	matches, err := SniffMatchesExtension(path, ".mp3")
+	if err != nil {
+		t.Fatalf("SniffMatchesExtension: %v", err)
+	}
+	if matches {
+		t.Error("SniffMatchesExtension accepted text bytes named .mp3")
+	}
+}
@@ -0,0 +1,138 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+var (
+	// ErrNotAudio is returned when ffprobe finds no audio stream in a file
+	ErrNotAudio = errors.New("file does not contain an audio stream")
+	// ErrProbeUnavailable is returned when ffprobe is not installed
+	ErrProbeUnavailable = errors.New("ffprobe is not available")
+	// ErrFFmpegUnavailable is returned when ffmpeg is not installed
+	ErrFFmpegUnavailable = errors.New("ffmpeg is not available")
+)
+
+// StreamInfo describes a file as reported by ffprobe
+type StreamInfo struct {
+	FormatName string // container, e.g. "mov,mp4,m4a,3gp,3g2,mj2" or "amr"
+	AudioCodec string // codec of the first audio stream, e.g. "aac", "amr_nb"
+	SampleRate string // sample rate of the first audio stream in Hz, e.g. "44100"
+	Channels   int    // channel count of the first audio stream
+}
+
+// ffprobeOutput is the subset of `ffprobe -of json` used by ProbeStreams
+type ffprobeOutput struct {
+	Streams []struct {
+		CodecType  string `json:"codec_type"`
+		CodecName  string `json:"codec_name"`
+		SampleRate string `json:"sample_rate"`
+		Channels   int    `json:"channels"`
+	} `json:"streams"`
+	Format struct {
+		FormatName string `json:"format_name"`
+	} `json:"format"`
+}
+
+// ProbeStreams inspects a file with ffprobe and returns its audio stream.
+// It returns ErrNotAudio when ffprobe cannot read the file or it has no audio stream,
+// and ErrProbeUnavailable when ffprobe is not installed.
+func ProbeStreams(ctx context.Context, path string) (*StreamInfo, error) {
+	cmd := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-show_entries", "stream=codec_type,codec_name,sample_rate,channels:format=format_name",
+		"-of", "json",
+		path,
+	)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, ErrProbeUnavailable
+		}
+		// ffprobe exits non-zero for files it cannot demux at all
+		return nil, fmt.Errorf("%w: %s", ErrNotAudio, strings.TrimSpace(stderr.String()))
+	}
+
+	var output ffprobeOutput
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	for _, stream := range output.Streams {
+		if stream.CodecType == "audio" {
+			return &StreamInfo{
+				FormatName: output.Format.FormatName,
+				AudioCodec: stream.CodecName,
+				SampleRate: stream.SampleRate,
+				Channels:   stream.Channels,
+			}, nil
+		}
+	}
+	return nil, ErrNotAudio
+}
+
+// ConvertToWAV transcodes any format ffmpeg can decode to mono 16-bit PCM WAV (LINEAR16).
+// The output is written next to the input as <input>.converted.wav; the caller removes it.
+// It returns ErrFFmpegUnavailable when ffmpeg is not installed.
+func ConvertToWAV(ctx context.Context, inputPath string, sampleRate int) (string, error) {
+	outputPath := inputPath + ".converted.wav"
+
+	// -vn drops video/cover-art streams (e.g. .3gp or .mp4 recordings)
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-hide_banner", "-i", inputPath,
+		"-vn", "-acodec", "pcm_s16le", "-ar", fmt.Sprint(sampleRate), "-ac", "1", "-y", outputPath)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		os.Remove(outputPath)
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", ErrFFmpegUnavailable
+		}
+		return "", fmt.Errorf("ffmpeg conversion to WAV failed: %w, stderr: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	// Verify output file exists and is not empty
+	info, err := os.Stat(outputPath)
+	if err != nil {
+		return "", fmt.Errorf("converted file not found: %w", err)
+	}
+	if err := CheckSize(info.Size()); err != nil {
+		os.Remove(outputPath)
+		return "", fmt.Errorf("converted file rejected, conversion may have failed: %w", err)
+	}
+	return outputPath, nil
+}
+
+// ExtractSample writes the first seconds of a file as mono 16kHz PCM WAV, e.g. to detect its language
+// cheaply before transcribing the whole recording. The output is written next to the input as
+// <input>.sample.wav; the caller removes it. It returns ErrFFmpegUnavailable when ffmpeg is not installed.
+func ExtractSample(ctx context.Context, inputPath string, seconds int) (string, error) {
+	outputPath := inputPath + ".sample.wav"
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-hide_banner", "-v", "error", "-i", inputPath,
+		"-t", fmt.Sprint(seconds), "-vn", "-acodec", "pcm_s16le", "-ar", "16000", "-ac", "1", "-y", outputPath)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		os.Remove(outputPath)
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", ErrFFmpegUnavailable
+		}
+		return "", fmt.Errorf("ffmpeg sample extraction failed: %w, stderr: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return outputPath, nil
+}
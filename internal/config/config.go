@@ -2,57 +2,632 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Default CORS settings; PATCH is needed by the title/tags update endpoints
+const (
+	defaultCORSAllowMethods  = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+	defaultCORSAllowHeaders  = "Content-Type, Content-Length, Content-Encoding, Content-Range, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-API-Key, X-Admin-Token, Idempotency-Key"
+	defaultCORSExposeHeaders = "Upload-Offset, Retry-After"
 )
 
+// defaultSTTProviderOrder is the fallback chain used when STT_PROVIDER is not set:
+// the first provider with credentials is selected
+const defaultSTTProviderOrder = "fpt,google"
+
+// sttProviders are the single provider names known to the STT factory
+var sttProviders = []string{"fpt", "google", "mock"}
+
+// googleSTTAuthModes and googleSTTModels are the values the Google provider accepts
+// (see stt.DetectGoogleAuthMode and stt.GoogleModel*)
+var (
+	googleSTTAuthModes = []string{"apikey", "service_account", "adc"}
+	googleSTTModels    = []string{"latest_long", "latest_short", "phone_call", "video", "command_and_search",
+		"default", "medical_dictation", "medical_conversation", "auto"}
+)
+
+// Config holds every setting read from the environment. Load reads and validates it once
+// at startup; packages get it through their Configure function instead of calling os.Getenv.
 type Config struct {
 	Port               string
-	FPTApiKey          string
-	FPTSTTURL          string
-	OpenAIKey          string
-	STTProvider        string
-	GoogleSTTProjectID string
-	GoogleSTTKeyFile   string
-	DatabaseURL         string
+	ShutdownTimeout    time.Duration // grace period for draining requests on SIGINT/SIGTERM
+	CORSAllowMethods   []string      // CORS_ALLOW_METHODS, comma-separated
+	CORSAllowHeaders   []string      // CORS_ALLOW_HEADERS, comma-separated
+	CORSExposeHeaders  []string      // CORS_EXPOSE_HEADERS, comma-separated
+	EnableGzip         bool          // ENABLE_GZIP: gzip responses for clients that accept it (default true)
+	DefaultPageSize    int           // DEFAULT_PAGE_SIZE: history/search page size when ?limit= is missing (default 20)
+	MaxPageSize        int           // MAX_PAGE_SIZE: largest ?limit= accepted, larger values are capped (default 100)
+	MaxBodyBytes       int64         // MAX_BODY_SIZE_MB: request body limit for all routes except uploads (default 2MB)
+	MaxUploadBodyBytes int64         // MAX_UPLOAD_BODY_SIZE_MB: request body limit for audio upload routes (default 40MB)
+
+	// Auth and limits
+	AdminToken           string            // ADMIN_TOKEN: enables the admin endpoints, sent as X-Admin-Token
+	DefaultUserID        string            // DEFAULT_USER_ID: user of unauthenticated requests when REQUIRE_AUTH=false, empty = built-in MVP user
+	APIKeys              map[string]string // API_KEYS: "key1:user-uuid,key2:user-uuid", key -> user ID
+	JWTSecret            string            // JWT_SECRET: HS256 key for Authorization: Bearer tokens, empty disables JWT auth
+	JWTIssuer            string            // JWT_ISSUER: required iss claim, empty = not checked
+	JWTAudience          string            // JWT_AUDIENCE: required aud claim, empty = not checked
+	RequireAuth          bool              // REQUIRE_AUTH: reject requests without a valid API key or JWT (default true)
+	AIRateLimitPerMin    int               // AI_RATE_LIMIT_PER_MIN: AI requests per user and minute, 0 disables (default 20)
+	AIBatchConcurrency   int               // AI_BATCH_CONCURRENCY: analyses run in parallel by batch endpoints (default 3)
+	AskMaxAnalyses       int               // ASK_MAX_ANALYSES: analyses sent to Ask Anything (default 20)
+	EnableDebugEndpoints bool              // ENABLE_DEBUG_ENDPOINTS: admin-only diagnostics (default false)
+
+	// Audio checks and retention
+	UploadDir                  string        // UPLOAD_DIR: where uploaded audio is stored, must be writable (default uploads)
+	MinAudioBytes              int64         // MIN_AUDIO_BYTES: smallest accepted upload, 0 disables (default 1000)
+	MinAudioDurationSeconds    float64       // MIN_AUDIO_DURATION_SECONDS: shorter audio is rejected (default 1)
+	SilenceThresholdDB         float64       // SILENCE_THRESHOLD_DB: audio whose peak stays below is silent (default -50)
+	MinConfidence              float64       // MIN_CONFIDENCE: flag transcripts below this STT confidence, 0 disables (default 0)
+	CleanSkipConfidence        float64       // CLEAN_SKIP_CONFIDENCE: skip AI cleaning at or above this confidence, 0 disables (default 0)
+	LowConfidenceSkipAI        bool          // LOW_CONFIDENCE_SKIP_AI: no AI cleaning/analysis for low-confidence transcripts (default false)
+	DeleteAudioAfterProcessing bool          // DELETE_AUDIO_AFTER_PROCESSING: keep only transcripts (default false)
+	RetentionDays              int           // RETENTION_DAYS: delete recordings older than this, 0 = keep forever (default)
+	RetentionPurge             bool          // RETENTION_PURGE: permanently delete expired rows instead of soft deleting (default false)
+	RetentionInterval          time.Duration // RETENTION_INTERVAL: how often the retention job runs (default 1h)
+	StorageMaxEntries          int           // STORAGE_MAX_ENTRIES: in-memory recordings kept before eviction, 0 disables (default 5000)
+	ResumableUploadTTL         time.Duration // RESUMABLE_UPLOAD_TTL: idle resumable uploads expire after this (default 24h)
+
+	// Speech-to-text
+	STTProvider          string        // STT_PROVIDER: fpt, google, mock or best:<p1>,<p2>; resolved from STTProviderOrder when unset
+	STTProviderOrder     []string      // STT_PROVIDER_ORDER: providers tried in order when STT_PROVIDER is unset (default fpt,google)
+	STTLanguage          string        // STT_LANGUAGE: vi-VN (default), en-US or auto
+	STTHTTPTimeout       time.Duration // STT_HTTP_TIMEOUT: provider HTTP timeout (default 90s)
+	STTMaxConcurrent     int           // STT_MAX_CONCURRENT: transcriptions running at once, 0 = unlimited (default 4)
+	STTQueueTimeout      time.Duration // STT_QUEUE_TIMEOUT: how long a transcription waits for a slot (default 30s)
+	STTRetryMaxAttempts  int           // STT_RETRY_MAX_ATTEMPTS: attempts per provider call, 1 disables retries (default 3)
+	FPTApiKey            string
+	FPTSTTURL            string
+	FPTSTTHTTPTimeout    time.Duration // FPT_STT_HTTP_TIMEOUT: overrides STTHTTPTimeout for FPT, 0 = not set
+	GoogleSTTProjectID   string
+	GoogleSTTKeyFile     string
+	GoogleSTTAuthMode    string        // GOOGLE_STT_AUTH_MODE: apikey, service_account or adc, empty = auto-detect
+	GoogleSTTModel       string        // GOOGLE_STT_MODEL: recognition model (default latest_long)
+	GoogleSTTUseEnhanced bool          // GOOGLE_STT_USE_ENHANCED: use the enhanced model variant when available (default true)
+	GoogleSTTHTTPTimeout time.Duration // GOOGLE_STT_HTTP_TIMEOUT: overrides STTHTTPTimeout for Google, 0 = not set
+	MockSTTFixture       string        // MOCK_STT_FIXTURE: JSON fixture for STT_PROVIDER=mock, empty = built-in
+
+	// Asynchronous (callback-based) STT for long audio
+	STTAsyncProviders  []string      // STT_ASYNC_PROVIDERS: providers that transcribe via callback (fpt, mock), empty = all synchronous
+	STTCallbackBaseURL string        // STT_CALLBACK_BASE_URL: public base URL providers call back, e.g. https://api.example.com
+	STTCallbackSecret  string        // STT_CALLBACK_SECRET: signs callback URLs, required with STT_ASYNC_PROVIDERS
+	STTAsyncTimeout    time.Duration // STT_ASYNC_TIMEOUT: a job without callback after this can be submitted again (default 2h)
+	FPTSTTAsyncURL     string        // FPT_AI_STT_ASYNC_URL: FPT.AI endpoint accepting jobs with a callback_url, required for async fpt
+
+	// AI
+	OpenAIKey                   string
+	EnableAI                    bool          // ENABLE_AI: AI cleaning, analysis and Ask Anything (default true, needs OpenAIKey)
+	AIProvider                  string        // AI_PROVIDER: openai (default) or mock for offline cleaning/analysis
+	OpenAIModel                 string        // OPENAI_MODEL: chat model for cleaning, analysis and Ask (default gpt-4o-mini)
+	OpenAIEmbeddingModel        string        // OPENAI_EMBEDDING_MODEL: model for semantic search (default text-embedding-3-small)
+	OpenAITimeout               time.Duration // OPENAI_TIMEOUT: bound on each OpenAI call (default 60s)
+	AnalysisMaxTranscriptTokens int           // ANALYSIS_MAX_TRANSCRIPT_TOKENS: longer transcripts are chunked (default 24000)
+	AnalysisMaxChunks           int           // ANALYSIS_MAX_CHUNKS: OpenAI calls spent on one analysis (default 8)
+	AnalysisOversizeMode        string        // ANALYSIS_OVERSIZE_MODE: chunk (default) or truncate
+	DigestMaxContextTokens      int           // DIGEST_MAX_CONTEXT_TOKENS: larger digests run in chunks (default 12000)
+	AskTopK                     int           // ASK_TOP_K: analyses selected by similarity for Ask (default 5)
+	AskContextCacheTTL          time.Duration // ASK_CONTEXT_CACHE_TTL: how long built Ask contexts are reused (default 10m)
+	CleanPromptDir              string        // CLEAN_PROMPT_DIR: overrides of the cleaning prompt templates
+	CleanGlossary               []string      // CLEAN_GLOSSARY: comma-separated terms ("term" or "wrong=>right") added to the cleaning prompt
+	CleanGlossaryFile           string        // CLEAN_GLOSSARY_FILE: glossary file, one entry per line, "#" comments
+	GlossaryMaxEntries          int           // GLOSSARY_MAX_ENTRIES: cap on each user's glossary (default 200)
+	PromptExperiment            string        // PROMPT_EXPERIMENT: name of the running A/B prompt experiment, empty = none
+	PromptExperimentBDir        string        // PROMPT_EXPERIMENT_B_DIR: prompts of variant B, required with PROMPT_EXPERIMENT
+	PromptExperimentBPercent    int           // PROMPT_EXPERIMENT_B_PERCENT: share of recordings assigned to B (default 50)
+
+	// Database
+	DatabaseURL       string
+	DBMaxOpenConns    int           // DB_MAX_OPEN_CONNS (default 25)
+	DBMaxIdleConns    int           // DB_MAX_IDLE_CONNS (default 5)
+	DBConnMaxLifetime time.Duration // DB_CONN_MAX_LIFETIME, 0 = no limit (default 30m)
+	DBPingRetries     int           // DB_PING_RETRIES: startup pings before giving up (default 5)
+	SkipMigrations    bool          // SKIP_MIGRATIONS: manage the schema manually (default false)
+
+	// Integrations
+	WebhookSecret string // WEBHOOK_SECRET: signs webhook bodies, empty = unsigned
+	PDFFontPath   string // PDF_FONT_PATH: Unicode .ttf font for PDF exports
 }
 
-// Load loads configuration from environment variables
+// Default returns the configuration used for unset variables. Packages start from it
+// until their Configure is called, so they also work without Load (e.g. in tests).
+func Default() *Config {
+	return &Config{
+		Port:               "8080",
+		ShutdownTimeout:    30 * time.Second,
+		CORSAllowMethods:   splitList(defaultCORSAllowMethods),
+		CORSAllowHeaders:   splitList(defaultCORSAllowHeaders),
+		CORSExposeHeaders:  splitList(defaultCORSExposeHeaders),
+		EnableGzip:         true,
+		DefaultPageSize:    20,
+		MaxPageSize:        100,
+		MaxBodyBytes:       2 << 20,
+		MaxUploadBodyBytes: 40 << 20,
+
+		RequireAuth:        true,
+		AIRateLimitPerMin:  20,
+		AIBatchConcurrency: 3,
+		AskMaxAnalyses:     20,
+
+		UploadDir:               "uploads",
+		MinAudioBytes:           1000,
+		MinAudioDurationSeconds: 1,
+		SilenceThresholdDB:      -50,
+		RetentionInterval:       time.Hour,
+		StorageMaxEntries:       5000,
+		ResumableUploadTTL:      24 * time.Hour,
+
+		STTProvider:          "fpt",
+		STTProviderOrder:     splitList(defaultSTTProviderOrder),
+		STTLanguage:          "vi-VN",
+		STTHTTPTimeout:       90 * time.Second,
+		STTMaxConcurrent:     4,
+		STTQueueTimeout:      30 * time.Second,
+		STTRetryMaxAttempts:  3,
+		FPTSTTURL:            "https://api.fpt.ai/hmi/asr/v1",
+		GoogleSTTModel:       "latest_long",
+		GoogleSTTUseEnhanced: true,
+		STTAsyncTimeout:      2 * time.Hour,
+
+		EnableAI:                    true,
+		AIProvider:                  "openai",
+		OpenAIModel:                 "gpt-4o-mini",
+		OpenAIEmbeddingModel:        "text-embedding-3-small",
+		OpenAITimeout:               60 * time.Second,
+		AnalysisMaxTranscriptTokens: 24000,
+		AnalysisMaxChunks:           8,
+		AnalysisOversizeMode:        "chunk",
+		DigestMaxContextTokens:      12000,
+		AskTopK:                     5,
+		AskContextCacheTTL:          10 * time.Minute,
+		PromptExperimentBPercent:    50,
+		GlossaryMaxEntries:          200,
+
+		DBMaxOpenConns:    25,
+		DBMaxIdleConns:    5,
+		DBConnMaxLifetime: 30 * time.Minute,
+		DBPingRetries:     5,
+
+		PDFFontPath: "/usr/share/fonts/dejavu/DejaVuSans.ttf",
+	}
+}
+
+// Load loads configuration from environment variables, starting from Default
 func Load() (*Config, error) {
-	cfg := &Config{
-		Port:               getEnv("PORT", "8080"),
-		FPTApiKey:          os.Getenv("FPT_AI_API_KEY"),
-		FPTSTTURL:          getEnv("FPT_AI_STT_URL", "https://api.fpt.ai/hmi/asr/v1"),
-		OpenAIKey:          os.Getenv("OPENAI_API_KEY"),
-		STTProvider:        getEnv("STT_PROVIDER", "fpt"),
-		GoogleSTTProjectID: os.Getenv("GOOGLE_STT_PROJECT_ID"),
-		GoogleSTTKeyFile:   os.Getenv("GOOGLE_STT_KEY_FILE"),
-		DatabaseURL:         os.Getenv("DATABASE_URL"),
-	}
-
-	// Validate STT provider configuration
-	sttProvider := getEnv("STT_PROVIDER", "fpt")
-	if sttProvider == "fpt" {
+	cfg := Default()
+	if err := cfg.loadServer(); err != nil {
+		return nil, err
+	}
+	if err := cfg.loadAuth(); err != nil {
+		return nil, err
+	}
+	if err := cfg.loadAudio(); err != nil {
+		return nil, err
+	}
+	if err := cfg.loadSTT(); err != nil {
+		return nil, err
+	}
+	if err := cfg.loadAI(); err != nil {
+		return nil, err
+	}
+	if err := cfg.loadDatabase(); err != nil {
+		return nil, err
+	}
+
+	cfg.WebhookSecret = os.Getenv("WEBHOOK_SECRET")
+	cfg.PDFFontPath = getEnv("PDF_FONT_PATH", cfg.PDFFontPath)
+
+	return cfg, nil
+}
+
+func (cfg *Config) loadServer() error {
+	var err error
+	cfg.Port = getEnv("PORT", cfg.Port)
+	if cfg.ShutdownTimeout, err = envDuration("SHUTDOWN_TIMEOUT", cfg.ShutdownTimeout, false); err != nil {
+		return err
+	}
+
+	if v := os.Getenv("CORS_ALLOW_METHODS"); v != "" {
+		cfg.CORSAllowMethods = splitList(strings.ToUpper(v))
+	}
+	// Preflight requests must always be answered
+	if !contains(cfg.CORSAllowMethods, "OPTIONS") {
+		cfg.CORSAllowMethods = append(cfg.CORSAllowMethods, "OPTIONS")
+	}
+	if v := os.Getenv("CORS_ALLOW_HEADERS"); v != "" {
+		cfg.CORSAllowHeaders = splitList(v)
+	}
+	if v := os.Getenv("CORS_EXPOSE_HEADERS"); v != "" {
+		cfg.CORSExposeHeaders = splitList(v)
+	}
+
+	if cfg.EnableGzip, err = envBool("ENABLE_GZIP", cfg.EnableGzip); err != nil {
+		return err
+	}
+
+	if cfg.DefaultPageSize, err = envInt("DEFAULT_PAGE_SIZE", cfg.DefaultPageSize, 1); err != nil {
+		return err
+	}
+	if cfg.MaxPageSize, err = envInt("MAX_PAGE_SIZE", cfg.MaxPageSize, 1); err != nil {
+		return err
+	}
+	if cfg.MaxPageSize < cfg.DefaultPageSize {
+		return fmt.Errorf("MAX_PAGE_SIZE (%d) must not be smaller than DEFAULT_PAGE_SIZE (%d)", cfg.MaxPageSize, cfg.DefaultPageSize)
+	}
+
+	maxBodyMB, err := envInt("MAX_BODY_SIZE_MB", int(cfg.MaxBodyBytes>>20), 1)
+	if err != nil {
+		return err
+	}
+	cfg.MaxBodyBytes = int64(maxBodyMB) << 20
+
+	// Uploads carry the 25MB audio limit plus multipart/base64 overhead (base64 grows data by a third)
+	maxUploadBodyMB, err := envInt("MAX_UPLOAD_BODY_SIZE_MB", int(cfg.MaxUploadBodyBytes>>20), 1)
+	if err != nil {
+		return err
+	}
+	if maxUploadBodyMB < maxBodyMB {
+		return fmt.Errorf("MAX_UPLOAD_BODY_SIZE_MB (%d) must not be smaller than MAX_BODY_SIZE_MB (%d)", maxUploadBodyMB, maxBodyMB)
+	}
+	cfg.MaxUploadBodyBytes = int64(maxUploadBodyMB) << 20
+	return nil
+}
+
+func (cfg *Config) loadAuth() error {
+	var err error
+	cfg.AdminToken = os.Getenv("ADMIN_TOKEN")
+
+	if v := strings.TrimSpace(os.Getenv("DEFAULT_USER_ID")); v != "" {
+		if _, err := uuid.Parse(v); err != nil {
+			return fmt.Errorf("DEFAULT_USER_ID must be a UUID, got %q", v)
+		}
+		cfg.DefaultUserID = v
+	}
+	if cfg.APIKeys, err = parseAPIKeys(os.Getenv("API_KEYS")); err != nil {
+		return err
+	}
+	cfg.JWTSecret = os.Getenv("JWT_SECRET")
+	cfg.JWTIssuer = os.Getenv("JWT_ISSUER")
+	cfg.JWTAudience = os.Getenv("JWT_AUDIENCE")
+	if cfg.JWTSecret != "" && len(cfg.JWTSecret) < 32 {
+		return fmt.Errorf("JWT_SECRET must be at least 32 bytes")
+	}
+	if cfg.RequireAuth, err = envBool("REQUIRE_AUTH", cfg.RequireAuth); err != nil {
+		return err
+	}
+	if cfg.RequireAuth && len(cfg.APIKeys) == 0 && cfg.JWTSecret == "" {
+		return fmt.Errorf("REQUIRE_AUTH is true but neither API_KEYS nor JWT_SECRET is set (set REQUIRE_AUTH=false only for local development)")
+	}
+
+	if cfg.AIRateLimitPerMin, err = envInt("AI_RATE_LIMIT_PER_MIN", cfg.AIRateLimitPerMin, 0); err != nil {
+		return err
+	}
+	if cfg.AIBatchConcurrency, err = envInt("AI_BATCH_CONCURRENCY", cfg.AIBatchConcurrency, 1); err != nil {
+		return err
+	}
+	if cfg.AskMaxAnalyses, err = envInt("ASK_MAX_ANALYSES", cfg.AskMaxAnalyses, 1); err != nil {
+		return err
+	}
+	if cfg.EnableDebugEndpoints, err = envBool("ENABLE_DEBUG_ENDPOINTS", cfg.EnableDebugEndpoints); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cfg *Config) loadAudio() error {
+	cfg.UploadDir = getEnv("UPLOAD_DIR", cfg.UploadDir)
+
+	minBytes, err := envInt("MIN_AUDIO_BYTES", int(cfg.MinAudioBytes), 0)
+	if err != nil {
+		return err
+	}
+	cfg.MinAudioBytes = int64(minBytes)
+
+	if cfg.MinAudioDurationSeconds, err = envFloat("MIN_AUDIO_DURATION_SECONDS", cfg.MinAudioDurationSeconds, 0, 3600); err != nil {
+		return err
+	}
+	if cfg.SilenceThresholdDB, err = envFloat("SILENCE_THRESHOLD_DB", cfg.SilenceThresholdDB, -100, 0); err != nil {
+		return err
+	}
+	if cfg.MinConfidence, err = envFloat("MIN_CONFIDENCE", cfg.MinConfidence, 0, 1); err != nil {
+		return err
+	}
+	if cfg.CleanSkipConfidence, err = envFloat("CLEAN_SKIP_CONFIDENCE", cfg.CleanSkipConfidence, 0, 1); err != nil {
+		return err
+	}
+	if cfg.LowConfidenceSkipAI, err = envBool("LOW_CONFIDENCE_SKIP_AI", cfg.LowConfidenceSkipAI); err != nil {
+		return err
+	}
+	if cfg.DeleteAudioAfterProcessing, err = envBool("DELETE_AUDIO_AFTER_PROCESSING", cfg.DeleteAudioAfterProcessing); err != nil {
+		return err
+	}
+
+	if cfg.RetentionDays, err = envInt("RETENTION_DAYS", cfg.RetentionDays, 0); err != nil {
+		return err
+	}
+	if cfg.RetentionPurge, err = envBool("RETENTION_PURGE", cfg.RetentionPurge); err != nil {
+		return err
+	}
+	if cfg.RetentionInterval, err = envDuration("RETENTION_INTERVAL", cfg.RetentionInterval, false); err != nil {
+		return err
+	}
+	if cfg.StorageMaxEntries, err = envInt("STORAGE_MAX_ENTRIES", cfg.StorageMaxEntries, 0); err != nil {
+		return err
+	}
+	if cfg.ResumableUploadTTL, err = envDuration("RESUMABLE_UPLOAD_TTL", cfg.ResumableUploadTTL, false); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cfg *Config) loadSTT() error {
+	var err error
+	cfg.FPTApiKey = os.Getenv("FPT_AI_API_KEY")
+	cfg.FPTSTTURL = getEnv("FPT_AI_STT_URL", cfg.FPTSTTURL)
+	cfg.GoogleSTTProjectID = os.Getenv("GOOGLE_STT_PROJECT_ID")
+	cfg.GoogleSTTKeyFile = os.Getenv("GOOGLE_STT_KEY_FILE")
+	cfg.MockSTTFixture = os.Getenv("MOCK_STT_FIXTURE")
+
+	if v := strings.TrimSpace(os.Getenv("STT_LANGUAGE")); v != "" {
+		// stt.ResolveLanguage normalizes variants like "en" or "vi_VN"
+		code := strings.ToLower(strings.ReplaceAll(v, "_", "-"))
+		if code != "auto" && code != "vi" && code != "en" && !strings.HasPrefix(code, "vi-") && !strings.HasPrefix(code, "en-") {
+			return fmt.Errorf("STT_LANGUAGE must be vi-VN, en-US or auto, got %q", v)
+		}
+		cfg.STTLanguage = v
+	}
+
+	if cfg.STTHTTPTimeout, err = envDuration("STT_HTTP_TIMEOUT", cfg.STTHTTPTimeout, false); err != nil {
+		return err
+	}
+	if cfg.FPTSTTHTTPTimeout, err = envDuration("FPT_STT_HTTP_TIMEOUT", cfg.FPTSTTHTTPTimeout, false); err != nil {
+		return err
+	}
+	if cfg.GoogleSTTHTTPTimeout, err = envDuration("GOOGLE_STT_HTTP_TIMEOUT", cfg.GoogleSTTHTTPTimeout, false); err != nil {
+		return err
+	}
+	if cfg.STTMaxConcurrent, err = envInt("STT_MAX_CONCURRENT", cfg.STTMaxConcurrent, 0); err != nil {
+		return err
+	}
+	if cfg.STTQueueTimeout, err = envDuration("STT_QUEUE_TIMEOUT", cfg.STTQueueTimeout, false); err != nil {
+		return err
+	}
+	if cfg.STTRetryMaxAttempts, err = envInt("STT_RETRY_MAX_ATTEMPTS", cfg.STTRetryMaxAttempts, 1); err != nil {
+		return err
+	}
+
+	if cfg.GoogleSTTAuthMode, err = envChoice("GOOGLE_STT_AUTH_MODE", cfg.GoogleSTTAuthMode, googleSTTAuthModes); err != nil {
+		return err
+	}
+	if cfg.GoogleSTTModel, err = envChoice("GOOGLE_STT_MODEL", cfg.GoogleSTTModel, googleSTTModels); err != nil {
+		return err
+	}
+	if cfg.GoogleSTTUseEnhanced, err = envBool("GOOGLE_STT_USE_ENHANCED", cfg.GoogleSTTUseEnhanced); err != nil {
+		return err
+	}
+
+	// Select and validate the STT provider
+	if v := os.Getenv("STT_PROVIDER_ORDER"); v != "" {
+		cfg.STTProviderOrder = splitList(strings.ToLower(v))
+	}
+	if len(cfg.STTProviderOrder) == 0 {
+		return fmt.Errorf("STT_PROVIDER_ORDER must list at least one provider")
+	}
+	if err := cfg.loadAsyncSTT(); err != nil {
+		return err
+	}
+	for _, name := range cfg.STTProviderOrder {
+		if !contains(sttProviders, name) {
+			return fmt.Errorf("STT_PROVIDER_ORDER contains unsupported provider %q. Supported: %s", name, strings.Join(sttProviders, ", "))
+		}
+	}
+	cfg.STTProvider = strings.ToLower(strings.TrimSpace(os.Getenv("STT_PROVIDER")))
+	if cfg.STTProvider == "" {
+		cfg.STTProvider = cfg.firstConfiguredSTTProvider()
+	}
+	return cfg.validateSTTProvider()
+}
+
+func (cfg *Config) loadAI() error {
+	var err error
+	// OpenAI key is optional: without it the AI endpoints answer 503 AI_NOT_CONFIGURED
+	// (main logs a warning at startup when ENABLE_AI is on)
+	cfg.OpenAIKey = os.Getenv("OPENAI_API_KEY")
+	if cfg.EnableAI, err = envBool("ENABLE_AI", cfg.EnableAI); err != nil {
+		return err
+	}
+	if cfg.AIProvider, err = envChoice("AI_PROVIDER", cfg.AIProvider, []string{"openai", "mock"}); err != nil {
+		return err
+	}
+	cfg.OpenAIModel = getEnv("OPENAI_MODEL", cfg.OpenAIModel)
+	cfg.OpenAIEmbeddingModel = getEnv("OPENAI_EMBEDDING_MODEL", cfg.OpenAIEmbeddingModel)
+	if cfg.OpenAITimeout, err = envDuration("OPENAI_TIMEOUT", cfg.OpenAITimeout, false); err != nil {
+		return err
+	}
+
+	if cfg.AnalysisMaxTranscriptTokens, err = envInt("ANALYSIS_MAX_TRANSCRIPT_TOKENS", cfg.AnalysisMaxTranscriptTokens, 1); err != nil {
+		return err
+	}
+	if cfg.AnalysisMaxChunks, err = envInt("ANALYSIS_MAX_CHUNKS", cfg.AnalysisMaxChunks, 1); err != nil {
+		return err
+	}
+	if cfg.AnalysisOversizeMode, err = envChoice("ANALYSIS_OVERSIZE_MODE", cfg.AnalysisOversizeMode, []string{"chunk", "truncate"}); err != nil {
+		return err
+	}
+	if cfg.DigestMaxContextTokens, err = envInt("DIGEST_MAX_CONTEXT_TOKENS", cfg.DigestMaxContextTokens, 1); err != nil {
+		return err
+	}
+	if cfg.AskTopK, err = envInt("ASK_TOP_K", cfg.AskTopK, 1); err != nil {
+		return err
+	}
+	if cfg.AskContextCacheTTL, err = envDuration("ASK_CONTEXT_CACHE_TTL", cfg.AskContextCacheTTL, false); err != nil {
+		return err
+	}
+
+	cfg.CleanPromptDir = os.Getenv("CLEAN_PROMPT_DIR")
+	cfg.CleanGlossary = splitList(os.Getenv("CLEAN_GLOSSARY"))
+	cfg.CleanGlossaryFile = os.Getenv("CLEAN_GLOSSARY_FILE")
+	if cfg.GlossaryMaxEntries, err = envInt("GLOSSARY_MAX_ENTRIES", cfg.GlossaryMaxEntries, 1); err != nil {
+		return err
+	}
+	cfg.PromptExperiment = os.Getenv("PROMPT_EXPERIMENT")
+	cfg.PromptExperimentBDir = os.Getenv("PROMPT_EXPERIMENT_B_DIR")
+	if cfg.PromptExperiment != "" && cfg.PromptExperimentBDir == "" {
+		return fmt.Errorf("PROMPT_EXPERIMENT_B_DIR is required when PROMPT_EXPERIMENT is set")
+	}
+	if cfg.PromptExperimentBPercent, err = envInt("PROMPT_EXPERIMENT_B_PERCENT", cfg.PromptExperimentBPercent, 0); err != nil {
+		return err
+	}
+	if cfg.PromptExperimentBPercent > 100 {
+		return fmt.Errorf("PROMPT_EXPERIMENT_B_PERCENT must be between 0 and 100, got %d", cfg.PromptExperimentBPercent)
+	}
+	return nil
+}
+
+func (cfg *Config) loadDatabase() error {
+	var err error
+	cfg.DatabaseURL = os.Getenv("DATABASE_URL")
+	if cfg.DBMaxOpenConns, err = envInt("DB_MAX_OPEN_CONNS", cfg.DBMaxOpenConns, 1); err != nil {
+		return err
+	}
+	if cfg.DBMaxIdleConns, err = envInt("DB_MAX_IDLE_CONNS", cfg.DBMaxIdleConns, 0); err != nil {
+		return err
+	}
+	if cfg.DBConnMaxLifetime, err = envDuration("DB_CONN_MAX_LIFETIME", cfg.DBConnMaxLifetime, true); err != nil {
+		return err
+	}
+	if cfg.DBPingRetries, err = envInt("DB_PING_RETRIES", cfg.DBPingRetries, 1); err != nil {
+		return err
+	}
+	if cfg.SkipMigrations, err = envBool("SKIP_MIGRATIONS", cfg.SkipMigrations); err != nil {
+		return err
+	}
+	return nil
+}
+
+// STTHTTPTimeoutFor returns the HTTP timeout of an STT provider:
+// its <PROVIDER>_STT_HTTP_TIMEOUT when set, STT_HTTP_TIMEOUT otherwise
+func (cfg *Config) STTHTTPTimeoutFor(provider string) time.Duration {
+	switch {
+	case provider == "fpt" && cfg.FPTSTTHTTPTimeout > 0:
+		return cfg.FPTSTTHTTPTimeout
+	case provider == "google" && cfg.GoogleSTTHTTPTimeout > 0:
+		return cfg.GoogleSTTHTTPTimeout
+	}
+	return cfg.STTHTTPTimeout
+}
+
+// firstConfiguredSTTProvider returns the first provider in STTProviderOrder whose credentials
+// are set. When none is, the first one is returned so validation reports what it is missing.
+func (cfg *Config) firstConfiguredSTTProvider() string {
+	for _, name := range cfg.STTProviderOrder {
+		if cfg.sttCredentialsError(name) == nil {
+			return name
+		}
+	}
+	return cfg.STTProviderOrder[0]
+}
+
+// validateSTTProvider checks that STTProvider names known providers with credentials set,
+// including every provider of a best:<p1>,<p2> list
+func (cfg *Config) validateSTTProvider() error {
+	names := []string{cfg.STTProvider}
+	if list, ok := strings.CutPrefix(cfg.STTProvider, "best:"); ok {
+		names = nil
+		for _, name := range splitList(list) {
+			if !contains(names, name) {
+				names = append(names, name)
+			}
+		}
+		if len(names) < 2 {
+			return fmt.Errorf("STT_PROVIDER=best:<p1>,<p2> needs at least two distinct providers, got %q", cfg.STTProvider)
+		}
+	}
+
+	for _, name := range names {
+		if !contains(sttProviders, name) {
+			return fmt.Errorf("unsupported STT provider: %s. Supported: %s, best:<p1>,<p2>", name, strings.Join(sttProviders, ", "))
+		}
+		if err := cfg.sttCredentialsError(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// asyncSTTProviders are the providers that implement callback-based transcription
+var asyncSTTProviders = []string{"fpt", "mock"}
+
+// loadAsyncSTT reads the callback-based STT settings; async mode needs a public callback URL and a signing secret
+func (cfg *Config) loadAsyncSTT() error {
+	var err error
+	cfg.STTAsyncProviders = splitList(strings.ToLower(os.Getenv("STT_ASYNC_PROVIDERS")))
+	cfg.STTCallbackBaseURL = strings.TrimRight(os.Getenv("STT_CALLBACK_BASE_URL"), "/")
+	cfg.STTCallbackSecret = os.Getenv("STT_CALLBACK_SECRET")
+	cfg.FPTSTTAsyncURL = os.Getenv("FPT_AI_STT_ASYNC_URL")
+	if cfg.STTAsyncTimeout, err = envDuration("STT_ASYNC_TIMEOUT", cfg.STTAsyncTimeout, false); err != nil {
+		return err
+	}
+	if len(cfg.STTAsyncProviders) == 0 {
+		return nil
+	}
+
+	for _, name := range cfg.STTAsyncProviders {
+		if !contains(asyncSTTProviders, name) {
+			return fmt.Errorf("STT_ASYNC_PROVIDERS contains %q, which has no async mode. Supported: %s", name, strings.Join(asyncSTTProviders, ", "))
+		}
+	}
+	if contains(cfg.STTAsyncProviders, "fpt") && cfg.FPTSTTAsyncURL == "" {
+		return fmt.Errorf("FPT_AI_STT_ASYNC_URL is required when STT_ASYNC_PROVIDERS includes fpt")
+	}
+	if cfg.STTCallbackBaseURL == "" {
+		return fmt.Errorf("STT_CALLBACK_BASE_URL is required when STT_ASYNC_PROVIDERS is set")
+	}
+	if u, err := url.Parse(cfg.STTCallbackBaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("STT_CALLBACK_BASE_URL must be an http(s) URL, got %q", cfg.STTCallbackBaseURL)
+	}
+	if cfg.STTCallbackSecret == "" {
+		return fmt.Errorf("STT_CALLBACK_SECRET is required when STT_ASYNC_PROVIDERS is set")
+	}
+	return nil
+}
+
+// sttCredentialsError reports the first missing credential of a single STT provider
+func (cfg *Config) sttCredentialsError(name string) error {
+	switch name {
+	case "fpt":
 		if cfg.FPTApiKey == "" {
-			return nil, fmt.Errorf("FPT_AI_API_KEY is required when STT_PROVIDER=fpt. Please set it as environment variable:\n  Windows PowerShell: $env:FPT_AI_API_KEY=\"your_key\"\n  Windows CMD: set FPT_AI_API_KEY=your_key\n  Linux/Mac: export FPT_AI_API_KEY=\"your_key\"")
+			return fmt.Errorf("FPT_AI_API_KEY is required when STT_PROVIDER=fpt. Please set it as environment variable:\n  Windows PowerShell: $env:FPT_AI_API_KEY=\"your_key\"\n  Windows CMD: set FPT_AI_API_KEY=your_key\n  Linux/Mac: export FPT_AI_API_KEY=\"your_key\"")
 		}
-	} else if sttProvider == "google" {
+	case "google":
 		if cfg.GoogleSTTProjectID == "" {
-			return nil, fmt.Errorf("GOOGLE_STT_PROJECT_ID is required when STT_PROVIDER=google")
+			return fmt.Errorf("GOOGLE_STT_PROJECT_ID is required when STT_PROVIDER=google")
 		}
 		if cfg.GoogleSTTKeyFile == "" {
-			return nil, fmt.Errorf("GOOGLE_STT_KEY_FILE is required when STT_PROVIDER=google. It can be either:\n  - A file path (e.g., ./keys/google-service-account.json)\n  - A JSON string containing service account credentials")
+			return fmt.Errorf("GOOGLE_STT_KEY_FILE is required when STT_PROVIDER=google. It can be either:\n  - A file path (e.g., ./keys/google-service-account.json)\n  - A JSON string containing service account credentials")
 		}
 	}
-
-	// OpenAI key is optional (only needed for AI analysis)
-	// Will be validated when AI analysis is called
-
-	return cfg, nil
+	return nil
 }
 
-func getEnv(key, fallback string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
+// parseAPIKeys parses API_KEYS ("key1:user-uuid,key2:user-uuid") into key -> user ID
+func parseAPIKeys(value string) (map[string]string, error) {
+	keys := make(map[string]string)
+	for _, entry := range splitList(value) {
+		key, user, ok := strings.Cut(entry, ":")
+		key, user = strings.TrimSpace(key), strings.TrimSpace(user)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("API_KEYS entries must be key:user-uuid, got a malformed entry")
+		}
+		if _, err := uuid.Parse(user); err != nil {
+			return nil, fmt.Errorf("API_KEYS entries must be key:user-uuid, got an invalid user ID %q", user)
+		}
+		keys[key] = user
 	}
-	return fallback
+	return keys, nil
 }
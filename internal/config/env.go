@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// envBool reads a true/false variable, def when unset
+func envBool(key string, def bool) (bool, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false, got %q", key, v)
+	}
+	return b, nil
+}
+
+// envInt reads an integer variable of at least min, def when unset
+func envInt(key string, def, min int) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err == nil && n >= min {
+		return n, nil
+	}
+	switch min {
+	case 0:
+		return 0, fmt.Errorf("%s must be a non-negative number, got %q", key, v)
+	case 1:
+		return 0, fmt.Errorf("%s must be a positive number, got %q", key, v)
+	default:
+		return 0, fmt.Errorf("%s must be a number of at least %d, got %q", key, min, v)
+	}
+}
+
+// envFloat reads a number between min and max, def when unset
+func envFloat(key string, def, min, max float64) (float64, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < min || f > max {
+		return 0, fmt.Errorf("%s must be a number between %g and %g, got %q", key, min, max, v)
+	}
+	return f, nil
+}
+
+// envDuration reads a Go duration (e.g. "30s", "1h"), def when unset.
+// Zero is only accepted when allowZero is set.
+func envDuration(key string, def time.Duration, allowZero bool) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 || (d == 0 && !allowZero) {
+		if allowZero {
+			return 0, fmt.Errorf("%s must be a non-negative duration (e.g. 30m), got %q", key, v)
+		}
+		return 0, fmt.Errorf("%s must be a positive duration (e.g. 30s), got %q", key, v)
+	}
+	return d, nil
+}
+
+// envChoice reads one of choices (case-insensitive), def when unset
+func envChoice(key, def string, choices []string) (string, error) {
+	v := strings.ToLower(strings.TrimSpace(os.Getenv(key)))
+	if v == "" {
+		return def, nil
+	}
+	if !contains(choices, v) {
+		return "", fmt.Errorf("%s must be one of %s, got %q", key, strings.Join(choices, ", "), os.Getenv(key))
+	}
+	return v, nil
+}
+
+// splitList splits a comma-separated env value, dropping empty entries
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func contains(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
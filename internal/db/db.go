@@ -4,16 +4,34 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
+	"noteme/internal/config"
+	"time"
 
 	_ "github.com/lib/pq"
 )
 
 var DB *sql.DB
 
-// Init initializes the database connection
-func Init() error {
-	databaseURL := os.Getenv("DATABASE_URL")
+// skipMigrations is SKIP_MIGRATIONS, set by Init
+var skipMigrations bool
+
+// Delays between startup pings
+const (
+	defaultPingRetryDelay = 2 * time.Second
+	maxPingRetryDelay     = 30 * time.Second
+)
+
+// PoolConfig holds connection pool limits
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// Init initializes the database connection from DATABASE_URL and the DB_* pool settings
+func Init(cfg *config.Config) error {
+	databaseURL := cfg.DatabaseURL
+	skipMigrations = cfg.SkipMigrations
 	if databaseURL == "" {
 		return fmt.Errorf("DATABASE_URL environment variable is required")
 	}
@@ -24,8 +42,15 @@ func Init() error {
 		return fmt.Errorf("failed to open database connection: %w", err)
 	}
 
-	// Test connection
-	if err := DB.Ping(); err != nil {
+	pool := poolConfig(cfg)
+	DB.SetMaxOpenConns(pool.MaxOpenConns)
+	DB.SetMaxIdleConns(pool.MaxIdleConns)
+	DB.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	log.Printf("Database pool: max_open=%d max_idle=%d max_lifetime=%s",
+		pool.MaxOpenConns, pool.MaxIdleConns, pool.ConnMaxLifetime)
+
+	// Test connection, waiting for the database to come up (e.g. in docker-compose)
+	if err := pingWithRetry(cfg.DBPingRetries); err != nil {
 		return fmt.Errorf("failed to ping database: %w", err)
 	}
 
@@ -33,6 +58,46 @@ func Init() error {
 	return nil
 }
 
+// poolConfig returns DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS and DB_CONN_MAX_LIFETIME
+func poolConfig(cfg *config.Config) PoolConfig {
+	pool := PoolConfig{
+		MaxOpenConns:    cfg.DBMaxOpenConns,
+		MaxIdleConns:    cfg.DBMaxIdleConns,
+		ConnMaxLifetime: cfg.DBConnMaxLifetime,
+	}
+
+	// More idle than open connections is never used by database/sql
+	if pool.MaxIdleConns > pool.MaxOpenConns {
+		log.Printf("Warning: DB_MAX_IDLE_CONNS=%d exceeds DB_MAX_OPEN_CONNS=%d, lowering to %d",
+			pool.MaxIdleConns, pool.MaxOpenConns, pool.MaxOpenConns)
+		pool.MaxIdleConns = pool.MaxOpenConns
+	}
+
+	return pool
+}
+
+// pingWithRetry pings the database up to attempts times (DB_PING_RETRIES) with exponential backoff
+func pingWithRetry(attempts int) error {
+	delay := defaultPingRetryDelay
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = DB.Ping(); err == nil {
+			return nil
+		}
+		if attempt == attempts {
+			break
+		}
+		log.Printf("Database not reachable (attempt %d/%d): %v. Retrying in %s", attempt, attempts, err, delay)
+		time.Sleep(delay)
+		delay *= 2
+		if delay > maxPingRetryDelay {
+			delay = maxPingRetryDelay
+		}
+	}
+	return err
+}
+
 // Close closes the database connection
 func Close() error {
 	if DB != nil {
@@ -40,4 +105,3 @@ func Close() error {
 	}
 	return nil
 }
-
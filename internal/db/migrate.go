@@ -0,0 +1,189 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"log"
+	"noteme/migrations"
+	"sort"
+	"strings"
+)
+
+// migrationLockID is the pg_advisory_lock key so only one instance migrates at a time
+const migrationLockID = 727465
+
+// baselineVersion is the initial schema, which older deployments created by hand
+const baselineVersion = "000001"
+
+// migration is a single embedded .sql file
+type migration struct {
+	Version string
+	Name    string
+	SQL     string
+}
+
+// Migrate applies pending embedded migrations in order, tracking them in schema_migrations.
+// Each migration runs in its own transaction; the first failure stops startup.
+// Set SKIP_MIGRATIONS=true to manage the schema manually.
+func Migrate(ctx context.Context) error {
+	if skipMigrations {
+		log.Println("[Migrate] SKIP_MIGRATIONS set, skipping schema migrations")
+		return nil
+	}
+	if DB == nil {
+		return fmt.Errorf("database is not initialized")
+	}
+
+	pending, err := loadMigrations(migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	// Hold a session-level advisory lock on a dedicated connection
+	conn, err := DB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection for migrations: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+
+	if _, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	if err := baselineExistingSchema(ctx, conn, applied, pending); err != nil {
+		return err
+	}
+
+	count := 0
+	for _, m := range pending {
+		if applied[m.Version] {
+			continue
+		}
+		if err := applyMigration(ctx, conn, m); err != nil {
+			return err
+		}
+		log.Printf("[Migrate] Applied %s", m.Name)
+		count++
+	}
+
+	log.Printf("[Migrate] Schema up to date (%d applied this run, %d total)", count, len(pending))
+	return nil
+}
+
+// loadMigrations reads and sorts the embedded .sql files by version
+func loadMigrations(fsys fs.FS) ([]migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	list := make([]migration, 0, len(files))
+	seen := make(map[string]string)
+	for _, name := range files {
+		version, _, ok := strings.Cut(name, "_")
+		if !ok || version == "" {
+			return nil, fmt.Errorf("migration %s must be named <version>_<description>.sql", name)
+		}
+		if other, dup := seen[version]; dup {
+			return nil, fmt.Errorf("duplicate migration version %s (%s, %s)", version, other, name)
+		}
+		seen[version] = name
+
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+		list = append(list, migration{Version: version, Name: name, SQL: string(content)})
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+// appliedVersions returns the versions recorded in schema_migrations
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[string]bool, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations: %w", err)
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// baselineExistingSchema marks the initial migration as applied on databases
+// whose stt_requests table was created before migrations were tracked
+func baselineExistingSchema(ctx context.Context, conn *sql.Conn, applied map[string]bool, list []migration) error {
+	if len(applied) > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := conn.QueryRowContext(ctx, "SELECT to_regclass('stt_requests') IS NOT NULL").Scan(&exists); err != nil {
+		return fmt.Errorf("failed to inspect existing schema: %w", err)
+	}
+	if !exists {
+		return nil
+	}
+
+	for _, m := range list {
+		if m.Version != baselineVersion {
+			continue
+		}
+		if _, err := conn.ExecContext(ctx,
+			"INSERT INTO schema_migrations (version, name) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+			m.Version, m.Name); err != nil {
+			return fmt.Errorf("failed to record baseline migration: %w", err)
+		}
+		applied[m.Version] = true
+		log.Printf("[Migrate] Existing stt_requests table found, marked %s as applied", m.Name)
+	}
+	return nil
+}
+
+// applyMigration runs one migration and records it in the same transaction
+func applyMigration(ctx context.Context, conn *sql.Conn, m migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %s: %w", m.Name, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+		return fmt.Errorf("migration %s failed: %w", m.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", m.Version, m.Name); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", m.Name, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %s: %w", m.Name, err)
+	}
+	return nil
+}
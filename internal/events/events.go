@@ -0,0 +1,269 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Event types clients can subscribe to
+const (
+	TypeTranscriptionCompleted = "transcription.completed"
+	TypeAnalysisCompleted      = "analysis.completed"
+)
+
+// Types lists every supported event type
+var Types = []string{TypeTranscriptionCompleted, TypeAnalysisCompleted}
+
+const (
+	webhookTimeout     = 10 * time.Second
+	webhookMaxAttempts = 3
+)
+
+// Event is the JSON payload delivered to webhook subscribers
+type Event struct {
+	ID          string                 `json:"id"`
+	Type        string                 `json:"type"`
+	RecordingID string                 `json:"recording_id"`
+	CreatedAt   time.Time              `json:"created_at"`
+	Data        map[string]interface{} `json:"data"`
+}
+
+// Subscription is a webhook URL receiving a set of event types of the recordings of one user
+type Subscription struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"-"` // owner: only events of this user's recordings are delivered
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+var (
+	subscriptions = make(map[string]*Subscription)
+	mu            sync.RWMutex
+
+	// httpClient refuses to connect to private addresses, including hosts that resolved to a
+	// public address at Subscribe time and later changed (DNS rebinding) or redirects
+	httpClient = &http.Client{
+		Timeout: webhookTimeout,
+		Transport: &http.Transport{
+			DialContext:         (&net.Dialer{Timeout: webhookTimeout, Control: checkDialAddress}).DialContext,
+			TLSHandshakeTimeout: webhookTimeout,
+		},
+	}
+
+	// webhookSecret is WEBHOOK_SECRET, applied at startup with SetWebhookSecret
+	webhookSecret string
+)
+
+// SetWebhookSecret sets the key webhook bodies are signed with; empty sends them unsigned
+func SetWebhookSecret(secret string) {
+	webhookSecret = secret
+}
+
+// IsValidType reports whether t is a supported event type
+func IsValidType(t string) bool {
+	for _, known := range Types {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrPrivateAddress rejects webhook URLs pointing into the server's own network (SSRF)
+var ErrPrivateAddress = errors.New("url must not point to a private, loopback or link-local address")
+
+// Subscribe registers a webhook of userID for the given event types (all types when empty)
+func Subscribe(userID string, rawURL string, types []string) (*Subscription, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("url must be an absolute http(s) URL")
+	}
+	if err := checkHost(u.Hostname()); err != nil {
+		return nil, err
+	}
+	if len(types) == 0 {
+		types = Types
+	}
+	for _, t := range types {
+		if !IsValidType(t) {
+			return nil, fmt.Errorf("unsupported event type: %s", t)
+		}
+	}
+
+	sub := &Subscription{
+		ID:        fmt.Sprintf("whk_%d", time.Now().UnixNano()),
+		UserID:    userID,
+		URL:       u.String(),
+		Events:    append([]string(nil), types...),
+		CreatedAt: time.Now(),
+	}
+
+	mu.Lock()
+	subscriptions[sub.ID] = sub
+	mu.Unlock()
+
+	log.Printf("[Events] Webhook %s subscribed to %v", sub.ID, sub.Events)
+	subCopy := *sub
+	return &subCopy, nil
+}
+
+// Unsubscribe removes a webhook subscription of userID
+func Unsubscribe(userID string, id string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	if sub, ok := subscriptions[id]; !ok || sub.UserID != userID {
+		return false
+	}
+	delete(subscriptions, id)
+	return true
+}
+
+// ListSubscriptions returns the webhook subscriptions of userID, oldest first
+func ListSubscriptions(userID string) []Subscription {
+	mu.RLock()
+	defer mu.RUnlock()
+	subs := make([]Subscription, 0)
+	for _, sub := range subscriptions {
+		if sub.UserID == userID {
+			subs = append(subs, *sub)
+		}
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].CreatedAt.Before(subs[j].CreatedAt) })
+	return subs
+}
+
+// Publish delivers an event of a recording owned by userID to that user's webhooks subscribed to
+// its type. Delivery is asynchronous and best effort, so callers never wait on subscribers.
+func Publish(eventType string, userID string, recordingID string, data map[string]interface{}) {
+	event := Event{
+		ID:          fmt.Sprintf("evt_%d", time.Now().UnixNano()),
+		Type:        eventType,
+		RecordingID: recordingID,
+		CreatedAt:   time.Now(),
+		Data:        data,
+	}
+
+	mu.RLock()
+	var targets []Subscription
+	for _, sub := range subscriptions {
+		if userID == "" || sub.UserID != userID {
+			continue
+		}
+		for _, t := range sub.Events {
+			if t == eventType {
+				targets = append(targets, *sub)
+				break
+			}
+		}
+	}
+	mu.RUnlock()
+
+	if len(targets) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("[Events] Failed to encode %s event for recording %s: %v", eventType, recordingID, err)
+		return
+	}
+	for _, sub := range targets {
+		go deliver(sub, event, body)
+	}
+}
+
+// deliver POSTs an event to one webhook, retrying failed attempts with backoff
+func deliver(sub Subscription, event Event, body []byte) {
+	backoff := time.Second
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		err := post(sub.URL, event, body)
+		if err == nil {
+			log.Printf("[Events] Delivered %s %s to webhook %s", event.Type, event.ID, sub.ID)
+			return
+		}
+		log.Printf("[Events] Webhook %s attempt %d/%d failed for %s: %v", sub.ID, attempt, webhookMaxAttempts, event.ID, err)
+		if attempt < webhookMaxAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+}
+
+// checkHost resolves a webhook host and rejects it when any of its addresses is not public
+func checkHost(host string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve webhook host %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if !isPublicIP(addr.IP) {
+			return ErrPrivateAddress
+		}
+	}
+	return nil
+}
+
+// checkDialAddress is the dialer Control hook: it runs on the resolved address of every
+// connection, so a webhook can never reach a private address
+func checkDialAddress(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+// sharedAddressSpace is 100.64.0.0/10 (carrier-grade NAT), not covered by net.IP.IsPrivate
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// isPublicIP reports whether ip is a routable unicast address outside private networks
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified() && !sharedAddressSpace.Contains(ip)
+}
+
+func post(target string, event Event, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-NoteMe-Event", event.Type)
+	req.Header.Set("X-NoteMe-Event-ID", event.ID)
+	// Sign the body when WEBHOOK_SECRET is set so receivers can verify the sender
+	if secret := webhookSecret; secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		req.Header.Set("X-NoteMe-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
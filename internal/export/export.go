@@ -0,0 +1,96 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"noteme/internal/model"
+	"strings"
+	"time"
+)
+
+// ErrNoAnalysis is returned when a record has no stored AI analysis
+var ErrNoAnalysis = errors.New("analysis not available for this recording")
+
+// Document is the format-independent view of an analyzed recording
+type Document struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Context     string    `json:"context"`
+	CreatedAt   time.Time `json:"created_at"`
+	Summary     []string  `json:"summary"`
+	KeyPoints   []string  `json:"key_points"`
+	ActionItems []string  `json:"action_items"`
+	ZaloBrief   string    `json:"zalo_brief"`
+}
+
+// Renderer renders a document into a specific export format
+type Renderer interface {
+	// Render renders the document
+	Render(doc *Document) ([]byte, error)
+
+	// ContentType returns the MIME type of the rendered output
+	ContentType() string
+
+	// Extension returns the file extension (including the dot)
+	Extension() string
+}
+
+// RendererFor returns the renderer for a format name
+func RendererFor(format string) (Renderer, error) {
+	switch strings.ToLower(format) {
+	case "markdown", "md":
+		return &MarkdownRenderer{}, nil
+	case "pdf":
+		return &PDFRenderer{}, nil
+	case "json":
+		return &JSONRenderer{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s. Supported: markdown, pdf, json", format)
+	}
+}
+
+// FromSTTRequest builds a document from the analysis stored in record metadata
+func FromSTTRequest(req *model.STTRequest) (*Document, error) {
+	raw, ok := req.Metadata["ai_analysis"]
+	if !ok || raw == nil {
+		return nil, ErrNoAnalysis
+	}
+
+	// Round-trip through JSON to decode the generic metadata map
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode analysis: %w", err)
+	}
+
+	var analysis struct {
+		Context     string   `json:"context"`
+		Summary     []string `json:"summary"`
+		KeyPoints   []string `json:"key_points"`
+		ActionItems []string `json:"action_items"`
+		ZaloBrief   string   `json:"zalo_brief"`
+	}
+	if err := json.Unmarshal(data, &analysis); err != nil {
+		return nil, fmt.Errorf("failed to decode analysis: %w", err)
+	}
+
+	doc := &Document{
+		ID:          req.ID.String(),
+		Context:     analysis.Context,
+		CreatedAt:   req.CreatedAt,
+		Summary:     analysis.Summary,
+		KeyPoints:   analysis.KeyPoints,
+		ActionItems: analysis.ActionItems,
+		ZaloBrief:   analysis.ZaloBrief,
+	}
+	if req.Title != nil {
+		doc.Title = *req.Title
+	}
+
+	return doc, nil
+}
+
+// Filename returns a download filename for the document
+func Filename(doc *Document, r Renderer) string {
+	return "noteme_" + doc.ID + r.Extension()
+}
@@ -0,0 +1,23 @@
+package export
+
+import (
+	"encoding/json"
+)
+
+// JSONRenderer renders documents as indented JSON (e.g. for archiving or re-importing)
+type JSONRenderer struct{}
+
+// ContentType returns the JSON MIME type
+func (r *JSONRenderer) ContentType() string {
+	return "application/json; charset=utf-8"
+}
+
+// Extension returns the JSON file extension
+func (r *JSONRenderer) Extension() string {
+	return ".json"
+}
+
+// Render renders the document as JSON
+func (r *JSONRenderer) Render(doc *Document) ([]byte, error) {
+	return json.MarshalIndent(doc, "", "  ")
+}
@@ -0,0 +1,68 @@
+package export
+
+import (
+	"strings"
+)
+
+// MarkdownRenderer renders documents as Markdown (e.g. for pasting into Notion)
+type MarkdownRenderer struct{}
+
+// ContentType returns the Markdown MIME type
+func (r *MarkdownRenderer) ContentType() string {
+	return "text/markdown; charset=utf-8"
+}
+
+// Extension returns the Markdown file extension
+func (r *MarkdownRenderer) Extension() string {
+	return ".md"
+}
+
+// Render renders the document as Markdown
+func (r *MarkdownRenderer) Render(doc *Document) ([]byte, error) {
+	var b strings.Builder
+
+	title := doc.Title
+	if title == "" {
+		title = "NoteMe"
+	}
+	b.WriteString("# " + title + "\n\n")
+
+	if doc.Context != "" {
+		b.WriteString("**Context:** " + doc.Context + "  \n")
+	}
+	if !doc.CreatedAt.IsZero() {
+		b.WriteString("**Date:** " + doc.CreatedAt.Format("2006-01-02 15:04") + "\n")
+	}
+	b.WriteString("\n")
+
+	writeMarkdownList(&b, "Summary", doc.Summary, false)
+	writeMarkdownList(&b, "Key Points", doc.KeyPoints, false)
+	writeMarkdownList(&b, "Action Items", doc.ActionItems, true)
+
+	if strings.TrimSpace(doc.ZaloBrief) != "" {
+		b.WriteString("## Zalo Brief\n\n")
+		b.WriteString(strings.TrimSpace(doc.ZaloBrief) + "\n\n")
+	}
+
+	return []byte(strings.TrimRight(b.String(), "\n") + "\n"), nil
+}
+
+// writeMarkdownList writes a section with a bullet (or checkbox) list, skipping empty lists
+func writeMarkdownList(b *strings.Builder, heading string, items []string, checkbox bool) {
+	if len(items) == 0 {
+		return
+	}
+	b.WriteString("## " + heading + "\n\n")
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if checkbox {
+			b.WriteString("- [ ] " + item + "\n")
+		} else {
+			b.WriteString("- " + item + "\n")
+		}
+	}
+	b.WriteString("\n")
+}
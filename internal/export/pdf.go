@@ -0,0 +1,146 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	defaultPDFFontPath = "/usr/share/fonts/dejavu/DejaVuSans.ttf"
+	pdfFontFamily      = "NoteMeSans"
+)
+
+// pdfFontPath is PDF_FONT_PATH, applied at startup with SetPDFFontPath
+var pdfFontPath = defaultPDFFontPath
+
+// SetPDFFontPath sets the Unicode .ttf font embedded in PDF exports; empty keeps DejaVu Sans
+func SetPDFFontPath(path string) {
+	if path != "" {
+		pdfFontPath = path
+	}
+}
+
+// PDFRenderer renders documents as PDF.
+// A Unicode TrueType font is embedded so Vietnamese diacritics render correctly;
+// the font is read from PDF_FONT_PATH (default: DejaVu Sans).
+type PDFRenderer struct{}
+
+// ContentType returns the PDF MIME type
+func (r *PDFRenderer) ContentType() string {
+	return "application/pdf"
+}
+
+// Extension returns the PDF file extension
+func (r *PDFRenderer) Extension() string {
+	return ".pdf"
+}
+
+// Render renders the document as PDF
+func (r *PDFRenderer) Render(doc *Document) ([]byte, error) {
+	regular, bold, err := loadPDFFonts()
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(true, 20)
+
+	// Embed UTF-8 fonts; core PDF fonts would mangle accented characters
+	pdf.AddUTF8FontFromBytes(pdfFontFamily, "", regular)
+	pdf.AddUTF8FontFromBytes(pdfFontFamily, "B", bold)
+	if err := pdf.Error(); err != nil {
+		return nil, fmt.Errorf("failed to embed PDF font: %w", err)
+	}
+
+	pdf.AddPage()
+
+	title := doc.Title
+	if title == "" {
+		title = "NoteMe"
+	}
+	pdf.SetFont(pdfFontFamily, "B", 18)
+	pdf.MultiCell(0, 9, title, "", "L", false)
+	pdf.Ln(2)
+
+	pdf.SetFont(pdfFontFamily, "", 10)
+	pdf.SetTextColor(100, 100, 100)
+	var meta []string
+	if doc.Context != "" {
+		meta = append(meta, "Context: "+doc.Context)
+	}
+	if !doc.CreatedAt.IsZero() {
+		meta = append(meta, "Date: "+doc.CreatedAt.Format("2006-01-02 15:04"))
+	}
+	if len(meta) > 0 {
+		pdf.MultiCell(0, 5, strings.Join(meta, "   |   "), "", "L", false)
+	}
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(4)
+
+	writePDFList(pdf, "Summary", doc.Summary, "•")
+	writePDFList(pdf, "Key Points", doc.KeyPoints, "•")
+	writePDFList(pdf, "Action Items", doc.ActionItems, "□")
+
+	if brief := strings.TrimSpace(doc.ZaloBrief); brief != "" {
+		writePDFHeading(pdf, "Zalo Brief")
+		pdf.SetFont(pdfFontFamily, "", 11)
+		pdf.MultiCell(0, 6, brief, "", "L", false)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render PDF: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writePDFHeading writes a section heading
+func writePDFHeading(pdf *gofpdf.Fpdf, heading string) {
+	pdf.SetFont(pdfFontFamily, "B", 13)
+	pdf.MultiCell(0, 8, heading, "", "L", false)
+	pdf.Ln(1)
+}
+
+// writePDFList writes a section with a bullet list, skipping empty lists
+func writePDFList(pdf *gofpdf.Fpdf, heading string, items []string, bullet string) {
+	if len(items) == 0 {
+		return
+	}
+	writePDFHeading(pdf, heading)
+	pdf.SetFont(pdfFontFamily, "", 11)
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		pdf.MultiCell(0, 6, bullet+" "+item, "", "L", false)
+	}
+	pdf.Ln(3)
+}
+
+// loadPDFFonts reads the regular and bold TrueType fonts.
+// The bold face is looked up next to the regular one (e.g. DejaVuSans-Bold.ttf)
+// and falls back to the regular face when missing.
+func loadPDFFonts() ([]byte, []byte, error) {
+	path := pdfFontPath
+
+	regular, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("PDF font not available (set PDF_FONT_PATH to a Unicode .ttf font): %w", err)
+	}
+
+	ext := filepath.Ext(path)
+	boldPath := strings.TrimSuffix(path, ext) + "-Bold" + ext
+	bold, err := os.ReadFile(boldPath)
+	if err != nil {
+		bold = regular
+	}
+
+	return regular, bold, nil
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"net/http"
+	"noteme/internal/ai"
+	"noteme/internal/stt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	registry = prometheus.NewRegistry()
+
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "noteme_http_requests_total",
+		Help: "HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "noteme_http_request_duration_seconds",
+		Help:    "HTTP request latency by route and method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	sttDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "noteme_stt_transcription_duration_seconds",
+		Help:    "STT transcription duration by provider.",
+		Buckets: []float64{0.5, 1, 2.5, 5, 10, 20, 30, 60, 90, 180},
+	}, []string{"provider"})
+
+	sttTranscriptions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "noteme_stt_transcriptions_total",
+		Help: "STT transcriptions by provider and result (success, failure).",
+	}, []string{"provider", "result"})
+
+	openAITokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "noteme_openai_tokens_total",
+		Help: "OpenAI tokens used by operation and type (prompt, completion).",
+	}, []string{"operation", "type"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration, sttDuration, sttTranscriptions, openAITokens,
+	)
+}
+
+// Register installs the Prometheus sinks into the stt and ai packages
+func Register() {
+	stt.SetMetrics(sttMetrics{})
+	ai.SetMetrics(aiMetrics{})
+}
+
+// Handler serves the /metrics endpoint
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// GinMiddleware records request count and latency per route template
+func GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		// Use the route template (e.g. /api/v1/recordings/:recording_id) to keep label cardinality low
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		httpRequests.WithLabelValues(route, c.Request.Method, strconv.Itoa(c.Writer.Status())).Inc()
+		httpDuration.WithLabelValues(route, c.Request.Method).Observe(time.Since(start).Seconds())
+	}
+}
+
+type sttMetrics struct{}
+
+func (sttMetrics) ObserveTranscription(provider string, duration time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	sttDuration.WithLabelValues(provider).Observe(duration.Seconds())
+	sttTranscriptions.WithLabelValues(provider, result).Inc()
+}
+
+type aiMetrics struct{}
+
+func (aiMetrics) AddTokens(operation string, promptTokens, completionTokens int) {
+	openAITokens.WithLabelValues(operation, "prompt").Add(float64(promptTokens))
+	openAITokens.WithLabelValues(operation, "completion").Add(float64(completionTokens))
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GlossaryEntry is one entry of a user's cleaning glossary: a preserved term when Wrong is nil,
+// otherwise a correction of the misrecognition Wrong to Term
+type GlossaryEntry struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Term      string    `json:"term"`
+	Wrong     *string   `json:"wrong,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
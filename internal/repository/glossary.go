@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"noteme/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	// ErrGlossaryFull is returned when adding entries would exceed the user's glossary size cap
+	ErrGlossaryFull = errors.New("glossary is full")
+	// ErrGlossaryDuplicate is returned when an update collides with another entry of the user
+	ErrGlossaryDuplicate = errors.New("glossary entry already exists")
+	// ErrGlossaryEntryNotFound is returned for an ID that is not in the user's glossary
+	ErrGlossaryEntryNotFound = errors.New("glossary entry not found")
+)
+
+// uniqueViolation is the PostgreSQL error code of a unique index conflict
+const uniqueViolation = "23505"
+
+// ListGlossary returns a user's glossary, oldest entries first
+func (r *postgresRepository) ListGlossary(ctx context.Context, userID uuid.UUID) ([]model.GlossaryEntry, error) {
+	query := `
+		SELECT id, user_id, term, wrong, created_at
+		FROM user_glossary
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list glossary: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []model.GlossaryEntry{}
+	for rows.Next() {
+		var e model.GlossaryEntry
+		var wrong sql.NullString
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Term, &wrong, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan glossary entry: %w", err)
+		}
+		if wrong.Valid {
+			e.Wrong = &wrong.String
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list glossary: %w", err)
+	}
+	return entries, nil
+}
+
+// AddGlossaryEntries inserts entries into a user's glossary and returns the ones added.
+// Entries matching an existing term or correction (case-insensitively) are skipped. If the
+// glossary would grow past maxEntries nothing is added and ErrGlossaryFull is returned.
+func (r *postgresRepository) AddGlossaryEntries(ctx context.Context, userID uuid.UUID, entries []model.GlossaryEntry, maxEntries int) ([]model.GlossaryEntry, error) {
+	var added []model.GlossaryEntry
+	err := r.withTx(ctx, func(txRepo *postgresRepository) error {
+		count, err := txRepo.lockGlossary(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO user_glossary (user_id, term, wrong)
+			VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING
+			RETURNING id, created_at
+		`
+		added = []model.GlossaryEntry{}
+		for _, e := range entries {
+			e.UserID = userID
+			err := txRepo.db.QueryRowContext(ctx, query, userID, e.Term, e.Wrong).Scan(&e.ID, &e.CreatedAt)
+			if err == sql.ErrNoRows {
+				continue // already in the glossary
+			}
+			if err != nil {
+				return fmt.Errorf("failed to add glossary entry: %w", err)
+			}
+			if count+len(added)+1 > maxEntries {
+				return ErrGlossaryFull
+			}
+			added = append(added, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// SaveGlossaryCorrections inserts "wrong → term" corrections into a user's glossary. A correction
+// whose wrong spelling is already there (case-insensitively) gets the new term; one with the same
+// term is left as is. If the glossary would grow past maxEntries nothing is saved and
+// ErrGlossaryFull is returned.
+func (r *postgresRepository) SaveGlossaryCorrections(ctx context.Context, userID uuid.UUID, entries []model.GlossaryEntry, maxEntries int) ([]model.GlossaryEntry, []model.GlossaryEntry, error) {
+	var added, updated []model.GlossaryEntry
+	err := r.withTx(ctx, func(txRepo *postgresRepository) error {
+		count, err := txRepo.lockGlossary(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		// xmax is 0 for a freshly inserted row and set for an updated one
+		query := `
+			INSERT INTO user_glossary (user_id, term, wrong)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, lower(wrong)) WHERE wrong IS NOT NULL
+			DO UPDATE SET term = EXCLUDED.term
+			WHERE user_glossary.term IS DISTINCT FROM EXCLUDED.term
+			RETURNING id, created_at, xmax = 0
+		`
+		added, updated = []model.GlossaryEntry{}, []model.GlossaryEntry{}
+		for _, e := range entries {
+			if e.Wrong == nil {
+				return fmt.Errorf("glossary correction %q has no wrong spelling", e.Term)
+			}
+			e.UserID = userID
+			var inserted bool
+			err := txRepo.db.QueryRowContext(ctx, query, userID, e.Term, e.Wrong).Scan(&e.ID, &e.CreatedAt, &inserted)
+			if err == sql.ErrNoRows {
+				continue // already in the glossary with this term
+			}
+			if err != nil {
+				return fmt.Errorf("failed to save glossary correction: %w", err)
+			}
+			if !inserted {
+				updated = append(updated, e)
+				continue
+			}
+			if count+len(added)+1 > maxEntries {
+				return ErrGlossaryFull
+			}
+			added = append(added, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return added, updated, nil
+}
+
+// lockGlossary serializes glossary additions of a user for the rest of the transaction, so
+// concurrent requests cannot both pass the size check, and returns the current entry count
+func (r *postgresRepository) lockGlossary(ctx context.Context, userID uuid.UUID) (int, error) {
+	if _, err := r.db.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", "user_glossary:"+userID.String()); err != nil {
+		return 0, fmt.Errorf("failed to lock glossary: %w", err)
+	}
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM user_glossary WHERE user_id = $1", userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count glossary entries: %w", err)
+	}
+	return count, nil
+}
+
+// UpdateGlossaryEntry replaces the term and wrong spelling of one of a user's glossary entries
+func (r *postgresRepository) UpdateGlossaryEntry(ctx context.Context, entry *model.GlossaryEntry) error {
+	query := `
+		UPDATE user_glossary
+		SET term = $3, wrong = $4
+		WHERE id = $1 AND user_id = $2
+		RETURNING created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query, entry.ID, entry.UserID, entry.Term, entry.Wrong).Scan(&entry.CreatedAt)
+	var pqErr *pq.Error
+	switch {
+	case err == sql.ErrNoRows:
+		return ErrGlossaryEntryNotFound
+	case errors.As(err, &pqErr) && pqErr.Code == uniqueViolation:
+		return ErrGlossaryDuplicate
+	case err != nil:
+		return fmt.Errorf("failed to update glossary entry: %w", err)
+	}
+	return nil
+}
+
+// DeleteGlossaryEntry removes one entry from a user's glossary
+func (r *postgresRepository) DeleteGlossaryEntry(ctx context.Context, userID uuid.UUID, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM user_glossary WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete glossary entry: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrGlossaryEntryNotFound
+	}
+	return nil
+}
+
+// ClearGlossary removes a user's whole glossary
+func (r *postgresRepository) ClearGlossary(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM user_glossary WHERE user_id = $1", userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear glossary: %w", err)
+	}
+	return result.RowsAffected()
+}
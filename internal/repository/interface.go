@@ -2,32 +2,120 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"noteme/internal/model"
 
 	"github.com/google/uuid"
 )
 
+// ErrNotFound is returned when an STT request does not exist or is already deleted.
+// Errors of GetByID and GetByRecordingID also wrap sql.ErrNoRows.
+var ErrNotFound = errors.New("STT request not found")
+
+// ListOptions holds optional filters and ordering for ListByUser
+type ListOptions struct {
+	Tag    string // only include records whose metadata.tags contains Tag
+	Status string // only include records with this status (default: all except deleted)
+	Entity string // only include records whose metadata.ai_analysis.entities has this text (case-insensitive)
+	Sort   string // created_at (default), duration, confidence or title (see ValidateSort)
+	Order  string // asc or desc (default)
+}
+
+// PurgedRecord identifies a permanently deleted row so callers can clean up its audio file and in-memory state
+type PurgedRecord struct {
+	ID          uuid.UUID
+	AudioURL    string
+	RecordingID string // in-memory recording ID from metadata, may be empty
+}
+
+// UserStats aggregates the non-deleted records of a user
+type UserStats struct {
+	Total            int            // number of records
+	ByStatus         map[string]int // number of records per status
+	TotalDurationMs  int64          // sum of audio_duration_ms (records without a duration count as 0)
+	TotalActionItems int            // sum of metadata.ai_analysis.action_items lengths
+}
+
 // STTRepository defines the interface for STT request data access
 type STTRepository interface {
 	// Create creates a new STT request record
 	Create(ctx context.Context, req *model.STTRequest) error
 
+	// WithTx runs fn with a repository bound to a single transaction (committed when fn returns nil)
+	WithTx(ctx context.Context, fn func(repo STTRepository) error) error
+
 	// UpdateResult updates the STT result (transcript, confidence, status, etc.)
 	UpdateResult(ctx context.Context, req *model.STTRequest) error
 
 	// UpdateTitle updates the title of an STT request
 	UpdateTitle(ctx context.Context, id uuid.UUID, title string) error
 
-	// Delete soft deletes an STT request by setting status to "deleted"
+	// ClearAudio empties audio_url and flags metadata.audio_deleted once the audio file has been removed
+	ClearAudio(ctx context.Context, id uuid.UUID) error
+
+	// Delete soft deletes an STT request by setting status to "deleted" (ErrNotFound)
 	Delete(ctx context.Context, id uuid.UUID) error
 
-	// GetByID retrieves an STT request by ID (excludes deleted records)
+	// Purge permanently removes an STT request, including soft-deleted ones (ErrNotFound)
+	Purge(ctx context.Context, id uuid.UUID) (*PurgedRecord, error)
+
+	// PurgeAllByUser permanently removes every STT request of a user
+	PurgeAllByUser(ctx context.Context, userID uuid.UUID) ([]PurgedRecord, error)
+
+	// DeleteExpired removes up to limit rows older than their retention period (the user's SetRetentionDays
+	// override, else defaultDays). purge deletes permanently, otherwise rows are soft deleted.
+	DeleteExpired(ctx context.Context, defaultDays int, purge bool, limit int) ([]PurgedRecord, error)
+
+	// SetRetentionDays overrides the retention period of a user's rows, current and future (0 = keep forever)
+	SetRetentionDays(ctx context.Context, userID uuid.UUID, days int) error
+
+	// GetByID retrieves an STT request by ID (excludes deleted records, ErrNotFound)
 	GetByID(ctx context.Context, id uuid.UUID) (*model.STTRequest, error)
 
+	// GetByRecordingID retrieves the STT request synced from an in-memory recording ID (excludes deleted records)
+	GetByRecordingID(ctx context.Context, recordingID string) (*model.STTRequest, error)
+
+	// GetByUploadKey retrieves a record of a user by an upload dedupe key stored in metadata
+	// (idempotency_key or content_sha256), so retries are matched after a restart (excludes deleted records)
+	GetByUploadKey(ctx context.Context, userID uuid.UUID, key string, value string) (*model.STTRequest, error)
+
+	// GetAnalysisJSON retrieves the metadata.ai_analysis JSON stored for an in-memory recording ID (excludes deleted records)
+	GetAnalysisJSON(ctx context.Context, recordingID string) ([]byte, error)
+
+	// UpdateTags replaces the tags stored in metadata of an STT request
+	UpdateTags(ctx context.Context, id uuid.UUID, tags []string) error
+
+	// UpdatePeaks caches the waveform peaks of the audio in metadata.waveform_peaks
+	UpdatePeaks(ctx context.Context, id uuid.UUID, peaks []float64) error
+
 	// ListByUser retrieves STT requests for a user with pagination (excludes deleted records)
-	ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.STTRequest, error)
+	ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int, opts ListOptions) ([]model.STTRequest, error)
 
 	// Search searches STT requests by meaning in title, summary, and action_items (excludes deleted records)
 	Search(ctx context.Context, userID uuid.UUID, query string, limit, offset int) ([]model.STTRequest, error)
-}
 
+	// Stats counts a user's records by status and sums their audio duration and action items (excludes deleted records)
+	Stats(ctx context.Context, userID uuid.UUID) (*UserStats, error)
+
+	// ListGlossary returns a user's cleaning glossary, oldest entries first
+	ListGlossary(ctx context.Context, userID uuid.UUID) ([]model.GlossaryEntry, error)
+
+	// AddGlossaryEntries adds entries to a user's glossary, skipping ones it already has, and returns the added ones.
+	// Returns ErrGlossaryFull when the glossary would exceed maxEntries.
+	AddGlossaryEntries(ctx context.Context, userID uuid.UUID, entries []model.GlossaryEntry, maxEntries int) ([]model.GlossaryEntry, error)
+
+	// SaveGlossaryCorrections adds "wrong → term" corrections to a user's glossary, replacing the term of
+	// corrections it already has for the same wrong spelling. Returns the added and the updated entries;
+	// unchanged ones are in neither. Returns ErrGlossaryFull when the glossary would exceed maxEntries.
+	SaveGlossaryCorrections(ctx context.Context, userID uuid.UUID, entries []model.GlossaryEntry, maxEntries int) (added []model.GlossaryEntry, updated []model.GlossaryEntry, err error)
+
+	// UpdateGlossaryEntry replaces the term and wrong spelling of entry.ID in entry.UserID's glossary
+	// (ErrGlossaryEntryNotFound, ErrGlossaryDuplicate)
+	UpdateGlossaryEntry(ctx context.Context, entry *model.GlossaryEntry) error
+
+	// DeleteGlossaryEntry removes an entry from a user's glossary (ErrGlossaryEntryNotFound)
+	DeleteGlossaryEntry(ctx context.Context, userID uuid.UUID, id uuid.UUID) error
+
+	// ClearGlossary removes a user's whole glossary
+	ClearGlossary(ctx context.Context, userID uuid.UUID) (int64, error)
+}
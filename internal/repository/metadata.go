@@ -0,0 +1,330 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+const (
+	// maxMetadataBytes caps the whole metadata document
+	maxMetadataBytes = 256 * 1024
+	// maxUnknownMetadataValueBytes caps values stored under keys outside the known schema
+	maxUnknownMetadataValueBytes = 4 * 1024
+)
+
+// ErrMetadataTooLarge is returned when a metadata document, or the result of merging it into the
+// stored one, exceeds maxMetadataBytes
+var ErrMetadataTooLarge = fmt.Errorf("metadata exceeds %d bytes", maxMetadataBytes)
+
+// metadataStringKeys are top-level metadata keys that must hold a string
+var metadataStringKeys = map[string]bool{
+	"recording_id":    true,
+	"idempotency_key": true,
+	"content_sha256":  true,
+	"raw_transcript":  true, // unredacted STT text of profanity-filtered recordings
+
+	// why AI cleaning was skipped (high_confidence, low_confidence, ai_not_configured)
+	"ai_cleaning_skip_reason": true,
+
+	// A/B prompt experiment the recording was assigned to (see ai.AssignPromptVariant)
+	"prompt_experiment": true,
+	"prompt_variant":    true,
+}
+
+// analysisStringFields and analysisListFields describe the ai_analysis shape used by Search and export
+var (
+	analysisStringFields = []string{"context", "title", "language", "zalo_brief", "prompt_template", "system_prompt", "transcript_hash"}
+	analysisListFields   = []string{"summary", "key_points", "action_items", "questions"}
+)
+
+// NormalizeMetadata validates metadata against the known schema before it is written to the JSONB column.
+// Known keys (and ai_analysis sub-fields) with the wrong type are rejected. Unknown keys are preserved
+// for backward compatibility unless their value is oversized, in which case they are dropped.
+func NormalizeMetadata(metadata map[string]interface{}) (map[string]interface{}, error) {
+	if metadata == nil {
+		return nil, nil
+	}
+
+	normalized := make(map[string]interface{}, len(metadata))
+	for key, value := range metadata {
+		switch {
+		case metadataStringKeys[key]:
+			s, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("metadata.%s must be a string", key)
+			}
+			normalized[key] = s
+
+		case key == "ai_analysis":
+			analysis, err := normalizeAnalysisMetadata(value)
+			if err != nil {
+				return nil, err
+			}
+			normalized[key] = analysis
+
+		case key == "tags" || key == "decoded_words" || key == "user_corrections":
+			list, ok := toStringSlice(value)
+			if !ok {
+				return nil, fmt.Errorf("metadata.%s must be an array of strings", key)
+			}
+			normalized[key] = list
+
+		case key == "segments":
+			segments, err := normalizeSegmentsMetadata(value)
+			if err != nil {
+				return nil, err
+			}
+			normalized[key] = segments
+
+		case key == "sentences":
+			sentences, err := normalizeSentencesMetadata(value)
+			if err != nil {
+				return nil, err
+			}
+			normalized[key] = sentences
+
+		case key == "original_transcript":
+			original, err := normalizeTranscriptVersion(key, value)
+			if err != nil {
+				return nil, err
+			}
+			normalized[key] = original
+
+		case key == "low_confidence" || key == "confidence_available" || key == "audio_deleted" || key == "ai_cleaning_skipped":
+			b, ok := value.(bool)
+			if !ok {
+				return nil, fmt.Errorf("metadata.%s must be a boolean", key)
+			}
+			normalized[key] = b
+
+		case key == "ai_cleaning_time_ms" || key == "raw_confidence":
+			if !isNumber(value) {
+				return nil, fmt.Errorf("metadata.%s must be a number", key)
+			}
+			normalized[key] = value
+
+		default:
+			keep, err := keepUnknownMetadataValue(key, value)
+			if err != nil {
+				return nil, err
+			}
+			if keep {
+				normalized[key] = value
+			}
+		}
+	}
+
+	if err := checkMetadataSize(normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// checkMetadataSize rejects metadata documents larger than maxMetadataBytes
+func checkMetadataSize(metadata map[string]interface{}) error {
+	raw, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	if len(raw) > maxMetadataBytes {
+		return ErrMetadataTooLarge
+	}
+	return nil
+}
+
+// normalizeAnalysisMetadata type-checks the ai_analysis fields and fills missing arrays
+func normalizeAnalysisMetadata(value interface{}) (map[string]interface{}, error) {
+	raw, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("metadata.ai_analysis must be an object")
+	}
+
+	analysis := make(map[string]interface{}, len(analysisStringFields)+len(analysisListFields))
+	for _, field := range analysisStringFields {
+		v, exists := raw[field]
+		if !exists || v == nil {
+			continue
+		}
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("metadata.ai_analysis.%s must be a string", field)
+		}
+		analysis[field] = s
+	}
+	for _, field := range analysisListFields {
+		v, exists := raw[field]
+		if !exists || v == nil {
+			// Search expands these with jsonb_array_elements_text, so keep them as arrays
+			analysis[field] = []string{}
+			continue
+		}
+		list, ok := toStringSlice(v)
+		if !ok {
+			return nil, fmt.Errorf("metadata.ai_analysis.%s must be an array of strings", field)
+		}
+		analysis[field] = list
+	}
+	if v, exists := raw["entities"]; exists && v != nil {
+		entities, err := normalizeEntitiesMetadata(v)
+		if err != nil {
+			return nil, err
+		}
+		analysis["entities"] = entities
+	}
+	if v, exists := raw["analysis_version"]; exists && v != nil {
+		if !isNumber(v) {
+			return nil, fmt.Errorf("metadata.ai_analysis.analysis_version must be a number")
+		}
+		analysis["analysis_version"] = v
+	}
+
+	for field, v := range raw {
+		if isAnalysisField(field) {
+			continue
+		}
+		keep, err := keepUnknownMetadataValue("ai_analysis."+field, v)
+		if err != nil {
+			return nil, err
+		}
+		if keep {
+			analysis[field] = v
+		}
+	}
+	return analysis, nil
+}
+
+// metadataEntity is the stored shape of a named entity (see ai.Entity)
+type metadataEntity struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// normalizeEntitiesMetadata checks that ai_analysis.entities is an array of {type, text}
+func normalizeEntitiesMetadata(value interface{}) ([]metadataEntity, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("metadata.ai_analysis.entities is not valid JSON: %w", err)
+	}
+	var entities []metadataEntity
+	if err := json.Unmarshal(raw, &entities); err != nil {
+		return nil, fmt.Errorf("metadata.ai_analysis.entities must be an array of {type, text}")
+	}
+	return entities, nil
+}
+
+// metadataSegment is the stored shape of a diarization segment (see stt.Segment)
+type metadataSegment struct {
+	Speaker   int     `json:"speaker"`
+	Text      string  `json:"text"`
+	StartTime float64 `json:"start_time"`
+	EndTime   float64 `json:"end_time"`
+}
+
+// metadataSentence is the stored shape of a timed sentence (see stt.Sentence)
+type metadataSentence struct {
+	Text      string  `json:"text"`
+	StartTime float64 `json:"start_time"`
+	EndTime   float64 `json:"end_time"`
+}
+
+// normalizeSentencesMetadata checks that sentences is an array of timed sentences
+func normalizeSentencesMetadata(value interface{}) ([]metadataSentence, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("metadata.sentences is not valid JSON: %w", err)
+	}
+	var sentences []metadataSentence
+	if err := json.Unmarshal(raw, &sentences); err != nil {
+		return nil, fmt.Errorf("metadata.sentences must be an array of {text, start_time, end_time}")
+	}
+	return sentences, nil
+}
+
+// normalizeSegmentsMetadata checks that segments is an array of speaker segments
+func normalizeSegmentsMetadata(value interface{}) ([]metadataSegment, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("metadata.segments is not valid JSON: %w", err)
+	}
+	var segments []metadataSegment
+	if err := json.Unmarshal(raw, &segments); err != nil {
+		return nil, fmt.Errorf("metadata.segments must be an array of {speaker, text, start_time, end_time}")
+	}
+	return segments, nil
+}
+
+// metadataTranscriptVersion is the stored shape of a transcript kept before a provider re-run
+type metadataTranscriptVersion struct {
+	Provider   string   `json:"provider"`
+	Transcript string   `json:"transcript"`
+	Confidence *float64 `json:"confidence"` // null when the provider gave no score
+}
+
+// normalizeTranscriptVersion checks that value is a {provider, transcript, confidence} object
+func normalizeTranscriptVersion(key string, value interface{}) (*metadataTranscriptVersion, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("metadata.%s is not valid JSON: %w", key, err)
+	}
+	var version metadataTranscriptVersion
+	if err := json.Unmarshal(raw, &version); err != nil {
+		return nil, fmt.Errorf("metadata.%s must be an object of {provider, transcript, confidence}", key)
+	}
+	version.Confidence = nullableConfidence(version.Confidence)
+	return &version, nil
+}
+
+// keepUnknownMetadataValue reports whether a key outside the schema is small enough to preserve
+func keepUnknownMetadataValue(key string, value interface{}) (bool, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return false, fmt.Errorf("metadata.%s is not valid JSON: %w", key, err)
+	}
+	if len(raw) > maxUnknownMetadataValueBytes {
+		log.Printf("Warning: dropping oversized metadata key %q (%d bytes)", key, len(raw))
+		return false, nil
+	}
+	return true, nil
+}
+
+// isAnalysisField reports whether field belongs to the typed ai_analysis schema
+func isAnalysisField(field string) bool {
+	if field == "entities" || field == "analysis_version" {
+		return true
+	}
+	for _, known := range append(analysisStringFields, analysisListFields...) {
+		if field == known {
+			return true
+		}
+	}
+	return false
+}
+
+// toStringSlice accepts []string or a JSON-decoded []interface{} of strings
+func toStringSlice(value interface{}) ([]string, bool) {
+	switch v := value.(type) {
+	case []string:
+		return v, true
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, s)
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+// isNumber reports whether value is a Go or JSON-decoded number
+func isNumber(value interface{}) bool {
+	switch value.(type) {
+	case int, int32, int64, float32, float64, json.Number:
+		return true
+	}
+	return false
+}
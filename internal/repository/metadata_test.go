@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeMetadataRejectsWrongTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata map[string]interface{}
+		wantErr  string
+	}{
+		{"tags as a string", map[string]interface{}{"tags": "work"}, "metadata.tags must be an array of strings"},
+		{"tags with a number", map[string]interface{}{"tags": []interface{}{"work", 1}}, "metadata.tags must be an array of strings"},
+		{"decoded_words as an object", map[string]interface{}{"decoded_words": map[string]interface{}{}}, "metadata.decoded_words must be an array of strings"},
+		{"recording_id as a number", map[string]interface{}{"recording_id": 42}, "metadata.recording_id must be a string"},
+		{"low_confidence as a string", map[string]interface{}{"low_confidence": "true"}, "metadata.low_confidence must be a boolean"},
+		{"ai_analysis as a string", map[string]interface{}{"ai_analysis": "summary"}, "metadata.ai_analysis must be an object"},
+		{"ai_analysis as an array", map[string]interface{}{"ai_analysis": []interface{}{"summary"}}, "metadata.ai_analysis must be an object"},
+		{
+			"ai_analysis.summary as a string",
+			map[string]interface{}{"ai_analysis": map[string]interface{}{"summary": "one line"}},
+			"metadata.ai_analysis.summary must be an array of strings",
+		},
+		{
+			"ai_analysis.title as an array",
+			map[string]interface{}{"ai_analysis": map[string]interface{}{"title": []interface{}{"a"}}},
+			"metadata.ai_analysis.title must be a string",
+		},
+		{
+			"ai_analysis.entities as strings",
+			map[string]interface{}{"ai_analysis": map[string]interface{}{"entities": []interface{}{"Lan"}}},
+			"metadata.ai_analysis.entities must be an array of {type, text}",
+		},
+		{
+			"ai_analysis.analysis_version as a string",
+			map[string]interface{}{"ai_analysis": map[string]interface{}{"analysis_version": "2"}},
+			"metadata.ai_analysis.analysis_version must be a number",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NormalizeMetadata(tt.metadata)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("NormalizeMetadata() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizeMetadataAcceptsKnownShapes(t *testing.T) {
+	got, err := NormalizeMetadata(map[string]interface{}{
+		"recording_id": "rec_1",
+		"tags":         []interface{}{"work", "q3"},
+		"ai_analysis": map[string]interface{}{
+			"title":    "Họp kế hoạch",
+			"summary":  []interface{}{"Chốt tính năng"},
+			"entities": []interface{}{map[string]interface{}{"type": "person", "text": "Lan"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NormalizeMetadata: %v", err)
+	}
+	if !reflect.DeepEqual(got["tags"], []string{"work", "q3"}) {
+		t.Errorf("tags = %#v, want []string{work q3}", got["tags"])
+	}
+	analysis := got["ai_analysis"].(map[string]interface{})
+	// Missing list fields are stored as empty arrays so Search can expand them
+	for _, field := range analysisListFields {
+		if _, ok := analysis[field].([]string); !ok {
+			t.Errorf("ai_analysis.%s = %#v, want a []string", field, analysis[field])
+		}
+	}
+	if !reflect.DeepEqual(analysis["entities"], []metadataEntity{{Type: "person", Text: "Lan"}}) {
+		t.Errorf("ai_analysis.entities = %#v", analysis["entities"])
+	}
+}
+
+func TestNormalizeMetadataOversize(t *testing.T) {
+	big := strings.Repeat("x", maxUnknownMetadataValueBytes)
+
+	tests := []struct {
+		name     string
+		metadata map[string]interface{}
+		wantErr  error
+		dropped  []string // keys removed from the result
+		kept     []string // keys present in the result
+	}{
+		{
+			name:     "oversized unknown key is dropped",
+			metadata: map[string]interface{}{"client_blob": big, "recording_id": "rec_1"},
+			dropped:  []string{"client_blob"},
+			kept:     []string{"recording_id"},
+		},
+		{
+			name:     "unknown key at the limit is kept",
+			metadata: map[string]interface{}{"client_blob": big[:maxUnknownMetadataValueBytes-2]},
+			kept:     []string{"client_blob"},
+		},
+		{
+			name:     "oversized unknown ai_analysis field is dropped",
+			metadata: map[string]interface{}{"ai_analysis": map[string]interface{}{"debug": big}},
+			kept:     []string{"ai_analysis"},
+		},
+		{
+			name:     "whole document over the cap is rejected",
+			metadata: map[string]interface{}{"raw_transcript": strings.Repeat("x", maxMetadataBytes)},
+			wantErr:  ErrMetadataTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeMetadata(tt.metadata)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("NormalizeMetadata() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeMetadata: %v", err)
+			}
+			for _, key := range tt.dropped {
+				if _, ok := got[key]; ok {
+					t.Errorf("key %q kept, want it dropped", key)
+				}
+			}
+			for _, key := range tt.kept {
+				if _, ok := got[key]; !ok {
+					t.Errorf("key %q dropped, want it kept", key)
+				}
+			}
+			if analysis, ok := got["ai_analysis"].(map[string]interface{}); ok {
+				if _, ok := analysis["debug"]; ok {
+					t.Error("oversized ai_analysis.debug kept, want it dropped")
+				}
+			}
+		})
+	}
+}
@@ -13,15 +13,55 @@ import (
 	"github.com/google/uuid"
 )
 
+// dbtx is the subset of *sql.DB and *sql.Tx used by the repository
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
 type postgresRepository struct {
-	db *sql.DB
+	db   dbtx
+	conn *sql.DB // nil when the repository is bound to a transaction
 }
 
 // NewPostgresRepository creates a new PostgreSQL repository
 func NewPostgresRepository() STTRepository {
 	return &postgresRepository{
-		db: db.DB,
+		db:   db.DB,
+		conn: db.DB,
+	}
+}
+
+// WithTx runs fn with a repository bound to a single transaction.
+// The transaction is committed if fn returns nil and rolled back otherwise.
+func (r *postgresRepository) WithTx(ctx context.Context, fn func(repo STTRepository) error) error {
+	return r.withTx(ctx, func(txRepo *postgresRepository) error {
+		return fn(txRepo)
+	})
+}
+
+// withTx is WithTx for internal callers that need the concrete repository
+func (r *postgresRepository) withTx(ctx context.Context, fn func(txRepo *postgresRepository) error) error {
+	// Already inside a transaction: reuse it
+	if r.conn == nil {
+		return fn(r)
+	}
+
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(&postgresRepository{db: tx}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	return nil
 }
 
 // Create creates a new STT request record
@@ -36,8 +76,13 @@ func (r *postgresRepository) Create(ctx context.Context, req *model.STTRequest)
 		)
 	`
 
+	metadata, err := NormalizeMetadata(req.Metadata)
+	if err != nil {
+		return fmt.Errorf("invalid metadata: %w", err)
+	}
+
 	// Convert metadata to JSONB
-	metadataJSON, err := json.Marshal(req.Metadata)
+	metadataJSON, err := json.Marshal(metadata)
 	if err != nil {
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
@@ -54,7 +99,7 @@ func (r *postgresRepository) Create(ctx context.Context, req *model.STTRequest)
 		req.ModelVersion,
 		req.Title,
 		req.Transcript,
-		req.Confidence,
+		nullableConfidence(req.Confidence),
 		req.Status,
 		req.ErrorMessage,
 		req.ProcessingTimeMs,
@@ -69,97 +114,96 @@ func (r *postgresRepository) Create(ctx context.Context, req *model.STTRequest)
 	return nil
 }
 
-// UpdateResult updates the STT result
+// UpdateResult updates the STT result.
+// Metadata is merged atomically in the database with JSONB concatenation (metadata || new):
+// top-level keys in req.Metadata replace existing ones wholesale (e.g. the whole ai_analysis object),
+// other keys are kept, so concurrent updates of different keys never lose each other's writes.
 func (r *postgresRepository) UpdateResult(ctx context.Context, req *model.STTRequest) error {
-	// Build update query - only update metadata if provided
-	var query string
-	var args []interface{}
-
+	// Stays a nil interface (SQL NULL) when there is no metadata to merge
+	var metadataArg interface{}
 	if len(req.Metadata) > 0 {
-		// Include metadata update
-		query = `
-			UPDATE stt_requests
-			SET 
-				transcript = COALESCE($1, transcript),
-				confidence = COALESCE($2, confidence),
-				status = COALESCE($3, status),
-				error_message = COALESCE($4, error_message),
-				processing_time_ms = COALESCE($5, processing_time_ms),
-				audio_duration_ms = COALESCE($6, audio_duration_ms),
-				audio_size_bytes = COALESCE($7, audio_size_bytes),
-				title = COALESCE(NULLIF($8, ''), title),
-				metadata = $9::jsonb
-			WHERE id = $10
-		`
-
-		// Merge metadata if provided
-		var existingMetadata map[string]interface{}
-		existingReq, getErr := r.GetByID(ctx, req.ID)
-		if getErr == nil && existingReq.Metadata != nil {
-			existingMetadata = existingReq.Metadata
-		} else {
-			existingMetadata = make(map[string]interface{})
-		}
-
-		// Merge new metadata with existing
-		for k, v := range req.Metadata {
-			existingMetadata[k] = v
-		}
-
-		metadataJSON, marshalErr := json.Marshal(existingMetadata)
-		if marshalErr != nil {
-			return fmt.Errorf("failed to marshal metadata: %w", marshalErr)
-		}
-
-		args = []interface{}{
-			req.Transcript,
-			req.Confidence,
-			req.Status,
-			req.ErrorMessage,
-			req.ProcessingTimeMs,
-			req.AudioDurationMs,
-			req.AudioSizeBytes,
-			req.Title,
-			metadataJSON,
-			req.ID,
+		metadata, err := NormalizeMetadata(req.Metadata)
+		if err != nil {
+			return fmt.Errorf("invalid metadata: %w", err)
 		}
-	} else {
-		// Don't update metadata
-		query = `
-			UPDATE stt_requests
-			SET 
-				transcript = COALESCE($1, transcript),
-				confidence = COALESCE($2, confidence),
-				status = COALESCE($3, status),
-				error_message = COALESCE($4, error_message),
-				processing_time_ms = COALESCE($5, processing_time_ms),
-				audio_duration_ms = COALESCE($6, audio_duration_ms),
-				audio_size_bytes = COALESCE($7, audio_size_bytes),
-				title = COALESCE(NULLIF($8, ''), title)
-			WHERE id = $9
-		`
-
-		args = []interface{}{
-			req.Transcript,
-			req.Confidence,
-			req.Status,
-			req.ErrorMessage,
-			req.ProcessingTimeMs,
-			req.AudioDurationMs,
-			req.AudioSizeBytes,
-			req.Title,
-			req.ID,
+		metadataJSON, err := json.Marshal(metadata)
+		if err != nil {
+			return fmt.Errorf("failed to marshal metadata: %w", err)
 		}
+		metadataArg = string(metadataJSON)
 	}
 
-	_, err := r.db.ExecContext(ctx, query, args...)
+	query := `
+		UPDATE stt_requests
+		SET 
+			transcript = COALESCE($1, transcript),
+			-- a new transcript always replaces confidence, so an unscored re-run clears the old score
+			confidence = CASE WHEN $1::text IS NOT NULL THEN $2 ELSE confidence END,
+			status = COALESCE($3, status),
+			error_message = COALESCE($4, error_message),
+			processing_time_ms = COALESCE($5, processing_time_ms),
+			audio_duration_ms = COALESCE($6, audio_duration_ms),
+			audio_size_bytes = COALESCE($7, audio_size_bytes),
+			title = COALESCE(NULLIF($8, ''), title),
+			language = COALESCE($11, language),
+			stt_provider = COALESCE(NULLIF($12, ''), stt_provider),
+			metadata = CASE
+				WHEN $9::jsonb IS NULL THEN metadata
+				ELSE COALESCE(metadata, '{}'::jsonb) || $9::jsonb
+			END
+		WHERE id = $10
+			-- the merged document is held to the same cap as the patch (jsonb text is slightly larger than compact JSON)
+			AND ($9::jsonb IS NULL OR octet_length((COALESCE(metadata, '{}'::jsonb) || $9::jsonb)::text) <= $13)
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		req.Transcript,
+		nullableConfidence(req.Confidence),
+		req.Status,
+		req.ErrorMessage,
+		req.ProcessingTimeMs,
+		req.AudioDurationMs,
+		req.AudioSizeBytes,
+		req.Title,
+		metadataArg,
+		req.ID,
+		req.Language,
+		req.Provider,
+		maxMetadataBytes,
+	)
 	if err != nil {
 		return fmt.Errorf("failed to update STT request: %w", err)
 	}
 
+	// No row updated: either the ID is unknown (not an error, as before) or the merge was too large
+	if metadataArg != nil {
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			var exists bool
+			if err := r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM stt_requests WHERE id = $1)", req.ID).Scan(&exists); err != nil {
+				return fmt.Errorf("failed to update STT request: %w", err)
+			}
+			if exists {
+				return fmt.Errorf("invalid metadata: %w", ErrMetadataTooLarge)
+			}
+		}
+	}
+
 	return nil
 }
 
+// nullableConfidence maps the "no score" sentinel (stt.ConfidenceUnavailable, any negative value) to
+// NULL, so unscored rows sort last and stay out of confidence aggregates
+func nullableConfidence(confidence *float64) *float64 {
+	if confidence == nil || *confidence < 0 {
+		return nil
+	}
+	return confidence
+}
+
 // UpdateTitle updates the title of an STT request
 func (r *postgresRepository) UpdateTitle(ctx context.Context, id uuid.UUID, title string) error {
 	query := `
@@ -179,7 +223,94 @@ func (r *postgresRepository) UpdateTitle(ctx context.Context, id uuid.UUID, titl
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("STT request not found or already deleted")
+		return fmt.Errorf("%w or already deleted", ErrNotFound)
+	}
+
+	return nil
+}
+
+// UpdateTags replaces the tags stored in metadata of an STT request
+func (r *postgresRepository) UpdateTags(ctx context.Context, id uuid.UUID, tags []string) error {
+	tagsJSON, err := json.Marshal(tags)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tags: %w", err)
+	}
+
+	query := `
+		UPDATE stt_requests
+		SET metadata = jsonb_set(COALESCE(metadata, '{}'::jsonb), '{tags}', $1::jsonb)
+		WHERE id = $2 AND status != 'deleted'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, string(tagsJSON), id)
+	if err != nil {
+		return fmt.Errorf("failed to update tags: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w or already deleted", ErrNotFound)
+	}
+
+	return nil
+}
+
+// UpdatePeaks caches the waveform peaks of the audio in metadata.waveform_peaks
+func (r *postgresRepository) UpdatePeaks(ctx context.Context, id uuid.UUID, peaks []float64) error {
+	peaksJSON, err := json.Marshal(peaks)
+	if err != nil {
+		return fmt.Errorf("failed to marshal peaks: %w", err)
+	}
+
+	query := `
+		UPDATE stt_requests
+		SET metadata = jsonb_set(COALESCE(metadata, '{}'::jsonb), '{waveform_peaks}', $1::jsonb)
+		WHERE id = $2 AND status != 'deleted'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, string(peaksJSON), id)
+	if err != nil {
+		return fmt.Errorf("failed to update peaks: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w or already deleted", ErrNotFound)
+	}
+
+	return nil
+}
+
+// ClearAudio empties audio_url and flags metadata.audio_deleted once the audio file has been removed.
+// audio_url is NOT NULL, so an empty string marks a transcript-only row.
+func (r *postgresRepository) ClearAudio(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE stt_requests
+		SET audio_url = '',
+			metadata = COALESCE(metadata, '{}'::jsonb) || '{"audio_deleted": true}'::jsonb
+		WHERE id = $1 AND status != 'deleted'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to clear audio: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w or already deleted", ErrNotFound)
 	}
 
 	return nil
@@ -204,28 +335,164 @@ func (r *postgresRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("STT request not found or already deleted")
+		return fmt.Errorf("%w or already deleted", ErrNotFound)
 	}
 
 	return nil
 }
 
+// Purge permanently removes an STT request, including soft-deleted ones
+func (r *postgresRepository) Purge(ctx context.Context, id uuid.UUID) (*PurgedRecord, error) {
+	query := `
+		DELETE FROM stt_requests
+		WHERE id = $1
+		RETURNING id, audio_url, COALESCE(metadata->>'recording_id', '')
+	`
+
+	var purged PurgedRecord
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&purged.ID, &purged.AudioURL, &purged.RecordingID)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge STT request: %w", err)
+	}
+
+	return &purged, nil
+}
+
+// PurgeAllByUser permanently removes every STT request of a user
+func (r *postgresRepository) PurgeAllByUser(ctx context.Context, userID uuid.UUID) ([]PurgedRecord, error) {
+	query := `
+		DELETE FROM stt_requests
+		WHERE user_id = $1
+		RETURNING id, audio_url, COALESCE(metadata->>'recording_id', '')
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge STT requests: %w", err)
+	}
+	return scanPurgedRecords(rows)
+}
+
+// expiredRowsCTE selects up to $2 rows older than their retention period, oldest first (using
+// idx_stt_requests_created_at). A user's retention is user_settings.retention_days, else $1 days;
+// 0 keeps the rows forever.
+const expiredRowsCTE = `
+	WITH expired AS (
+		SELECT s.id, s.audio_url
+		FROM stt_requests s
+		LEFT JOIN user_settings u ON u.user_id = s.user_id
+		WHERE COALESCE(u.retention_days, $1) > 0
+			AND s.created_at < NOW() - make_interval(days => COALESCE(u.retention_days, $1))
+			%s
+		ORDER BY s.created_at
+		LIMIT $2
+		FOR UPDATE OF s SKIP LOCKED
+	)
+`
+
+// DeleteExpired removes up to limit rows past their retention period and returns them.
+// With purge the rows are permanently deleted, otherwise they are soft deleted and their audio_url cleared.
+func (r *postgresRepository) DeleteExpired(ctx context.Context, defaultDays int, purge bool, limit int) ([]PurgedRecord, error) {
+	var query string
+	if purge {
+		query = fmt.Sprintf(expiredRowsCTE, "") + `
+		DELETE FROM stt_requests s
+		USING expired e
+		WHERE s.id = e.id
+		RETURNING s.id, e.audio_url, COALESCE(s.metadata->>'recording_id', '')
+	`
+	} else {
+		query = fmt.Sprintf(expiredRowsCTE, "AND s.status != 'deleted'") + `
+		UPDATE stt_requests s
+		SET status = 'deleted', audio_url = ''
+		FROM expired e
+		WHERE s.id = e.id
+		RETURNING s.id, e.audio_url, COALESCE(s.metadata->>'recording_id', '')
+	`
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, defaultDays, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete expired STT requests: %w", err)
+	}
+	return scanPurgedRecords(rows)
+}
+
+// SetRetentionDays overrides the retention period of a user in user_settings, so it also applies to
+// rows created later and survives the user's rows being purged
+func (r *postgresRepository) SetRetentionDays(ctx context.Context, userID uuid.UUID, days int) error {
+	query := `
+		INSERT INTO user_settings (user_id, retention_days, updated_at)
+		VALUES ($1, $2, now())
+		ON CONFLICT (user_id) DO UPDATE
+		SET retention_days = EXCLUDED.retention_days, updated_at = EXCLUDED.updated_at
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, days); err != nil {
+		return fmt.Errorf("failed to set retention: %w", err)
+	}
+	return nil
+}
+
+// scanPurgedRecords reads id, audio_url, recording_id rows and closes them
+func scanPurgedRecords(rows *sql.Rows) ([]PurgedRecord, error) {
+	defer rows.Close()
+
+	var purged []PurgedRecord
+	for rows.Next() {
+		var rec PurgedRecord
+		if err := rows.Scan(&rec.ID, &rec.AudioURL, &rec.RecordingID); err != nil {
+			return nil, fmt.Errorf("failed to scan purged STT request: %w", err)
+		}
+		purged = append(purged, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating purged STT requests: %w", err)
+	}
+
+	return purged, nil
+}
+
 // GetByID retrieves an STT request by ID (excludes deleted records)
 func (r *postgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.STTRequest, error) {
-	query := `
+	return r.getOne(ctx, "id = $1", id)
+}
+
+// GetByRecordingID retrieves the non-deleted STT request synced from an in-memory recording ID.
+// Uses idx_stt_requests_recording_id (migrations/000005_add_recording_id_index.sql).
+func (r *postgresRepository) GetByRecordingID(ctx context.Context, recordingID string) (*model.STTRequest, error) {
+	return r.getOne(ctx, "metadata ? 'recording_id' AND metadata->>'recording_id' = $1", recordingID)
+}
+
+// GetByUploadKey retrieves a non-deleted STT request of a user by metadata.idempotency_key or
+// metadata.content_sha256. Uses the indexes of migrations/000008_add_upload_key_indexes.sql.
+func (r *postgresRepository) GetByUploadKey(ctx context.Context, userID uuid.UUID, key string, value string) (*model.STTRequest, error) {
+	if key != "idempotency_key" && key != "content_sha256" {
+		return nil, fmt.Errorf("unsupported upload key: %s", key)
+	}
+	where := fmt.Sprintf("user_id = $1 AND metadata ? '%[1]s' AND metadata->>'%[1]s' = $2", key)
+	return r.getOne(ctx, where, userID, value)
+}
+
+// getOne retrieves a single non-deleted STT request matching where (which uses args as $1, $2...)
+func (r *postgresRepository) getOne(ctx context.Context, where string, args ...interface{}) (*model.STTRequest, error) {
+	query := fmt.Sprintf(`
 		SELECT 
 			id, user_id, audio_url, audio_format, audio_duration_ms, audio_size_bytes,
 			stt_provider, language, model_version, title, transcript, confidence,
 			status, error_message, processing_time_ms, metadata, created_at
 		FROM stt_requests
-		WHERE id = $1 AND status != 'deleted'
-	`
+		WHERE %s AND status != 'deleted'
+	`, where)
 
 	var req model.STTRequest
 	var metadataJSON []byte
 	var createdAt time.Time
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(
 		&req.ID,
 		&req.UserID,
 		&req.AudioURL,
@@ -246,7 +513,7 @@ func (r *postgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("STT request not found: %w", err)
+		return nil, fmt.Errorf("%w: %w", ErrNotFound, err)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get STT request: %w", err)
@@ -266,20 +533,81 @@ func (r *postgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.
 	return &req, nil
 }
 
-// ListByUser retrieves STT requests for a user with pagination (excludes deleted records)
-func (r *postgresRepository) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]model.STTRequest, error) {
+// GetAnalysisJSON retrieves the metadata.ai_analysis JSON stored for an in-memory recording ID (excludes deleted records)
+func (r *postgresRepository) GetAnalysisJSON(ctx context.Context, recordingID string) ([]byte, error) {
 	query := `
+		SELECT metadata->'ai_analysis'
+		FROM stt_requests
+		WHERE metadata ? 'recording_id'
+			AND metadata->>'recording_id' = $1
+			AND metadata ? 'ai_analysis'
+			AND status != 'deleted'
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	var analysisJSON []byte
+	err := r.db.QueryRowContext(ctx, query, recordingID).Scan(&analysisJSON)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("analysis not found for recording %s", recordingID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get analysis: %w", err)
+	}
+
+	return analysisJSON, nil
+}
+
+// ListByUser retrieves STT requests for a user with pagination (excludes deleted records)
+func (r *postgresRepository) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int, opts ListOptions) ([]model.STTRequest, error) {
+	// Query shapes match the indexes in migrations/000004_add_user_list_indexes.sql:
+	//   status filter  -> idx_stt_requests_user_created (user_id, status, created_at DESC)
+	//   default        -> idx_stt_requests_user_active  (user_id, created_at DESC) WHERE status != 'deleted'
+	// Both return rows in created_at DESC order straight from the index, so no Sort is needed.
+	// Other sort keys use the indexes in migrations/000006_add_history_sort_indexes.sql.
+	orderBy, err := orderByClause(opts.Sort, opts.Order)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []interface{}{userID}
+	where := "user_id = $1 AND status != 'deleted'"
+	if opts.Status != "" {
+		args = append(args, opts.Status)
+		where = fmt.Sprintf("user_id = $1 AND status = $%d", len(args))
+	}
+
+	// Filter by tag using JSONB containment (metadata.tags @> ["tag"])
+	if opts.Tag != "" {
+		tagJSON, err := json.Marshal([]string{opts.Tag})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal tag filter: %w", err)
+		}
+		args = append(args, string(tagJSON))
+		where += fmt.Sprintf(" AND metadata->'tags' @> $%d::jsonb", len(args))
+	}
+
+	// Filter by extracted entity, matching its text case-insensitively
+	if opts.Entity != "" {
+		args = append(args, opts.Entity)
+		where += fmt.Sprintf(` AND EXISTS (
+			SELECT 1 FROM jsonb_array_elements(COALESCE(metadata->'ai_analysis'->'entities', '[]'::jsonb)) AS entity
+			WHERE lower(entity->>'text') = lower($%d))`, len(args))
+	}
+
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`
 		SELECT 
 			id, user_id, audio_url, audio_format, audio_duration_ms, audio_size_bytes,
 			stt_provider, language, model_version, title, transcript, confidence,
 			status, error_message, processing_time_ms, metadata, created_at
 		FROM stt_requests
-		WHERE user_id = $1 AND status != 'deleted'
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
-	`
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, where, orderBy, len(args)-1, len(args))
 
-	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query STT requests: %w", err)
 	}
@@ -430,3 +758,48 @@ func (r *postgresRepository) Search(ctx context.Context, userID uuid.UUID, searc
 
 	return requests, nil
 }
+
+// Stats aggregates the non-deleted records of a user per status, with their total audio duration
+// and the number of action items in metadata.ai_analysis.action_items
+func (r *postgresRepository) Stats(ctx context.Context, userID uuid.UUID) (*UserStats, error) {
+	query := `
+		SELECT
+			status,
+			COUNT(*),
+			COALESCE(SUM(audio_duration_ms), 0),
+			COALESCE(SUM(
+				CASE WHEN jsonb_typeof(metadata->'ai_analysis'->'action_items') = 'array'
+					THEN jsonb_array_length(metadata->'ai_analysis'->'action_items')
+					ELSE 0
+				END
+			), 0)
+		FROM stt_requests
+		WHERE user_id = $1 AND status != 'deleted'
+		GROUP BY status
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query STT stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := &UserStats{ByStatus: make(map[string]int)}
+	for rows.Next() {
+		var status string
+		var count int
+		var durationMs, actionItems int64
+		if err := rows.Scan(&status, &count, &durationMs, &actionItems); err != nil {
+			return nil, fmt.Errorf("failed to scan STT stats: %w", err)
+		}
+		stats.ByStatus[status] = count
+		stats.Total += count
+		stats.TotalDurationMs += durationMs
+		stats.TotalActionItems += int(actionItems)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating STT stats: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,420 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"noteme/internal/config"
+	"noteme/internal/db"
+	"noteme/internal/model"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// testRepo is backed by the throwaway PostgreSQL container started in TestMain
+var testRepo STTRepository
+
+// TestMain starts PostgreSQL in a container (needs Docker), applies the migrations and removes
+// the container when the tests end. These tests never connect to DATABASE_URL.
+func TestMain(m *testing.M) {
+	ctx := context.Background()
+	container, err := postgres.RunContainer(ctx,
+		testcontainers.WithImage("postgres:16-alpine"),
+		postgres.WithDatabase("noteme_test"),
+		postgres.WithUsername("noteme"),
+		postgres.WithPassword("noteme"),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(time.Minute)),
+	)
+	if err != nil {
+		log.Fatalf("failed to start PostgreSQL container: %v", err)
+	}
+
+	code, err := runWithDatabase(ctx, container, m)
+	if terminateErr := container.Terminate(ctx); terminateErr != nil {
+		log.Printf("failed to remove PostgreSQL container: %v", terminateErr)
+	}
+	if err != nil {
+		log.Fatalf("failed to prepare test database: %v", err)
+	}
+	os.Exit(code)
+}
+
+// runWithDatabase connects the db package to container, migrates it and runs the tests
+func runWithDatabase(ctx context.Context, container *postgres.PostgresContainer, m *testing.M) (int, error) {
+	url, err := container.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		return 0, err
+	}
+	cfg := config.Default()
+	cfg.DatabaseURL = url
+	cfg.DBPingRetries = 1
+	if err := db.Init(cfg); err != nil {
+		return 0, err
+	}
+	defer db.Close()
+	if err := db.Migrate(ctx); err != nil {
+		return 0, err
+	}
+	testRepo = NewPostgresRepository()
+	return m.Run(), nil
+}
+
+// newTestRepository returns the repository of the test container
+func newTestRepository(t *testing.T) STTRepository {
+	t.Helper()
+	return testRepo
+}
+
+// newTestUser returns a fresh user ID whose rows and settings are removed when the test ends
+func newTestUser(t *testing.T, repo STTRepository) uuid.UUID {
+	t.Helper()
+	userID := uuid.New()
+	t.Cleanup(func() {
+		ctx := context.Background()
+		if _, err := repo.PurgeAllByUser(ctx, userID); err != nil {
+			t.Errorf("cleanup: %v", err)
+		}
+		if _, err := db.DB.ExecContext(ctx, "DELETE FROM user_settings WHERE user_id = $1", userID); err != nil {
+			t.Errorf("cleanup: %v", err)
+		}
+	})
+	return userID
+}
+
+// createTestRequest inserts a processed row of userID created at createdAt
+func createTestRequest(t *testing.T, repo STTRepository, userID uuid.UUID, title string, createdAt time.Time, metadata map[string]interface{}) *model.STTRequest {
+	t.Helper()
+	transcript := "xin chào"
+	confidence := 0.9
+	req := &model.STTRequest{
+		ID:         uuid.New(),
+		UserID:     userID,
+		AudioURL:   "uploads/" + title + ".wav",
+		Provider:   "mock",
+		Title:      &title,
+		Transcript: &transcript,
+		Confidence: &confidence,
+		Status:     "processed",
+		Metadata:   metadata,
+		CreatedAt:  createdAt,
+	}
+	if err := repo.Create(context.Background(), req); err != nil {
+		t.Fatalf("Create(%s): %v", title, err)
+	}
+	return req
+}
+
+func TestCreateGetAndSoftDelete(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	userID := newTestUser(t, repo)
+
+	unscored := -1.0
+	title := "unscored"
+	req := &model.STTRequest{
+		ID:         uuid.New(),
+		UserID:     userID,
+		AudioURL:   "uploads/unscored.wav",
+		Provider:   "mock",
+		Title:      &title,
+		Confidence: &unscored,
+		Status:     "uploaded",
+		Metadata:   map[string]interface{}{"recording_id": "rec_create"},
+		CreatedAt:  time.Now(),
+	}
+	if err := repo.Create(ctx, req); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, req.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.UserID != userID || got.Status != "uploaded" || got.Title == nil || *got.Title != title {
+		t.Errorf("GetByID = %+v, want the created row", got)
+	}
+	if got.Confidence != nil {
+		t.Errorf("Confidence = %v, want NULL for the unscored sentinel", *got.Confidence)
+	}
+	if got.Metadata["recording_id"] != "rec_create" {
+		t.Errorf("metadata.recording_id = %v, want rec_create", got.Metadata["recording_id"])
+	}
+
+	if err := repo.Delete(ctx, req.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, req.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetByID after Delete: err = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := repo.GetByRecordingID(ctx, "rec_create"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetByRecordingID after Delete: err = %v, want sql.ErrNoRows", err)
+	}
+	if err := repo.Delete(ctx, req.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete: err = %v, want ErrNotFound", err)
+	}
+
+	// Purge still removes the soft deleted row, then reports it missing
+	if _, err := repo.Purge(ctx, req.ID); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if _, err := repo.Purge(ctx, req.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Purge: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestUpdateResultMergesMetadata(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	userID := newTestUser(t, repo)
+
+	req := createTestRequest(t, repo, userID, "merge", time.Now(), map[string]interface{}{
+		"recording_id": "rec_merge",
+		"tags":         []string{"work"},
+	})
+
+	transcript := "đã sửa"
+	update := &model.STTRequest{
+		ID:         req.ID,
+		Transcript: &transcript,
+		Status:     "processed",
+		Metadata: map[string]interface{}{
+			"ai_analysis": map[string]interface{}{"summary": []interface{}{"Chốt kế hoạch"}},
+		},
+	}
+	if err := repo.UpdateResult(ctx, update); err != nil {
+		t.Fatalf("UpdateResult: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, req.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Transcript == nil || *got.Transcript != transcript {
+		t.Errorf("transcript = %v, want %q", got.Transcript, transcript)
+	}
+	if got.Confidence != nil {
+		t.Errorf("confidence = %v, want NULL after a transcript update without a score", *got.Confidence)
+	}
+	if got.Metadata["recording_id"] != "rec_merge" {
+		t.Errorf("metadata.recording_id = %v, want it kept by the merge", got.Metadata["recording_id"])
+	}
+	if tags, _ := got.Metadata["tags"].([]interface{}); len(tags) != 1 || tags[0] != "work" {
+		t.Errorf("metadata.tags = %v, want [work] kept by the merge", got.Metadata["tags"])
+	}
+	analysis, _ := got.Metadata["ai_analysis"].(map[string]interface{})
+	if summary, _ := analysis["summary"].([]interface{}); len(summary) != 1 || summary[0] != "Chốt kế hoạch" {
+		t.Errorf("metadata.ai_analysis.summary = %v, want the new analysis", analysis["summary"])
+	}
+
+	// A wrongly typed known key is rejected before reaching the database
+	bad := &model.STTRequest{ID: req.ID, Metadata: map[string]interface{}{"tags": "work"}}
+	if err := repo.UpdateResult(ctx, bad); err == nil {
+		t.Error("UpdateResult with metadata.tags as a string: want an error")
+	}
+}
+
+// TestUpdateResultConcurrentMerges runs parallel UpdateResult calls that each add a different
+// metadata key: the JSONB merge happens in the UPDATE itself, so no write may be lost
+func TestUpdateResultConcurrentMerges(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	userID := newTestUser(t, repo)
+	req := createTestRequest(t, repo, userID, "concurrent", time.Now(), map[string]interface{}{"recording_id": "rec_concurrent"})
+
+	const writers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			update := &model.STTRequest{
+				ID:       req.ID,
+				Status:   "processed",
+				Metadata: map[string]interface{}{fmt.Sprintf("writer_%d", i): i},
+			}
+			errs <- repo.UpdateResult(ctx, update)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("UpdateResult: %v", err)
+		}
+	}
+
+	got, err := repo.GetByID(ctx, req.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Metadata["recording_id"] != "rec_concurrent" {
+		t.Errorf("metadata.recording_id = %v, want it kept", got.Metadata["recording_id"])
+	}
+	for i := 0; i < writers; i++ {
+		if _, ok := got.Metadata[fmt.Sprintf("writer_%d", i)]; !ok {
+			t.Errorf("metadata.writer_%d lost by a concurrent update", i)
+		}
+	}
+}
+
+func TestListByUserAndSearch(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	userID := newTestUser(t, repo)
+	otherID := newTestUser(t, repo)
+
+	now := time.Now()
+	older := createTestRequest(t, repo, userID, "100% done", now.Add(-2*time.Hour), map[string]interface{}{"tags": []string{"work"}})
+	newer := createTestRequest(t, repo, userID, "100 done", now.Add(-time.Hour), map[string]interface{}{
+		"ai_analysis": map[string]interface{}{"action_items": []interface{}{"Gửi báo cáo_tuần"}},
+	})
+	deleted := createTestRequest(t, repo, userID, "deleted 100%", now, nil)
+	createTestRequest(t, repo, otherID, "100% other user", now, nil)
+	if err := repo.Delete(ctx, deleted.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	list, err := repo.ListByUser(ctx, userID, 10, 0, ListOptions{})
+	if err != nil {
+		t.Fatalf("ListByUser: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != newer.ID || list[1].ID != older.ID {
+		t.Errorf("ListByUser = %v, want the two non-deleted rows newest first", requestIDs(list))
+	}
+
+	list, err = repo.ListByUser(ctx, userID, 10, 0, ListOptions{Tag: "work"})
+	if err != nil {
+		t.Fatalf("ListByUser by tag: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != older.ID {
+		t.Errorf("ListByUser(tag=work) = %v, want only the tagged row", requestIDs(list))
+	}
+
+	list, err = repo.ListByUser(ctx, userID, 1, 1, ListOptions{})
+	if err != nil {
+		t.Fatalf("ListByUser page 2: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != older.ID {
+		t.Errorf("ListByUser(limit=1, offset=1) = %v, want the older row", requestIDs(list))
+	}
+
+	tests := []struct {
+		query string
+		want  []uuid.UUID
+	}{
+		{"100%", []uuid.UUID{older.ID}},           // % is literal, deleted and other users' rows excluded
+		{"100", []uuid.UUID{newer.ID, older.ID}},  // plain substring of both titles
+		{"báo cáo_tuần", []uuid.UUID{newer.ID}},   // ai_analysis.action_items, _ is literal
+		{"báo cáo-tuần", nil},                     // _ does not match any character
+		{"DONE", []uuid.UUID{newer.ID, older.ID}}, // case-insensitive
+		{"không có", nil},
+	}
+	for _, tt := range tests {
+		got, err := repo.Search(ctx, userID, tt.query, 10, 0)
+		if err != nil {
+			t.Fatalf("Search(%q): %v", tt.query, err)
+		}
+		if ids := requestIDs(got); !equalIDs(ids, tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, ids, tt.want)
+		}
+	}
+}
+
+func TestDeleteExpiredRetention(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	defaultUser := newTestUser(t, repo)
+	keepForever := newTestUser(t, repo)
+	shortRetention := newTestUser(t, repo)
+
+	if err := repo.SetRetentionDays(ctx, keepForever, 0); err != nil {
+		t.Fatalf("SetRetentionDays(0): %v", err)
+	}
+	if err := repo.SetRetentionDays(ctx, shortRetention, 7); err != nil {
+		t.Fatalf("SetRetentionDays(7): %v", err)
+	}
+
+	now := time.Now()
+	expiredDefault := createTestRequest(t, repo, defaultUser, "old", now.AddDate(0, 0, -40), map[string]interface{}{"recording_id": "rec_old"})
+	freshDefault := createTestRequest(t, repo, defaultUser, "fresh", now.AddDate(0, 0, -10), nil)
+	forever := createTestRequest(t, repo, keepForever, "forever", now.AddDate(0, 0, -400), nil)
+	expiredShort := createTestRequest(t, repo, shortRetention, "short", now.AddDate(0, 0, -10), nil)
+
+	expired, err := repo.DeleteExpired(ctx, 30, false, 1000)
+	if err != nil {
+		t.Fatalf("DeleteExpired: %v", err)
+	}
+	got := make(map[uuid.UUID]PurgedRecord)
+	for _, rec := range expired {
+		got[rec.ID] = rec
+	}
+	for _, id := range []uuid.UUID{expiredDefault.ID, expiredShort.ID} {
+		if _, ok := got[id]; !ok {
+			t.Errorf("DeleteExpired did not expire %s", id)
+		}
+	}
+	for _, id := range []uuid.UUID{freshDefault.ID, forever.ID} {
+		if _, ok := got[id]; ok {
+			t.Errorf("DeleteExpired expired %s, want it kept", id)
+		}
+	}
+	if rec := got[expiredDefault.ID]; rec.AudioURL != expiredDefault.AudioURL || rec.RecordingID != "rec_old" {
+		t.Errorf("expired record = %+v, want its audio_url and recording_id", rec)
+	}
+
+	// Soft deleted rows are hidden but still present until purged
+	if _, err := repo.GetByID(ctx, expiredDefault.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetByID of an expired row: err = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := repo.GetByID(ctx, forever.ID); err != nil {
+		t.Errorf("GetByID of a row kept forever: %v", err)
+	}
+
+	purged, err := repo.DeleteExpired(ctx, 30, true, 1000)
+	if err != nil {
+		t.Fatalf("DeleteExpired(purge): %v", err)
+	}
+	purgedIDs := make(map[uuid.UUID]bool)
+	for _, rec := range purged {
+		purgedIDs[rec.ID] = true
+	}
+	if !purgedIDs[expiredDefault.ID] || !purgedIDs[expiredShort.ID] || purgedIDs[forever.ID] {
+		t.Errorf("DeleteExpired(purge) = %v, want the soft deleted rows purged and the kept row left", purged)
+	}
+}
+
+// requestIDs returns the IDs of requests in order
+func requestIDs(requests []model.STTRequest) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, req := range requests {
+		ids = append(ids, req.ID)
+	}
+	return ids
+}
+
+func equalIDs(a, b []uuid.UUID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
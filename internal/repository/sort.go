@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"fmt"
+)
+
+// Sort keys accepted by ListByUser (ListOptions.Sort)
+const (
+	SortCreatedAt  = "created_at"
+	SortDuration   = "duration"
+	SortConfidence = "confidence"
+	SortTitle      = "title"
+)
+
+// Sort orders accepted by ListByUser (ListOptions.Order)
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// sortColumns maps the public sort keys to columns. Only these values ever reach ORDER BY.
+var sortColumns = map[string]string{
+	SortCreatedAt:  "created_at",
+	SortDuration:   "audio_duration_ms",
+	SortConfidence: "confidence",
+	SortTitle:      "title",
+}
+
+// ValidateSort checks sort and order against the allowlist; empty values mean the default (created_at desc)
+func ValidateSort(sort, order string) error {
+	_, err := orderByClause(sort, order)
+	return err
+}
+
+// orderByClause builds the ORDER BY for a sort key and order. Records without a value
+// (no duration, confidence or title yet) come last in both orders, and created_at DESC
+// breaks ties so pages stay stable.
+func orderByClause(sort, order string) (string, error) {
+	if sort == "" {
+		sort = SortCreatedAt
+	}
+	column, ok := sortColumns[sort]
+	if !ok {
+		return "", fmt.Errorf("invalid sort %q (use created_at, duration, confidence or title)", sort)
+	}
+
+	direction := "DESC"
+	switch order {
+	case "", OrderDesc:
+	case OrderAsc:
+		direction = "ASC"
+	default:
+		return "", fmt.Errorf("invalid order %q (use asc or desc)", order)
+	}
+
+	// created_at is never NULL; keep the default plain so it matches the list indexes
+	if sort == SortCreatedAt {
+		return "created_at " + direction, nil
+	}
+	return fmt.Sprintf("%s %s NULLS LAST, created_at DESC", column, direction), nil
+}
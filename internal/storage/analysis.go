@@ -1,13 +1,20 @@
 package storage
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"noteme/internal/ai"
 	"sync"
+	"time"
 )
 
 var (
-	analyses   = make(map[string]*ai.AnalysisResult)
-	muAnalysis sync.Mutex
+	analyses        = make(map[string]*ai.AnalysisResult)
+	analysisSavedAt = make(map[string]time.Time)
+	muAnalysis      sync.Mutex
+
+	// analysesVersion bumps on every change so derived caches can detect staleness
+	analysesVersion uint64
 )
 
 // SaveAnalysis saves analysis result for a recording
@@ -15,6 +22,32 @@ func SaveAnalysis(recordingID string, result *ai.AnalysisResult) {
 	muAnalysis.Lock()
 	defer muAnalysis.Unlock()
 	analyses[recordingID] = result
+	touchAnalysisLocked(recordingID)
+	evictOldestAnalysesLocked()
+	analysesVersion++
+	ai.InvalidateAskContextCache()
+}
+
+// DeleteAnalysis removes the analysis of a recording
+func DeleteAnalysis(recordingID string) bool {
+	muAnalysis.Lock()
+	defer muAnalysis.Unlock()
+	delete(analysesV1, recordingID)
+	if _, ok := analyses[recordingID]; !ok {
+		return false
+	}
+	delete(analyses, recordingID)
+	delete(analysisSavedAt, recordingID)
+	analysesVersion++
+	ai.InvalidateAskContextCache()
+	return true
+}
+
+// AnalysesVersion returns a counter that changes whenever any analysis changes
+func AnalysesVersion() uint64 {
+	muAnalysis.Lock()
+	defer muAnalysis.Unlock()
+	return analysesVersion
 }
 
 // GetAnalysis retrieves analysis result for a recording
@@ -30,14 +63,34 @@ func GetAnalysis(recordingID string) (*ai.AnalysisResult, bool) {
 	return &resultCopy, true
 }
 
-// GetAllAnalyses retrieves all analysis results
-func GetAllAnalyses() map[string]*ai.AnalysisResult {
+// HashTranscript computes the SHA-256 hex digest of a transcript, stored with its analysis
+// so clients can tell whether the analysis still matches the current transcript
+func HashTranscript(transcript string) string {
+	sum := sha256.Sum256([]byte(transcript))
+	return hex.EncodeToString(sum[:])
+}
+
+// GetAnalysesByUser retrieves the analysis results of the recordings owned by userID.
+// Analyses whose recording is no longer in memory have no known owner and are left out.
+func GetAnalysesByUser(userID string) map[string]*ai.AnalysisResult {
+	mu.Lock()
+	owned := make(map[string]bool)
+	for id, rec := range recordings {
+		if rec.UserID == userID {
+			owned[id] = true
+		}
+	}
+	mu.Unlock()
+
 	muAnalysis.Lock()
 	defer muAnalysis.Unlock()
 
 	// Return a copy of the map
 	result := make(map[string]*ai.AnalysisResult)
 	for k, v := range analyses {
+		if !owned[k] {
+			continue
+		}
 		resultCopy := *v
 		result[k] = &resultCopy
 	}
@@ -0,0 +1,33 @@
+package storage
+
+import "noteme/internal/ai"
+
+// analysisV1Entry is a cached V1 analysis and the transcript it was built from
+type analysisV1Entry struct {
+	result         *ai.AnalysisResultV1
+	transcriptHash string // HashTranscript of the source transcript
+}
+
+// analysesV1 is guarded by muAnalysis and removed together with the legacy analysis
+var analysesV1 = make(map[string]analysisV1Entry)
+
+// SaveAnalysisV1 caches the V1-format analysis of a recording's transcript
+func SaveAnalysisV1(recordingID string, transcript string, result *ai.AnalysisResultV1) {
+	muAnalysis.Lock()
+	defer muAnalysis.Unlock()
+	analysesV1[recordingID] = analysisV1Entry{result: result, transcriptHash: HashTranscript(transcript)}
+}
+
+// GetAnalysisV1 returns the cached V1 analysis, or false when there is none for this transcript
+// (e.g. the recording was re-processed since)
+func GetAnalysisV1(recordingID string, transcript string) (*ai.AnalysisResultV1, bool) {
+	muAnalysis.Lock()
+	defer muAnalysis.Unlock()
+	entry, ok := analysesV1[recordingID]
+	if !ok || entry.transcriptHash != HashTranscript(transcript) {
+		return nil, false
+	}
+	// Return a copy to avoid race conditions
+	resultCopy := *entry.result
+	return &resultCopy, true
+}
@@ -3,42 +3,117 @@ package storage
 import (
 	"fmt"
 	"mime/multipart"
+	"noteme/internal/stt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 	"time"
 )
 
 type Recording struct {
-	ID          string
-	Path        string
-	Status      string // uploaded, processing, processed, failed
-	Duration    int    // in seconds
-	Size        int64  // file size in bytes
-	CreatedAt   string
-	Transcript  string
-	Confidence  float64
-	Error       string
+	ID             string
+	UserID         string // owner: the authenticated user that uploaded it
+	Path           string
+	Status         string // uploaded, processing, processed, failed
+	Duration       int    // in seconds
+	Size           int64  // file size in bytes
+	CreatedAt      string
+	Transcript     string
+	Confidence     float64 // normalized 0-1, or stt.ConfidenceUnavailable when the provider gave no score
+	RawConfidence  float64 // confidence on the provider's own scale
+	Error          string
+	ContentHash    string         // SHA-256 of the uploaded audio (hex)
+	IdempotencyKey string         // client-provided Idempotency-Key, if any
+	LowConfidence  bool           // STT confidence below MIN_CONFIDENCE; transcript may be unreliable
+	ProcessingTime int            // STT transcription time in milliseconds
+	CleaningTime   int            // AI transcript cleaning time in milliseconds
+	CleaningSkip   string         // why AI cleaning was skipped (CleaningSkipped*), empty when the transcript was cleaned
+	Language       string         // transcript language detected by STT (e.g., "vi-VN")
+	Segments       []stt.Segment  // speaker-labeled segments when diarization was requested
+	Sentences      []stt.Sentence // timed sentences when the provider reported word timings
+	RawTranscript  string         // STT text before profanity redaction, set only when filtering was requested
+	DecodedWords   []string       // "wrong → right" corrections made by AI cleaning
+	Corrections    []string       // "wrong → right" fixes the user reported via /corrections
+	Provider       string         // STT provider that produced Transcript
+	DeleteAudio    bool           // remove the audio file once STT succeeds (transcript-only upload)
+	AudioDeleted   bool           // the audio file was removed after processing; Path is empty
+	Peaks          []float64      // cached waveform peaks at full resolution (see audio.ComputePeaks)
+	AudioVersion   int            // bumped by ReplaceAudio so probes of the previous audio are dropped
+
+	// PromptExperiment and PromptVariant record the A/B prompt variant used for cleaning and analysis
+	PromptExperiment string
+	PromptVariant    string
+
+	// OriginalTranscript is the first transcript, kept when the recording is re-run with another provider
+	OriginalTranscript *TranscriptVersion
+}
+
+// TranscriptVersion is a transcript together with the provider that produced it
+type TranscriptVersion struct {
+	Provider   string  `json:"provider"`
+	Transcript string  `json:"transcript"`
+	Confidence float64 `json:"confidence"`
 }
 
 var (
 	recordings = make(map[string]*Recording)
 	mu         sync.Mutex
+
+	// uploadDir is where audio files are saved, set once at startup by InitUploadDir
+	uploadDir = "uploads"
 )
 
-// SaveAudio saves uploaded audio file and returns recording ID
-func SaveAudio(file *multipart.FileHeader) (string, error) {
+// InitUploadDir creates the uploads directory (UPLOAD_DIR) and its partial/ subdirectory
+// and checks that it is writable, so a bad mount fails at startup instead of on the first upload
+func InitUploadDir(dir string) error {
+	if err := os.MkdirAll(filepath.Join(dir, "partial"), 0755); err != nil {
+		return fmt.Errorf("failed to create uploads directory %s: %w", dir, err)
+	}
+	probe, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("uploads directory %s is not writable: %w", dir, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	uploadDir = dir
+	return nil
+}
+
+// UploadDir returns the directory uploaded audio is saved in
+func UploadDir() string {
+	return uploadDir
+}
+
+// SaveAudio saves uploaded audio file for a user and returns recording ID
+func SaveAudio(file *multipart.FileHeader, userID string) (string, error) {
 	id := fmt.Sprintf("rec_%d", time.Now().UnixNano())
-	dst := filepath.Join("uploads", id+"_"+file.Filename)
+	dst := filepath.Join(uploadDir, id+"_"+safeFilename(file.Filename))
 
-	if err := os.MkdirAll("uploads", 0755); err != nil {
-		return "", fmt.Errorf("failed to create uploads directory: %w", err)
+	if err := saveMultipartFile(file, dst); err != nil {
+		return "", fmt.Errorf("failed to save file: %w", err)
 	}
 
-	if err := saveMultipartFile(file, dst); err != nil {
+	registerRecording(id, dst, userID)
+	return id, nil
+}
+
+// SaveAudioBytes saves an in-memory audio payload (e.g. decoded base64) and creates a recording owned by userID
+func SaveAudioBytes(name string, data []byte, userID string) (string, error) {
+	id := fmt.Sprintf("rec_%d", time.Now().UnixNano())
+	dst := filepath.Join(uploadDir, id+"_"+safeFilename(name))
+
+	if err := os.WriteFile(dst, data, 0644); err != nil {
 		return "", fmt.Errorf("failed to save file: %w", err)
 	}
 
+	registerRecording(id, dst, userID)
+	return id, nil
+}
+
+// registerRecording adds a freshly saved audio file to the in-memory store
+func registerRecording(id, dst, userID string) {
 	// Get file size
 	fileInfo, err := os.Stat(dst)
 	var fileSize int64
@@ -49,6 +124,7 @@ func SaveAudio(file *multipart.FileHeader) (string, error) {
 	mu.Lock()
 	recordings[id] = &Recording{
 		ID:        id,
+		UserID:    userID,
 		Path:      dst,
 		Status:    "uploaded",
 		Size:      fileSize,
@@ -56,7 +132,8 @@ func SaveAudio(file *multipart.FileHeader) (string, error) {
 	}
 	mu.Unlock()
 
-	return id, nil
+	// Keep the map bounded (oldest recordings go first)
+	evictOldestRecordings(id)
 }
 
 // GetRecording retrieves a recording by ID
@@ -72,6 +149,18 @@ func GetRecording(id string) (*Recording, bool) {
 	return &recCopy, true
 }
 
+// DeleteRecording removes a recording and its upload dedupe keys from memory.
+// The audio file on disk is left untouched (DB deletes are soft deletes).
+func DeleteRecording(id string) bool {
+	mu.Lock()
+	_, ok := recordings[id]
+	delete(recordings, id)
+	mu.Unlock()
+
+	removeUploadKeys(id)
+	return ok
+}
+
 // UpdateStatus updates the status of a recording
 func UpdateStatus(id, status string) {
 	mu.Lock()
@@ -81,6 +170,20 @@ func UpdateStatus(id, status string) {
 	}
 }
 
+// TryBeginProcessing atomically moves a recording from status expected to "processing" and
+// reports whether it did. It fails when another request changed the status since the caller
+// read expected, so only one of two concurrent requests starts work on a recording.
+func TryBeginProcessing(id, expected string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	rec, ok := recordings[id]
+	if !ok || rec.Status != expected {
+		return false
+	}
+	rec.Status = "processing"
+	return true
+}
+
 // UpdateTranscript updates transcript and confidence
 func UpdateTranscript(id string, transcript string, confidence float64) {
 	mu.Lock()
@@ -91,6 +194,140 @@ func UpdateTranscript(id string, transcript string, confidence float64) {
 	}
 }
 
+// UpdateRawConfidence records the confidence as reported by the STT provider
+func UpdateRawConfidence(id string, raw float64) {
+	mu.Lock()
+	defer mu.Unlock()
+	if rec, ok := recordings[id]; ok {
+		rec.RawConfidence = raw
+	}
+}
+
+// UpdateLowConfidence sets the low-confidence warning flag
+func UpdateLowConfidence(id string, low bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	if rec, ok := recordings[id]; ok {
+		rec.LowConfidence = low
+	}
+}
+
+// UpdateLanguage records the detected transcript language
+func UpdateLanguage(id string, language string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if rec, ok := recordings[id]; ok {
+		rec.Language = language
+	}
+}
+
+// UpdateProvider records the STT provider that produced the current transcript
+func UpdateProvider(id string, provider string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if rec, ok := recordings[id]; ok {
+		rec.Provider = provider
+	}
+}
+
+// KeepOriginalTranscript saves the current transcript before a re-run. Only the first
+// transcript is kept, so repeated re-runs can always be compared against the original.
+func KeepOriginalTranscript(id string, defaultProvider string) {
+	mu.Lock()
+	defer mu.Unlock()
+	rec, ok := recordings[id]
+	if !ok || rec.Transcript == "" || rec.OriginalTranscript != nil {
+		return
+	}
+	provider := rec.Provider
+	if provider == "" {
+		provider = defaultProvider
+	}
+	rec.OriginalTranscript = &TranscriptVersion{
+		Provider:   provider,
+		Transcript: rec.Transcript,
+		Confidence: rec.Confidence,
+	}
+}
+
+// UpdateDecodedWords stores the corrections AI cleaning made to the transcript
+func UpdateDecodedWords(id string, decodedWords []string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if rec, ok := recordings[id]; ok {
+		rec.DecodedWords = decodedWords
+	}
+}
+
+// AddUserCorrections appends the user's "wrong → right" fixes to a recording, skipping ones it already has
+func AddUserCorrections(id string, corrections []string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	rec, ok := recordings[id]
+	if !ok {
+		return false
+	}
+	for _, c := range corrections {
+		if !slices.Contains(rec.Corrections, c) {
+			rec.Corrections = append(rec.Corrections, c)
+		}
+	}
+	return true
+}
+
+// UpdateRawTranscript keeps the unredacted STT transcript of a profanity-filtered recording
+func UpdateRawTranscript(id string, transcript string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if rec, ok := recordings[id]; ok {
+		rec.RawTranscript = transcript
+	}
+}
+
+// UpdateSegments stores speaker-labeled segments from diarization
+func UpdateSegments(id string, segments []stt.Segment) {
+	mu.Lock()
+	defer mu.Unlock()
+	if rec, ok := recordings[id]; ok {
+		rec.Segments = segments
+	}
+}
+
+// UpdateSentences stores the timed sentences of the transcript
+func UpdateSentences(id string, sentences []stt.Sentence) {
+	mu.Lock()
+	defer mu.Unlock()
+	if rec, ok := recordings[id]; ok {
+		rec.Sentences = sentences
+	}
+}
+
+// UpdateProcessingTime records STT and AI cleaning durations in milliseconds
+func UpdateProcessingTime(id string, processingMs, cleaningMs int) {
+	mu.Lock()
+	defer mu.Unlock()
+	if rec, ok := recordings[id]; ok {
+		rec.ProcessingTime = processingMs
+		rec.CleaningTime = cleaningMs
+	}
+}
+
+// Reasons AI cleaning was skipped for a transcript (Recording.CleaningSkip)
+const (
+	CleaningSkippedHighConfidence  = "high_confidence"   // confidence reached CLEAN_SKIP_CONFIDENCE
+	CleaningSkippedLowConfidence   = "low_confidence"    // below MIN_CONFIDENCE with LOW_CONFIDENCE_SKIP_AI
+	CleaningSkippedAINotConfigured = "ai_not_configured" // no OpenAI key or ENABLE_AI=false
+)
+
+// UpdateCleaningSkipReason records why AI cleaning was skipped, or "" when it ran
+func UpdateCleaningSkipReason(id, reason string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if rec, ok := recordings[id]; ok {
+		rec.CleaningSkip = reason
+	}
+}
+
 // UpdateError updates error message
 func UpdateError(id string, errorMsg string) {
 	mu.Lock()
@@ -100,12 +337,98 @@ func UpdateError(id string, errorMsg string) {
 	}
 }
 
-// UpdateDuration updates recording duration
-func UpdateDuration(id string, duration int) {
+// UpdateDuration sets the duration probed from the audio at AudioVersion version.
+// Probes run on a copy of the recording without holding the lock, so the update is
+// dropped (and false returned) when the audio was replaced in the meantime, e.g. by
+// an append; otherwise a slow probe of the old file would overwrite the new duration.
+func UpdateDuration(id string, version, duration int) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	rec, ok := recordings[id]
+	if !ok || rec.AudioVersion != version {
+		return false
+	}
+	rec.Duration = duration
+	return true
+}
+
+// SetDeleteAudio marks a recording as transcript-only: its audio is removed once STT succeeds
+func SetDeleteAudio(id string, deleteAudio bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	if rec, ok := recordings[id]; ok {
+		rec.DeleteAudio = deleteAudio
+	}
+}
+
+// MarkAudioDeleted records that the audio file of a recording has been removed
+func MarkAudioDeleted(id string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if rec, ok := recordings[id]; ok {
+		rec.Path = ""
+		rec.AudioDeleted = true
+	}
+}
+
+// SetPeaks caches the waveform peaks of a recording's audio
+func SetPeaks(id string, peaks []float64) {
+	mu.Lock()
+	defer mu.Unlock()
+	if rec, ok := recordings[id]; ok {
+		rec.Peaks = peaks
+	}
+}
+
+// SaveAudioClip saves a clip to be appended to a recording next to its audio and returns its path
+func SaveAudioClip(id string, file *multipart.FileHeader) (string, error) {
+	dst := filepath.Join(uploadDir, fmt.Sprintf("%s_clip_%d_%s", id, time.Now().UnixNano(), safeFilename(file.Filename)))
+
+	if err := saveMultipartFile(file, dst); err != nil {
+		return "", fmt.Errorf("failed to save file: %w", err)
+	}
+	return dst, nil
+}
+
+// ReplaceAudio points a recording at new audio (e.g. after a clip was appended) and resets it
+// to "uploaded" so it can be processed again. Duration and cached peaks are cleared, and
+// AudioVersion is bumped so a probe still running on the old audio is ignored.
+func ReplaceAudio(id, path string) {
+	var fileSize int64
+	if fileInfo, err := os.Stat(path); err == nil {
+		fileSize = fileInfo.Size()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if rec, ok := recordings[id]; ok {
+		rec.Path = path
+		rec.Size = fileSize
+		rec.Duration = 0
+		rec.Peaks = nil
+		rec.AudioVersion++
+		rec.Status = "uploaded"
+		rec.Error = ""
+	}
+}
+
+// UpdatePromptVariant records the A/B prompt experiment variant assigned to a recording
+func UpdatePromptVariant(id, experiment, variant string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if rec, ok := recordings[id]; ok {
+		rec.PromptExperiment = experiment
+		rec.PromptVariant = variant
+	}
+}
+
+// UpdateUploadKeys records the content hash and idempotency key of a recording
+func UpdateUploadKeys(id, contentHash, idempotencyKey string) {
 	mu.Lock()
 	defer mu.Unlock()
 	if rec, ok := recordings[id]; ok {
-		rec.Duration = duration
+		rec.ContentHash = contentHash
+		rec.IdempotencyKey = idempotencyKey
 	}
 }
 
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// newTestRecording registers a recording backed by a small file in a temporary directory
+func newTestRecording(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "note.wav")
+	if err := os.WriteFile(path, make([]byte, 2000), 0644); err != nil {
+		t.Fatal(err)
+	}
+	id := "rec_" + t.Name()
+	registerRecording(id, path, "user-1")
+	t.Cleanup(func() { DeleteRecording(id) })
+	return id
+}
+
+// TestConcurrentRecordingUpdates updates different fields of one recording from many goroutines
+// while others read it. Run with -race: every update must land and reads must not race writes.
+func TestConcurrentRecordingUpdates(t *testing.T) {
+	id := newTestRecording(t)
+	rec, _ := GetRecording(id)
+	version := rec.AudioVersion
+
+	const rounds = 100
+	var wg sync.WaitGroup
+	updates := []func(i int){
+		func(i int) { UpdateTranscript(id, fmt.Sprintf("transcript %d", i), 0.9) },
+		func(i int) { UpdateDuration(id, version, i) },
+		func(i int) { UpdateLanguage(id, "vi-VN") },
+		func(i int) { SetPeaks(id, []float64{float64(i)}) },
+		func(i int) { UpdateProcessingTime(id, i, i) },
+	}
+	for _, update := range updates {
+		wg.Add(1)
+		go func(update func(int)) {
+			defer wg.Done()
+			for i := 1; i <= rounds; i++ {
+				update(i)
+			}
+		}(update)
+	}
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				if rec, ok := GetRecording(id); ok {
+					_ = rec.Transcript + rec.Language
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	got, ok := GetRecording(id)
+	if !ok {
+		t.Fatal("recording disappeared")
+	}
+	want := fmt.Sprintf("transcript %d", rounds)
+	if got.Transcript != want || got.Duration != rounds || got.Language != "vi-VN" ||
+		len(got.Peaks) != 1 || got.Peaks[0] != rounds || got.ProcessingTime != rounds {
+		t.Errorf("after concurrent updates got transcript %q, duration %d, language %q, peaks %v, processing %d; want the last write of each",
+			got.Transcript, got.Duration, got.Language, got.Peaks, got.ProcessingTime)
+	}
+}
+
+// TestUpdateDurationDropsStaleProbe checks that a probe of audio replaced meanwhile is ignored
+func TestUpdateDurationDropsStaleProbe(t *testing.T) {
+	id := newTestRecording(t)
+	rec, _ := GetRecording(id)
+
+	ReplaceAudio(id, rec.Path)
+	if UpdateDuration(id, rec.AudioVersion, 42) {
+		t.Error("UpdateDuration with the version before ReplaceAudio: want false")
+	}
+	current, _ := GetRecording(id)
+	if current.Duration != 0 {
+		t.Errorf("Duration = %d after a stale probe, want 0", current.Duration)
+	}
+	if !UpdateDuration(id, current.AudioVersion, 7) {
+		t.Error("UpdateDuration with the current version: want true")
+	}
+	if current, _ = GetRecording(id); current.Duration != 7 {
+		t.Errorf("Duration = %d, want 7", current.Duration)
+	}
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxFilenameRunes caps the client part of a stored filename; the recording ID is prepended
+	maxFilenameRunes = 100
+	// maxExtRunes caps the extension kept when a long name is shortened
+	maxExtRunes = 10
+)
+
+// safeFilename turns a client-supplied filename into one that is safe to join onto the
+// uploads directory: directory components (both / and \) are dropped, anything but letters,
+// digits, '.', '-' and '_' becomes '_', and the name is capped at maxFilenameRunes with its
+// extension kept. Letters include non-ASCII ones so Vietnamese names survive.
+func safeFilename(name string) string {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '.', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	// A leading dot would make a hidden file, and "." or ".." must never reach filepath.Join
+	cleaned := strings.TrimLeft(b.String(), ".")
+
+	ext := filepath.Ext(cleaned)
+	if len([]rune(ext)) > maxExtRunes {
+		ext = ""
+	}
+	stem := strings.TrimSuffix(cleaned, ext)
+	if strings.Trim(stem, "_") == "" {
+		stem = "audio"
+	}
+	if runes := []rune(stem); len(runes)+len([]rune(ext)) > maxFilenameRunes {
+		stem = string(runes[:maxFilenameRunes-len([]rune(ext))])
+	}
+	return stem + ext
+}
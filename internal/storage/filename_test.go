@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSafeFilename(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain name", "note.m4a", "note.m4a"},
+		{"parent traversal", "../../etc/passwd", "passwd"},
+		{"traversal with extension", "../secret.wav", "secret.wav"},
+		{"absolute unix path", "/var/lib/app/audio.mp3", "audio.mp3"},
+		{"windows path", `C:\Users\lan\ghi âm.m4a`, "ghi_âm.m4a"},
+		{"windows traversal", `..\..\x.wav`, "x.wav"},
+		{"mixed separators", `a/b\c.mp3`, "c.mp3"},
+		{"only dots", "..", "audio"},
+		{"trailing slash", "../", "audio"},
+		{"hidden file", ".hidden.wav", "hidden.wav"},
+		{"vietnamese name", "Ghi âm cuộc họp.m4a", "Ghi_âm_cuộc_họp.m4a"},
+		{"cjk name", "会议.wav", "会议.wav"},
+		{"emoji only stem", "🎵.mp3", "audio.mp3"},
+		{"shell characters", "a;rm -rf $HOME.wav", "a_rm_-rf__HOME.wav"},
+		{"null byte", "a\x00b.wav", "a_b.wav"},
+		{"empty name", "", "audio"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := safeFilename(tt.in); got != tt.want {
+				t.Errorf("safeFilename(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSafeFilenameLength(t *testing.T) {
+	long := strings.Repeat("ạ", 150) + ".m4a"
+	got := safeFilename(long)
+	if n := utf8.RuneCountInString(got); n != maxFilenameRunes {
+		t.Errorf("safeFilename of a 154-rune name has %d runes, want %d", n, maxFilenameRunes)
+	}
+	if !strings.HasSuffix(got, ".m4a") {
+		t.Errorf("safeFilename(%q) = %q, want the .m4a extension kept", long, got)
+	}
+
+	// An implausibly long extension is not preserved at the expense of the name
+	longExt := "a." + strings.Repeat("x", 20)
+	if got := safeFilename(longExt); got != longExt {
+		t.Errorf("safeFilename(%q) = %q, want it unchanged", longExt, got)
+	}
+}
+
+func TestSafeFilenameStaysInUploadDir(t *testing.T) {
+	dir := "uploads"
+	for _, in := range []string{"..", "../..", "../../etc/passwd", `..\..\win.ini`, "/abs/path.wav", "a/../../b.mp3", "./.", "...."} {
+		got := safeFilename(in)
+		if strings.ContainsAny(got, `/\`) || got == "." || got == ".." {
+			t.Errorf("safeFilename(%q) = %q, want a single path element", in, got)
+		}
+		if joined := filepath.Join(dir, got); filepath.Dir(joined) != dir {
+			t.Errorf("safeFilename(%q) = %q escapes %s (joined %s)", in, got, dir, joined)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime/multipart"
+	"sync"
+)
+
+// Keys and hashes are scoped per user (see uploadKey), so one user's retry never returns
+// another user's recording
+var (
+	idempotencyKeys = make(map[string]string) // user + Idempotency-Key -> recording ID, "" while in flight
+	contentHashes   = make(map[string]string) // user + SHA-256 of audio -> recording ID
+	muIdempotency   sync.Mutex
+)
+
+// uploadKey scopes an idempotency key or content hash to its user
+func uploadKey(userID, key string) string {
+	return userID + "\x00" + key
+}
+
+// HashAudioFile returns the hex-encoded SHA-256 of an uploaded file
+func HashAudioFile(file *multipart.FileHeader) (string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, src); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// HashAudioBytes computes the SHA-256 hex digest of an in-memory audio payload
+func HashAudioBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ReserveIdempotencyKey atomically claims an Idempotency-Key of userID as in flight before
+// the upload is saved. When the key is already taken it returns false with the recording
+// created for it, or "" while the first request is still running. A claimed key is completed
+// by SaveUploadKeys or given up with ReleaseIdempotencyKey.
+func ReserveIdempotencyKey(userID, key string) (string, bool) {
+	muIdempotency.Lock()
+	defer muIdempotency.Unlock()
+	if id, exists := idempotencyKeys[uploadKey(userID, key)]; exists {
+		return id, false
+	}
+	idempotencyKeys[uploadKey(userID, key)] = ""
+	return "", true
+}
+
+// ReleaseIdempotencyKey drops a key claimed by ReserveIdempotencyKey whose upload failed, so a
+// retry can run. Keys of completed uploads are kept.
+func ReleaseIdempotencyKey(userID, key string) {
+	muIdempotency.Lock()
+	defer muIdempotency.Unlock()
+	if id, exists := idempotencyKeys[uploadKey(userID, key)]; exists && id == "" {
+		delete(idempotencyKeys, uploadKey(userID, key))
+	}
+}
+
+// GetRecordingIDByContentHash returns the first recording of userID uploaded with the given content hash
+func GetRecordingIDByContentHash(userID, hash string) (string, bool) {
+	muIdempotency.Lock()
+	defer muIdempotency.Unlock()
+	id, ok := contentHashes[uploadKey(userID, hash)]
+	return id, ok
+}
+
+// removeUploadKeys drops every idempotency key and content hash pointing at a recording
+func removeUploadKeys(recordingID string) {
+	muIdempotency.Lock()
+	defer muIdempotency.Unlock()
+	for key, id := range idempotencyKeys {
+		if id == recordingID {
+			delete(idempotencyKeys, key)
+		}
+	}
+	for hash, id := range contentHashes {
+		if id == recordingID {
+			delete(contentHashes, hash)
+		}
+	}
+}
+
+// SaveUploadKeys registers the idempotency key and content hash of userID for a recording,
+// completing a key claimed by ReserveIdempotencyKey. The first recording registered for a key
+// or hash wins.
+func SaveUploadKeys(recordingID, userID, idempotencyKey, contentHash string) {
+	muIdempotency.Lock()
+	if idempotencyKey != "" {
+		if id := idempotencyKeys[uploadKey(userID, idempotencyKey)]; id == "" {
+			idempotencyKeys[uploadKey(userID, idempotencyKey)] = recordingID
+		}
+	}
+	if contentHash != "" {
+		if _, exists := contentHashes[uploadKey(userID, contentHash)]; !exists {
+			contentHashes[uploadKey(userID, contentHash)] = recordingID
+		}
+	}
+	muIdempotency.Unlock()
+
+	UpdateUploadKeys(recordingID, contentHash, idempotencyKey)
+}
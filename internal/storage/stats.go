@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"log"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// defaultMaxEntries bounds each in-memory map until SetLimits is called
+const defaultMaxEntries = 5000
+
+// Stats describes the size of the in-memory maps
+type Stats struct {
+	Recordings       int    `json:"recordings"`
+	Analyses         int    `json:"analyses"`
+	IdempotencyKeys  int    `json:"idempotency_keys"`
+	ContentHashes    int    `json:"content_hashes"`
+	ApproxBytes      int64  `json:"approx_bytes"` // rough estimate of string payloads, not exact heap usage
+	MaxEntries       int    `json:"max_entries"`
+	EvictedTotal     int64  `json:"evicted_total"`
+	AnalysesEvicted  int64  `json:"analyses_evicted_total"`
+	RecordingsOldest string `json:"recordings_oldest,omitempty"`
+}
+
+var (
+	evictedRecordings atomic.Int64
+	evictedAnalyses   atomic.Int64
+
+	// evictHook lets other packages drop state keyed by an evicted recording (e.g. DB mappings)
+	evictHook func(recordingID string)
+)
+
+// OnEvict registers a callback invoked (outside storage locks) for every evicted recording
+func OnEvict(fn func(recordingID string)) {
+	evictHook = fn
+}
+
+// Limits applied at startup with SetLimits
+var (
+	maxEntries atomic.Int64
+	uploadTTL  atomic.Int64 // time.Duration
+)
+
+func init() {
+	maxEntries.Store(defaultMaxEntries)
+	uploadTTL.Store(int64(defaultUploadTTL))
+}
+
+// SetLimits sets the in-memory map bound (STORAGE_MAX_ENTRIES, 0 disables eviction)
+// and the resumable upload expiry (RESUMABLE_UPLOAD_TTL)
+func SetLimits(entries int, ttl time.Duration) {
+	maxEntries.Store(int64(entries))
+	uploadTTL.Store(int64(ttl))
+}
+
+// MaxEntries returns the bound on each in-memory map (default 5000, 0 disables eviction)
+func MaxEntries() int {
+	return int(maxEntries.Load())
+}
+
+// GetStats returns counts and an approximate memory footprint of the in-memory maps
+func GetStats() Stats {
+	stats := Stats{MaxEntries: MaxEntries()}
+
+	mu.Lock()
+	stats.Recordings = len(recordings)
+	for _, rec := range recordings {
+		stats.ApproxBytes += int64(len(rec.ID) + len(rec.Path) + len(rec.Status) + len(rec.CreatedAt) +
+			len(rec.Transcript) + len(rec.Error) + len(rec.ContentHash) + len(rec.IdempotencyKey) + 64)
+		if stats.RecordingsOldest == "" || rec.CreatedAt < stats.RecordingsOldest {
+			stats.RecordingsOldest = rec.CreatedAt
+		}
+	}
+	mu.Unlock()
+
+	muAnalysis.Lock()
+	stats.Analyses = len(analyses)
+	for id, a := range analyses {
+		size := len(id) + len(a.Context) + len(a.Title) + len(a.ZaloBrief) + len(a.Language) + 64
+		for _, list := range [][]string{a.Summary, a.ActionItems, a.KeyPoints, a.Questions} {
+			for _, item := range list {
+				size += len(item) + 16
+			}
+		}
+		stats.ApproxBytes += int64(size)
+	}
+	muAnalysis.Unlock()
+	stats.EvictedTotal = evictedRecordings.Load()
+	stats.AnalysesEvicted = evictedAnalyses.Load()
+
+	muIdempotency.Lock()
+	stats.IdempotencyKeys = len(idempotencyKeys)
+	stats.ContentHashes = len(contentHashes)
+	for key := range idempotencyKeys {
+		stats.ApproxBytes += int64(len(key) + 48)
+	}
+	stats.ApproxBytes += int64(len(contentHashes) * (36 + 1 + 64 + 48))
+	muIdempotency.Unlock()
+
+	return stats
+}
+
+// evictOldestRecordings removes the oldest recordings (by CreatedAt) beyond MaxEntries,
+// together with their analyses and upload keys. keepID (the recording just added) is never evicted.
+func evictOldestRecordings(keepID string) {
+	limit := MaxEntries()
+	if limit == 0 {
+		return
+	}
+
+	mu.Lock()
+	excess := len(recordings) - limit
+	if excess <= 0 {
+		mu.Unlock()
+		return
+	}
+	byAge := make([]*Recording, 0, len(recordings))
+	for _, rec := range recordings {
+		if rec.ID != keepID {
+			byAge = append(byAge, rec)
+		}
+	}
+	sort.Slice(byAge, func(i, j int) bool { return byAge[i].CreatedAt < byAge[j].CreatedAt })
+	evicted := make([]string, 0, excess)
+	for _, rec := range byAge[:excess] {
+		delete(recordings, rec.ID)
+		evicted = append(evicted, rec.ID)
+	}
+	mu.Unlock()
+
+	for _, id := range evicted {
+		removeUploadKeys(id)
+		DeleteAnalysis(id)
+		if evictHook != nil {
+			evictHook(id)
+		}
+	}
+
+	evictedRecordings.Add(int64(len(evicted)))
+
+	log.Printf("[Storage] Evicted %d oldest recordings (STORAGE_MAX_ENTRIES=%d)", len(evicted), limit)
+}
+
+// evictOldestAnalysesLocked bounds analyses that have no in-memory recording (e.g. loaded back from the DB).
+// Must be called with muAnalysis held.
+func evictOldestAnalysesLocked() {
+	limit := MaxEntries()
+	excess := len(analyses) - limit
+	if limit == 0 || excess <= 0 {
+		return
+	}
+
+	ids := make([]string, 0, len(analyses))
+	for id := range analyses {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return analysisSavedAt[ids[i]].Before(analysisSavedAt[ids[j]]) })
+	for _, id := range ids[:excess] {
+		delete(analyses, id)
+		delete(analysisSavedAt, id)
+		delete(analysesV1, id)
+	}
+	evictedAnalyses.Add(int64(excess))
+	log.Printf("[Storage] Evicted %d oldest analyses (STORAGE_MAX_ENTRIES=%d)", excess, limit)
+}
+
+// touchAnalysisLocked records when an analysis was saved (used for eviction order).
+// Must be called with muAnalysis held.
+func touchAnalysisLocked(recordingID string) {
+	analysisSavedAt[recordingID] = time.Now()
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"crypto/subtle"
+	"sync"
+	"time"
+)
+
+// STTJob is an asynchronous transcription waiting for its provider callback.
+// A recording has at most one pending job; it is removed when the callback arrives.
+type STTJob struct {
+	RecordingID string
+	Provider    string // provider name, used to parse the callback
+	JobID       string // the provider's job ID
+	Token       string // random per job, part of the signed callback URL
+	SubmittedAt time.Time
+
+	// /process options applied once the transcript arrives
+	OutputLanguage   string
+	FilterProfanity  bool
+	UseFastClean     bool
+	ProviderOverride string
+	UserID           string
+}
+
+var (
+	sttJobs   = make(map[string]*STTJob)
+	muSTTJobs sync.Mutex
+)
+
+// SaveSTTJob records a submitted job, replacing any earlier job of the recording
+func SaveSTTJob(job *STTJob) {
+	muSTTJobs.Lock()
+	defer muSTTJobs.Unlock()
+	sttJobs[job.RecordingID] = job
+}
+
+// GetSTTJob returns the pending job of a recording
+func GetSTTJob(recordingID string) (*STTJob, bool) {
+	muSTTJobs.Lock()
+	defer muSTTJobs.Unlock()
+	job, ok := sttJobs[recordingID]
+	if !ok {
+		return nil, false
+	}
+	jobCopy := *job
+	return &jobCopy, true
+}
+
+// TakeSTTJob removes and returns the pending job of a recording if token matches, so each
+// callback URL is accepted at most once even when the provider retries concurrently
+func TakeSTTJob(recordingID, token string) (*STTJob, bool) {
+	muSTTJobs.Lock()
+	defer muSTTJobs.Unlock()
+	job, ok := sttJobs[recordingID]
+	if !ok || subtle.ConstantTimeCompare([]byte(job.Token), []byte(token)) != 1 {
+		return nil, false
+	}
+	delete(sttJobs, recordingID)
+	return job, true
+}
+
+// DeleteSTTJob drops the pending job of a recording (e.g. when it timed out)
+func DeleteSTTJob(recordingID string) {
+	muSTTJobs.Lock()
+	defer muSTTJobs.Unlock()
+	delete(sttJobs, recordingID)
+}
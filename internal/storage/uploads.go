@@ -0,0 +1,280 @@
+package storage
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// defaultUploadTTL is how long an incomplete resumable upload is kept until SetLimits is called
+const defaultUploadTTL = 24 * time.Hour
+
+var (
+	ErrUploadNotFound       = errors.New("upload not found or expired")
+	ErrUploadOffsetMismatch = errors.New("chunk offset does not match received bytes")
+	ErrUploadTooLarge       = errors.New("upload exceeds maximum size")
+	ErrUploadIncomplete     = errors.New("upload is incomplete")
+)
+
+// PartialUpload tracks a resumable upload whose chunks are appended to a temp file
+type PartialUpload struct {
+	ID        string
+	UserID    string // owner: only this user may append to, read or finalize the upload
+	Filename  string
+	Path      string
+	TotalSize int64 // declared total size, 0 if unknown until the last chunk
+	MaxSize   int64
+	Received  int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	hasher hash.Hash // SHA-256 of the bytes received so far
+}
+
+// partialUpload is the stored state of an upload. mu serializes its chunks so a slow client
+// never blocks other uploads; muUploads only guards the map.
+type partialUpload struct {
+	mu      sync.Mutex
+	removed bool // finalized, deleted or expired while a caller waited for mu
+	PartialUpload
+}
+
+var (
+	partialUploads = make(map[string]*partialUpload)
+	muUploads      sync.Mutex
+)
+
+// UploadTTL returns how long an incomplete resumable upload is kept (RESUMABLE_UPLOAD_TTL, default 24h)
+func UploadTTL() time.Duration {
+	return time.Duration(uploadTTL.Load())
+}
+
+// CreateUpload starts a resumable upload owned by userID and returns its ID
+func CreateUpload(filename string, totalSize, maxSize int64, userID string) (*PartialUpload, error) {
+	ExpireUploads()
+
+	id, err := newUploadID()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(uploadDir, "partial", id)
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create upload file: %w", err)
+	}
+	f.Close()
+
+	now := time.Now()
+	upload := &partialUpload{PartialUpload: PartialUpload{
+		ID:        id,
+		UserID:    userID,
+		Filename:  safeFilename(filename),
+		Path:      path,
+		TotalSize: totalSize,
+		MaxSize:   maxSize,
+		CreatedAt: now,
+		UpdatedAt: now,
+		hasher:    sha256.New(),
+	}}
+
+	muUploads.Lock()
+	partialUploads[id] = upload
+	muUploads.Unlock()
+
+	uploadCopy := upload.PartialUpload
+	return &uploadCopy, nil
+}
+
+// newUploadID returns an unguessable upload ID, since the ID is all a client needs to resume
+func newUploadID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate upload ID: %w", err)
+	}
+	return "upl_" + hex.EncodeToString(b), nil
+}
+
+// lockUpload returns the live upload id of userID with its mu held, or nil. Uploads of other
+// users are reported as missing.
+func lockUpload(id string, userID string) *partialUpload {
+	muUploads.Lock()
+	upload, ok := partialUploads[id]
+	muUploads.Unlock()
+	if !ok || upload.UserID != userID {
+		return nil
+	}
+
+	upload.mu.Lock()
+	if upload.removed || time.Since(upload.UpdatedAt) > UploadTTL() {
+		upload.mu.Unlock()
+		return nil
+	}
+	return upload
+}
+
+// removeUpload drops an upload from the map; the caller holds upload.mu
+func removeUpload(upload *partialUpload) {
+	upload.removed = true
+	muUploads.Lock()
+	delete(partialUploads, upload.ID)
+	muUploads.Unlock()
+}
+
+// GetUpload returns a snapshot of a resumable upload of userID
+func GetUpload(id string, userID string) (*PartialUpload, bool) {
+	upload := lockUpload(id, userID)
+	if upload == nil {
+		return nil, false
+	}
+	defer upload.mu.Unlock()
+	uploadCopy := upload.PartialUpload
+	return &uploadCopy, true
+}
+
+// AppendUploadChunk writes a chunk of userID's upload starting at offset. Chunks must arrive in
+// order: offset has to equal the bytes received so far. totalSize (if > 0) fixes the final size.
+func AppendUploadChunk(id string, userID string, offset, totalSize int64, chunk io.Reader) (*PartialUpload, error) {
+	upload := lockUpload(id, userID)
+	if upload == nil {
+		return nil, ErrUploadNotFound
+	}
+	defer upload.mu.Unlock()
+
+	if offset != upload.Received {
+		return nil, ErrUploadOffsetMismatch
+	}
+	if totalSize > 0 {
+		if upload.TotalSize > 0 && upload.TotalSize != totalSize {
+			return nil, fmt.Errorf("total size changed from %d to %d", upload.TotalSize, totalSize)
+		}
+		if totalSize > upload.MaxSize {
+			return nil, ErrUploadTooLarge
+		}
+		upload.TotalSize = totalSize
+	}
+
+	limit := upload.MaxSize - upload.Received
+	if upload.TotalSize > 0 {
+		limit = upload.TotalSize - upload.Received
+	}
+
+	f, err := os.OpenFile(upload.Path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open upload file: %w", err)
+	}
+	defer f.Close()
+
+	// Read one byte past the limit to detect oversized chunks
+	written, err := io.Copy(io.MultiWriter(f, upload.hasher), io.LimitReader(chunk, limit+1))
+	if err != nil || written > limit {
+		// Roll back the partial write so the client can retry from the last good offset
+		f.Truncate(upload.Received)
+		upload.hasher = rehashFile(upload.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write chunk: %w", err)
+		}
+		return nil, ErrUploadTooLarge
+	}
+
+	upload.Received += written
+	upload.UpdatedAt = time.Now()
+
+	uploadCopy := upload.PartialUpload
+	return &uploadCopy, nil
+}
+
+// UploadContentHash returns the SHA-256 of the bytes received for userID's upload
+func UploadContentHash(id string, userID string) (string, bool) {
+	upload := lockUpload(id, userID)
+	if upload == nil {
+		return "", false
+	}
+	defer upload.mu.Unlock()
+	return hex.EncodeToString(upload.hasher.Sum(nil)), true
+}
+
+// FinalizeUpload assembles a complete upload of userID into a recording owned by them and
+// returns the recording ID
+func FinalizeUpload(id string, userID string) (string, error) {
+	upload := lockUpload(id, userID)
+	if upload == nil {
+		return "", ErrUploadNotFound
+	}
+	if upload.Received == 0 || (upload.TotalSize > 0 && upload.Received != upload.TotalSize) {
+		upload.mu.Unlock()
+		return "", ErrUploadIncomplete
+	}
+	removeUpload(upload)
+	upload.mu.Unlock()
+
+	recordingID := fmt.Sprintf("rec_%d", time.Now().UnixNano())
+	dst := filepath.Join(uploadDir, recordingID+"_"+upload.Filename)
+	if err := os.Rename(upload.Path, dst); err != nil {
+		os.Remove(upload.Path)
+		return "", fmt.Errorf("failed to assemble upload: %w", err)
+	}
+
+	registerRecording(recordingID, dst, upload.UserID)
+	return recordingID, nil
+}
+
+// DeleteUpload discards a resumable upload of userID and its temp file
+func DeleteUpload(id string, userID string) bool {
+	upload := lockUpload(id, userID)
+	if upload == nil {
+		return false
+	}
+	removeUpload(upload)
+	upload.mu.Unlock()
+
+	os.Remove(upload.Path)
+	return true
+}
+
+// ExpireUploads removes incomplete uploads idle for longer than UploadTTL. Uploads busy with a
+// chunk are in use and skipped.
+func ExpireUploads() int {
+	ttl := UploadTTL()
+
+	muUploads.Lock()
+	var expired []*partialUpload
+	for id, upload := range partialUploads {
+		if !upload.mu.TryLock() {
+			continue
+		}
+		if time.Since(upload.UpdatedAt) > ttl {
+			upload.removed = true
+			expired = append(expired, upload)
+			delete(partialUploads, id)
+		}
+		upload.mu.Unlock()
+	}
+	muUploads.Unlock()
+
+	for _, upload := range expired {
+		os.Remove(upload.Path)
+	}
+	if len(expired) > 0 {
+		log.Printf("[Storage] Expired %d incomplete uploads (RESUMABLE_UPLOAD_TTL=%s)", len(expired), ttl)
+	}
+	return len(expired)
+}
+
+// rehashFile recomputes the SHA-256 state from a file on disk
+func rehashFile(path string) hash.Hash {
+	h := sha256.New()
+	if f, err := os.Open(path); err == nil {
+		io.Copy(h, f)
+		f.Close()
+	}
+	return h
+}
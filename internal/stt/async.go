@@ -0,0 +1,45 @@
+package stt
+
+import "context"
+
+// AsyncProvider is a provider that can also transcribe long audio as a background job.
+// Submit uploads the audio and returns the provider's job ID; when the job is done the
+// provider POSTs its result to callbackURL, and ParseCallback turns that body into a Result.
+type AsyncProvider interface {
+	Provider
+
+	// Submit starts a transcription job whose result is delivered to callbackURL
+	Submit(ctx context.Context, audioPath, callbackURL string) (jobID string, err error)
+
+	// ParseCallback parses the body the provider POSTed to the callback URL
+	ParseCallback(body []byte) (*Result, error)
+}
+
+// AsyncMode returns the async mode of a provider created by CreateProvider or CreateNamedProvider
+// when its name is listed in STT_ASYNC_PROVIDERS. Best-of providers always run synchronously.
+func AsyncMode(p Provider) (AsyncProvider, bool) {
+	enabled := false
+	for _, name := range conf.STTAsyncProviders {
+		if name == p.Name() {
+			enabled = true
+			break
+		}
+	}
+	if !enabled {
+		return nil, false
+	}
+
+	// Look through the concurrency and instrumentation wrappers added by createNamedProvider
+	for {
+		switch v := p.(type) {
+		case limitedProvider:
+			p = v.Provider
+		case instrumentedProvider:
+			p = v.Provider
+		case AsyncProvider:
+			return v, true
+		default:
+			return nil, false
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package stt
+
+import "math"
+
+// ConfidenceUnavailable is the Result.Confidence sentinel for transcripts the provider did not score.
+// It is outside the 0..1 scale on purpose, so it is never mistaken for a (low) real score.
+const ConfidenceUnavailable = -1.0
+
+// NormalizeConfidence maps a provider's raw confidence onto the common 0..1 scale used by
+// Result.Confidence. ok is false when the provider gave no usable score; the returned value
+// is then ConfidenceUnavailable.
+//
+// Source scales:
+//   - fpt: hypotheses[0].confidence, documented as 0..1. Some FPT.AI deployments report a
+//     0..100 percentage instead, so values above 1 are divided by 100. 0 means "not scored".
+//   - google: results[].alternatives[0].confidence, 0..1. Google documents 0 as the sentinel
+//     for "confidence not set" (e.g. non-final results), so 0 is treated as unavailable.
+//   - other providers: assumed 0..1 and clamped.
+func NormalizeConfidence(provider string, raw float64) (float64, bool) {
+	if math.IsNaN(raw) || math.IsInf(raw, 0) || raw < 0 {
+		return ConfidenceUnavailable, false
+	}
+
+	switch provider {
+	case "fpt":
+		if raw == 0 {
+			return ConfidenceUnavailable, false
+		}
+		if raw > 1 {
+			raw /= 100
+		}
+	case "google":
+		if raw == 0 {
+			return ConfidenceUnavailable, false
+		}
+	}
+	return math.Min(raw, 1), true
+}
+
+// HasConfidence reports whether confidence is a real 0..1 score rather than ConfidenceUnavailable
+func HasConfidence(confidence float64) bool {
+	return confidence >= 0
+}
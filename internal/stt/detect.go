@@ -0,0 +1,66 @@
+package stt
+
+import (
+	"context"
+	"log"
+	"noteme/internal/audio"
+	"noteme/internal/config"
+	"os"
+)
+
+// languageSampleSeconds is the length of the clip transcribed to detect the spoken language
+const languageSampleSeconds = 15
+
+// languageRoutedProvider serves STT_LANGUAGE=auto for a Vietnamese-only provider (FPT): it
+// transcribes a short sample with Google to detect the language, then sends the recording to
+// Google when it is English and to the Vietnamese provider otherwise. Detection failures fall
+// back to the Vietnamese provider, like before auto mode existed.
+type languageRoutedProvider struct {
+	Provider          // the Vietnamese-only provider, also the reported Name
+	detector Provider // Google with language auto, transcribes the sample and English recordings
+}
+
+func (p languageRoutedProvider) Transcribe(ctx context.Context, audioPath string) (*Result, error) {
+	if detectLanguage(ctx, p.detector, audioPath) == LanguageEnglish {
+		log.Printf("[STT] Sample of %s is English, transcribing with %s instead of %s", audioPath, p.detector.Name(), p.Name())
+		return p.detector.Transcribe(ctx, audioPath)
+	}
+	return p.Provider.Transcribe(ctx, audioPath)
+}
+
+// detectLanguage transcribes the first seconds of a recording and returns the language the
+// detector reported, or "" when it could not tell
+func detectLanguage(ctx context.Context, detector Provider, audioPath string) string {
+	samplePath, err := audio.ExtractSample(ctx, audioPath, languageSampleSeconds)
+	if err != nil {
+		log.Printf("[STT] Warning: language detection skipped, could not extract a sample: %v", err)
+		return ""
+	}
+	defer os.Remove(samplePath)
+
+	result, err := detector.Transcribe(ctx, samplePath)
+	if err != nil {
+		log.Printf("[STT] Warning: language detection on sample failed: %v", err)
+		return ""
+	}
+	if lang := normalizeLanguageCode(result.Language); lang != "" {
+		return lang
+	}
+	lang, _ := DetectTextLanguage(result.Transcript)
+	return lang
+}
+
+// withLanguageRouting wraps FPT in a languageRoutedProvider when STT_LANGUAGE=auto and Google
+// can be created; otherwise it returns provider unchanged
+func withLanguageRouting(cfg *config.Config, providerName string, provider Provider) Provider {
+	if providerName != "fpt" || configuredLanguage(cfg) != LanguageAuto {
+		return provider
+	}
+	detector, err := createNamedProvider(cfg, "google")
+	if err != nil {
+		log.Printf("[STT Factory] Warning: STT_LANGUAGE=auto needs Google to detect English for FPT, transcribing everything as Vietnamese: %v", err)
+		return provider
+	}
+	log.Printf("[STT Factory] STT_LANGUAGE=auto: detecting the language of a %ds sample with Google before using FPT", languageSampleSeconds)
+	return languageRoutedProvider{Provider: provider, detector: detector}
+}
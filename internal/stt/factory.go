@@ -3,34 +3,103 @@ package stt
 import (
 	"fmt"
 	"log"
-	"os"
+	"noteme/internal/config"
 	"strings"
+	"time"
 )
 
-// CreateProvider creates an STT provider based on environment configuration
-func CreateProvider() (Provider, error) {
-	providerName := strings.ToLower(os.Getenv("STT_PROVIDER"))
+// DefaultHTTPTimeout is used by the provider constructors when given a zero timeout
+const DefaultHTTPTimeout = 90 * time.Second
 
-	// Default to FPT if not specified
-	if providerName == "" {
-		providerName = "fpt"
-		log.Printf("[STT Factory] STT_PROVIDER not set, defaulting to 'fpt'")
+// conf holds the settings shared by all providers (language, concurrency, retries).
+// It starts at the defaults and is replaced by Configure at startup.
+var conf = config.Default()
+
+// Configure applies the loaded configuration to the package-wide STT settings.
+// Providers themselves are built from the config passed to CreateProvider.
+func Configure(cfg *config.Config) {
+	conf = cfg
+}
+
+// CreateProvider creates the STT provider selected by cfg.STTProvider (see config.Load)
+func CreateProvider(cfg *config.Config) (Provider, error) {
+	providerName := cfg.STTProvider
+
+	// "best:fpt,google" runs the listed providers in parallel and keeps the most confident result
+	if names, ok := strings.CutPrefix(providerName, "best:"); ok {
+		return createParallelProvider(cfg, names)
+	}
+
+	provider, err := createNamedProvider(cfg, providerName)
+	if err != nil {
+		return nil, err
+	}
+	return withLanguageRouting(cfg, providerName, provider), nil
+}
+
+// CreateNamedProvider creates a single provider by name (see SupportedProviders), ignoring STT_PROVIDER.
+// Used to re-run a recording with a different provider than the configured one.
+func CreateNamedProvider(cfg *config.Config, name string) (Provider, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	provider, err := createNamedProvider(cfg, name)
+	if err != nil {
+		return nil, err
 	}
+	return withLanguageRouting(cfg, name, provider), nil
+}
 
+// createNamedProvider creates a single instrumented, concurrency-limited provider by name
+func createNamedProvider(cfg *config.Config, providerName string) (Provider, error) {
+	var provider Provider
+	var err error
 	switch providerName {
 	case "fpt":
-		return createFPTProvider()
+		provider, err = createFPTProvider(cfg)
 	case "google":
-		return createGoogleProvider()
+		provider, err = createGoogleProvider(cfg)
+	case "mock":
+		provider, err = createMockProvider(cfg)
 	default:
-		return nil, fmt.Errorf("unsupported STT provider: %s. Supported: fpt, google", providerName)
+		return nil, fmt.Errorf("unsupported STT provider: %s. Supported: fpt, google, mock, best:<p1>,<p2>", providerName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return limitedProvider{Provider: instrumentedProvider{Provider: provider}}, nil
+}
+
+// createParallelProvider creates a ParallelProvider from a comma-separated list of provider names
+func createParallelProvider(cfg *config.Config, names string) (Provider, error) {
+	var providers []Provider
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		provider, err := createNamedProvider(cfg, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s for best-of mode: %w", name, err)
+		}
+		providers = append(providers, provider)
+	}
+
+	if len(providers) < 2 {
+		return nil, fmt.Errorf("STT_PROVIDER=best:<p1>,<p2> needs at least two distinct providers, got %q", names)
 	}
+
+	parallel := NewParallelProvider(providers...)
+	log.Printf("[STT Factory] Creating parallel STT provider: %s", parallel.Name())
+	return parallel, nil
 }
 
 // createFPTProvider creates an FPT STT provider
-func createFPTProvider() (Provider, error) {
-	apiKey := os.Getenv("FPT_AI_API_KEY")
-	url := os.Getenv("FPT_AI_STT_URL")
+func createFPTProvider(cfg *config.Config) (Provider, error) {
+	apiKey := cfg.FPTApiKey
+	url := cfg.FPTSTTURL
 
 	if apiKey == "" {
 		return nil, fmt.Errorf("FPT_AI_API_KEY environment variable is not set")
@@ -41,35 +110,53 @@ func createFPTProvider() (Provider, error) {
 		log.Printf("[STT Factory] FPT_AI_STT_URL not set, using default: %s", url)
 	}
 
-	log.Printf("[STT Factory] Creating FPT STT provider")
-	return NewFPTProvider(apiKey, url), nil
+	timeout := cfg.STTHTTPTimeoutFor("fpt")
+
+	// FPT.AI only transcribes Vietnamese; with STT_LANGUAGE=auto, English recordings go to Google (see withLanguageRouting)
+	if lang := configuredLanguage(cfg); lang == LanguageEnglish {
+		log.Printf("[STT Factory] Warning: FPT STT only supports Vietnamese, ignoring STT_LANGUAGE=%s", lang)
+	}
+
+	log.Printf("[STT Factory] Creating FPT STT provider (timeout %v)", timeout)
+	provider := NewFPTProvider(apiKey, url, timeout)
+	provider.asyncURL = cfg.FPTSTTAsyncURL
+	return provider, nil
 }
 
 // createGoogleProvider creates a Google STT provider
 // GOOGLE_STT_KEY_FILE can be either:
-//   - An API key (39 characters, typically starts with "AIzaSy")
+//   - An API key
 //   - A file path to a JSON key file (e.g., "./keys/google-service-account.json")
 //   - A JSON string containing the service account credentials
-func createGoogleProvider() (Provider, error) {
-	projectID := os.Getenv("GOOGLE_STT_PROJECT_ID")
-	keyData := os.Getenv("GOOGLE_STT_KEY_FILE")
-	
-	// Project ID is optional when using API key
-	keyDataTrimmed := strings.TrimSpace(keyData)
-	isAPIKey := len(keyDataTrimmed) == 39 && strings.HasPrefix(keyDataTrimmed, "AIzaSy")
-	
-	if !isAPIKey && projectID == "" {
-		return nil, fmt.Errorf("GOOGLE_STT_PROJECT_ID environment variable is required when using service account")
-	}
-	
-	if keyData == "" {
-		return nil, fmt.Errorf("GOOGLE_STT_KEY_FILE environment variable is not set. It can be:\n  - An API key (39 characters)\n  - A file path to a JSON key file\n  - A JSON string containing service account credentials")
+//   - Empty, to use Application Default Credentials
+//
+// GOOGLE_STT_AUTH_MODE (apikey | service_account | adc) overrides auto-detection.
+// GOOGLE_STT_MODEL and GOOGLE_STT_USE_ENHANCED select the recognition model (see recognitionModel).
+func createGoogleProvider(cfg *config.Config) (Provider, error) {
+	projectID := cfg.GoogleSTTProjectID
+	keyData := cfg.GoogleSTTKeyFile
+
+	detectedMode, err := DetectGoogleAuthMode(keyData, cfg.GoogleSTTAuthMode)
+	if err != nil {
+		return nil, err
 	}
 
-	if isAPIKey {
+	timeout := cfg.STTHTTPTimeoutFor("google")
+
+	if detectedMode == GoogleAuthAPIKey {
 		log.Printf("[STT Factory] Creating Google STT provider with API key")
 	} else {
-		log.Printf("[STT Factory] Creating Google STT provider with project: %s", projectID)
+		log.Printf("[STT Factory] Creating Google STT provider with %s credentials (project: %s)", detectedMode, projectID)
 	}
-	return NewGoogleProvider(projectID, keyData)
+	provider, err := NewGoogleProviderWithMode(projectID, keyData, detectedMode, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	provider.language = configuredLanguage(cfg)
+	log.Printf("[STT Factory] Google STT language: %s", provider.language)
+
+	provider.model, provider.useEnhanced = cfg.GoogleSTTModel, cfg.GoogleSTTUseEnhanced
+	log.Printf("[STT Factory] Google STT model: %s, enhanced: %v", provider.model, provider.useEnhanced)
+	return provider, nil
 }
@@ -2,11 +2,13 @@ package stt
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"noteme/internal/audio"
 	"os"
 	"path/filepath"
 	"strings"
@@ -15,15 +17,22 @@ import (
 
 // FPTProvider implements STT using FPT.AI Speech-to-Text API
 type FPTProvider struct {
-	apiKey string
-	url    string
+	apiKey     string
+	url        string
+	asyncURL   string // FPT_AI_STT_ASYNC_URL, empty when async jobs are not configured
+	httpClient *http.Client
 }
 
 // NewFPTProvider creates a new FPT STT provider
-func NewFPTProvider(apiKey, url string) *FPTProvider {
+// timeout bounds each HTTP call to FPT.AI (DefaultHTTPTimeout if zero)
+func NewFPTProvider(apiKey, url string, timeout time.Duration) *FPTProvider {
+	if timeout <= 0 {
+		timeout = DefaultHTTPTimeout
+	}
 	return &FPTProvider{
-		apiKey: apiKey,
-		url:    url,
+		apiKey:     apiKey,
+		url:        url,
+		httpClient: &http.Client{Timeout: timeout},
 	}
 }
 
@@ -42,10 +51,52 @@ type FPTSTTResponse struct {
 	Message   string `json:"message,omitempty"`
 }
 
+// fptNativeExts are uploaded to FPT.AI as-is (see getContentType); everything else is converted to WAV first
+var fptNativeExts = map[string]bool{
+	".wav": true, ".mp3": true, ".m4a": true, ".mp4": true, ".aac": true, ".ogg": true, ".flac": true,
+	".webm": true, ".amr": true, ".caf": true, ".aiff": true, ".aif": true,
+}
+
+// getContentType returns the audio MIME type for a file extension.
+// text/plain is kept only as a last-resort default for unknown formats.
+func getContentType(fileExt string) string {
+	switch strings.ToLower(fileExt) {
+	case ".wav":
+		return "audio/wav"
+	case ".mp3":
+		return "audio/mpeg"
+	case ".m4a", ".mp4":
+		return "audio/mp4"
+	case ".aac":
+		return "audio/aac"
+	case ".ogg":
+		return "audio/ogg"
+	case ".flac":
+		return "audio/flac"
+	case ".webm":
+		return "audio/webm"
+	case ".amr":
+		return "audio/amr"
+	case ".caf":
+		return "audio/x-caf"
+	case ".aiff", ".aif":
+		return "audio/aiff"
+	default:
+		return "text/plain"
+	}
+}
+
 // Transcribe sends audio file to FPT.AI STT API and returns transcript
-func (p *FPTProvider) Transcribe(audioPath string) (*Result, error) {
+func (p *FPTProvider) Transcribe(ctx context.Context, audioPath string) (*Result, error) {
 	startTime := time.Now()
 
+	// Formats FPT.AI does not accept (e.g. 3GP from Android) are converted to WAV
+	audioPath, cleanup, err := prepareAudio(ctx, "[FPT STT]", audioPath, fptNativeExts, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
 	// Read audio file
 	audioBytes, err := os.ReadFile(audioPath)
 	if err != nil {
@@ -58,22 +109,23 @@ func (p *FPTProvider) Transcribe(audioPath string) (*Result, error) {
 		audioPath, len(audioBytes), fileExt)
 
 	// Check if audio file is too small (likely empty or corrupted)
-	if len(audioBytes) < 1000 {
-		return nil, fmt.Errorf("audio file too small (%d bytes), may be empty or corrupted", len(audioBytes))
+	if err := audio.CheckSize(int64(len(audioBytes))); err != nil {
+		return nil, err
 	}
 
-	// Create request
-	req, err := http.NewRequest("POST", p.url, bytes.NewReader(audioBytes))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	// Build request (rebuilt on every retry attempt)
+	newRequest := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", p.url, bytes.NewReader(audioBytes))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("api-key", p.apiKey)
+		req.Header.Set("Content-Type", getContentType(fileExt))
+		return req, nil
 	}
 
-	req.Header.Set("api-key", p.apiKey)
-	req.Header.Set("Content-Type", "text/plain")
-
-	// Send request with timeout
-	client := &http.Client{Timeout: 90 * time.Second}
-	resp, err := client.Do(req)
+	// Send request with timeout, retrying transient errors
+	resp, err := doWithRetry(ctx, p.httpClient, "[FPT STT]", newRequest)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request to FPT.AI: %w", err)
 	}
@@ -101,6 +153,20 @@ func (p *FPTProvider) Transcribe(audioPath string) (*Result, error) {
 		}, fmt.Errorf("FPT.AI API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
+	result, err := p.parseResponse(body)
+	if err != nil {
+		return result, err
+	}
+
+	result.Duration = time.Since(startTime)
+	log.Printf("[FPT STT] Transcription successful: confidence=%.2f, length=%d, duration=%v",
+		result.Confidence, len(result.Transcript), result.Duration)
+	return result, nil
+}
+
+// parseResponse turns an FPT.AI transcription response (synchronous or callback) into a Result.
+// On error the returned Result still carries the raw body for debugging.
+func (p *FPTProvider) parseResponse(body []byte) (*Result, error) {
 	// Parse JSON response
 	var sttResp FPTSTTResponse
 	if err := json.Unmarshal(body, &sttResp); err != nil {
@@ -132,7 +198,7 @@ func (p *FPTProvider) Transcribe(audioPath string) (*Result, error) {
 	// Get the first (best) hypothesis
 	hyp := sttResp.Hypotheses[0]
 	transcript := strings.TrimSpace(hyp.Utterance)
-	confidence := hyp.Confidence
+	confidence, _ := NormalizeConfidence(p.Name(), hyp.Confidence)
 
 	// Empty transcript is not valid
 	if transcript == "" {
@@ -143,14 +209,11 @@ func (p *FPTProvider) Transcribe(audioPath string) (*Result, error) {
 		}, fmt.Errorf("empty transcript returned")
 	}
 
-	duration := time.Since(startTime)
-	log.Printf("[FPT STT] Transcription successful: confidence=%.2f, length=%d, duration=%v",
-		confidence, len(transcript), duration)
-
 	return &Result{
-		Transcript:  transcript,
-		Confidence:  confidence,
-		Provider:    p.Name(),
-		RawResponse: string(body),
+		Transcript:    transcript,
+		Confidence:    confidence,
+		RawConfidence: hyp.Confidence,
+		Provider:      p.Name(),
+		RawResponse:   string(body),
 	}, nil
 }
@@ -0,0 +1,90 @@
+package stt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"noteme/internal/audio"
+	"os"
+	"path/filepath"
+)
+
+// FPTAsyncResponse is the reply of FPT_AI_STT_ASYNC_URL to a submitted job. The transcript
+// itself is POSTed to the callback URL later, in the same shape as FPTSTTResponse.
+type FPTAsyncResponse struct {
+	RequestID string `json:"request_id"`
+	ErrorCode int    `json:"errorCode,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+// Submit uploads audio to FPT_AI_STT_ASYNC_URL with a callback_url header and returns the job ID
+func (p *FPTProvider) Submit(ctx context.Context, audioPath, callbackURL string) (string, error) {
+	if p.asyncURL == "" {
+		return "", fmt.Errorf("FPT_AI_STT_ASYNC_URL is not set")
+	}
+
+	audioPath, cleanup, err := prepareAudio(ctx, "[FPT STT]", audioPath, fptNativeExts, nil)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	audioBytes, err := os.ReadFile(audioPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read audio file: %w", err)
+	}
+	if err := audio.CheckSize(int64(len(audioBytes))); err != nil {
+		return "", err
+	}
+
+	fileExt := filepath.Ext(audioPath)
+	log.Printf("[FPT STT] Submitting async job: %s, size: %d bytes", audioPath, len(audioBytes))
+
+	newRequest := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", p.asyncURL, bytes.NewReader(audioBytes))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("api-key", p.apiKey)
+		req.Header.Set("Content-Type", getContentType(fileExt))
+		req.Header.Set("callback_url", callbackURL)
+		return req, nil
+	}
+
+	resp, err := doWithRetry(ctx, p.httpClient, "[FPT STT]", newRequest)
+	if err != nil {
+		return "", fmt.Errorf("failed to submit job to FPT.AI: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return "", fmt.Errorf("FPT.AI API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var jobResp FPTAsyncResponse
+	if err := json.Unmarshal(body, &jobResp); err != nil {
+		return "", fmt.Errorf("failed to parse FPT.AI response: %w", err)
+	}
+	if jobResp.ErrorCode != 0 {
+		return "", fmt.Errorf("FPT.AI API error %d: %s", jobResp.ErrorCode, jobResp.Message)
+	}
+	if jobResp.RequestID == "" {
+		return "", fmt.Errorf("FPT.AI accepted the job without a request_id: %s", string(body))
+	}
+
+	log.Printf("[FPT STT] Async job submitted: %s", jobResp.RequestID)
+	return jobResp.RequestID, nil
+}
+
+// ParseCallback parses the transcription FPT.AI POSTs to the callback URL
+func (p *FPTProvider) ParseCallback(body []byte) (*Result, error) {
+	return p.parseResponse(body)
+}
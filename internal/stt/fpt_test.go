@@ -0,0 +1,81 @@
+package stt
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetContentType(t *testing.T) {
+	tests := map[string]string{
+		".wav":  "audio/wav",
+		".WAV":  "audio/wav",
+		".mp3":  "audio/mpeg",
+		".m4a":  "audio/mp4",
+		".mp4":  "audio/mp4",
+		".aac":  "audio/aac",
+		".ogg":  "audio/ogg",
+		".flac": "audio/flac",
+		".webm": "audio/webm",
+		".amr":  "audio/amr",
+		".caf":  "audio/x-caf",
+		".aif":  "audio/aiff",
+		".txt":  "text/plain",
+	}
+	for ext, want := range tests {
+		if got := getContentType(ext); got != want {
+			t.Errorf("getContentType(%q) = %q, want %q", ext, got, want)
+		}
+	}
+	// Every format uploaded as-is must have a real audio MIME type
+	for ext := range fptNativeExts {
+		if got := getContentType(ext); got == "text/plain" {
+			t.Errorf("getContentType(%q) = text/plain for a format FPT.AI receives as-is", ext)
+		}
+	}
+}
+
+// TestFPTTranscribeSendsWAVContentType uploads a .wav file to a fake FPT.AI endpoint and checks
+// the request it receives
+func TestFPTTranscribeSendsWAVContentType(t *testing.T) {
+	audioBytes := make([]byte, 64*1024)
+	audioPath := filepath.Join(t.TempDir(), "note.wav")
+	if err := os.WriteFile(audioPath, audioBytes, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var gotContentType, gotAPIKey string
+	var gotBodySize int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotContentType = r.Header.Get("Content-Type")
+		gotAPIKey = r.Header.Get("api-key")
+		body, _ := io.ReadAll(r.Body)
+		gotBodySize = len(body)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"hypotheses":[{"utterance":"xin chào","confidence":0.9}]}`)
+	}))
+	defer server.Close()
+
+	provider := NewFPTProvider("fpt-test-key", server.URL, 5*time.Second)
+	result, err := provider.Transcribe(context.Background(), audioPath)
+	if err != nil {
+		t.Fatalf("Transcribe: %v", err)
+	}
+	if gotContentType != "audio/wav" {
+		t.Errorf("Content-Type = %q, want audio/wav", gotContentType)
+	}
+	if gotAPIKey != "fpt-test-key" {
+		t.Errorf("api-key = %q, want fpt-test-key", gotAPIKey)
+	}
+	if gotBodySize != len(audioBytes) {
+		t.Errorf("uploaded %d bytes, want the %d bytes of the file", gotBodySize, len(audioBytes))
+	}
+	if result.Transcript != "xin chào" || result.Provider != "fpt" {
+		t.Errorf("result transcript %q, provider %q; want xin chào from fpt", result.Transcript, result.Provider)
+	}
+}
@@ -9,8 +9,8 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"noteme/internal/audio"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -25,49 +25,134 @@ type GoogleProvider struct {
 	apiKey     string
 	keyFile    string
 	httpClient *http.Client
-	useAPIKey  bool // true if using API key, false if using service account
+	useAPIKey  bool   // true if using API key, false if using service account
+	language   string // STT_LANGUAGE: vi-VN, en-US or auto
+
+	model       string // GOOGLE_STT_MODEL, or GoogleModelAuto to choose by duration
+	useEnhanced bool   // GOOGLE_STT_USE_ENHANCED
+}
+
+// Google authentication modes (GOOGLE_STT_AUTH_MODE)
+const (
+	GoogleAuthAPIKey         = "apikey"
+	GoogleAuthServiceAccount = "service_account"
+	GoogleAuthADC            = "adc"
+)
+
+const googleCloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// errGoogleCredentials is returned when credentials cannot be interpreted in any supported mode
+var errGoogleCredentials = fmt.Errorf("cannot interpret Google credentials. Supported GOOGLE_STT_AUTH_MODE values:\n" +
+	"  - apikey: GOOGLE_STT_KEY_FILE is an API key\n" +
+	"  - service_account: GOOGLE_STT_KEY_FILE is a path to a JSON key file or the JSON itself\n" +
+	"  - adc: Application Default Credentials (GOOGLE_STT_KEY_FILE may be empty)")
+
+// DetectGoogleAuthMode decides how keyData should be used.
+// An explicit mode (GOOGLE_STT_AUTH_MODE) wins; otherwise empty means ADC, JSON or an
+// existing file means service account, and anything else is treated as an API key.
+func DetectGoogleAuthMode(keyData, mode string) (string, error) {
+	keyData = strings.TrimSpace(keyData)
+	mode = strings.ToLower(strings.TrimSpace(mode))
+
+	switch mode {
+	case GoogleAuthAPIKey:
+		if keyData == "" {
+			return "", fmt.Errorf("GOOGLE_STT_AUTH_MODE=apikey requires GOOGLE_STT_KEY_FILE to hold the API key: %w", errGoogleCredentials)
+		}
+		return mode, nil
+	case GoogleAuthServiceAccount:
+		if !isJSONCredentials(keyData) && !fileExists(keyData) {
+			return "", fmt.Errorf("GOOGLE_STT_AUTH_MODE=service_account requires GOOGLE_STT_KEY_FILE to be a JSON key or an existing file: %w", errGoogleCredentials)
+		}
+		return mode, nil
+	case GoogleAuthADC:
+		return mode, nil
+	case "":
+		// auto-detect below
+	default:
+		return "", fmt.Errorf("unsupported GOOGLE_STT_AUTH_MODE %q: %w", mode, errGoogleCredentials)
+	}
+
+	switch {
+	case keyData == "":
+		return GoogleAuthADC, nil
+	case isJSONCredentials(keyData), fileExists(keyData):
+		return GoogleAuthServiceAccount, nil
+	case strings.HasSuffix(strings.ToLower(keyData), ".json"):
+		// Looks like a key file path, but the file is missing
+		return "", fmt.Errorf("key file '%s' not found: %w", keyData, errGoogleCredentials)
+	default:
+		return GoogleAuthAPIKey, nil
+	}
 }
 
-// NewGoogleProvider creates a new Google STT provider
+// isJSONCredentials reports whether keyData is an inline JSON object
+func isJSONCredentials(keyData string) bool {
+	return strings.HasPrefix(keyData, "{") && json.Valid([]byte(keyData))
+}
+
+// fileExists reports whether path names an existing regular file
+func fileExists(path string) bool {
+	if path == "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// NewGoogleProvider creates a new Google STT provider, auto-detecting the auth mode.
 // keyData can be either:
-//   - An API key (39 characters, typically starts with "AIzaSy")
+//   - An API key
 //   - A file path to a JSON key file (e.g., "./keys/google-service-account.json")
 //   - A JSON string containing the service account credentials
+//   - Empty, to use Application Default Credentials
 func NewGoogleProvider(projectID, keyData string) (*GoogleProvider, error) {
+	return NewGoogleProviderWithMode(projectID, keyData, "", DefaultHTTPTimeout)
+}
+
+// NewGoogleProviderWithMode creates a Google STT provider using an explicit auth mode
+// (apikey, service_account, adc); an empty mode auto-detects from keyData.
+// timeout bounds each HTTP call to Google (DefaultHTTPTimeout if zero).
+func NewGoogleProviderWithMode(projectID, keyData, mode string, timeout time.Duration) (*GoogleProvider, error) {
 	keyDataTrimmed := strings.TrimSpace(keyData)
+	if timeout <= 0 {
+		timeout = DefaultHTTPTimeout
+	}
+
+	authMode, err := DetectGoogleAuthMode(keyDataTrimmed, mode)
+	if err != nil {
+		return nil, err
+	}
 
-	// Check if it's an API key (typically 39 chars, starts with "AIzaSy")
-	if len(keyDataTrimmed) == 39 && strings.HasPrefix(keyDataTrimmed, "AIzaSy") {
+	if authMode == GoogleAuthAPIKey {
 		log.Printf("[Google STT] Using API key authentication")
 		return &GoogleProvider{
-			projectID:  projectID,
-			apiKey:     keyDataTrimmed,
-			httpClient: &http.Client{Timeout: 90 * time.Second},
-			useAPIKey:  true,
+			projectID:   projectID,
+			apiKey:      keyDataTrimmed,
+			httpClient:  &http.Client{Timeout: timeout},
+			useAPIKey:   true,
+			model:       GoogleModelLatestLong,
+			useEnhanced: true,
 		}, nil
 	}
 
-	// Otherwise, treat as service account (JSON file or JSON string)
+	// Otherwise, use OAuth credentials (service account JSON or default credentials)
 	ctx := context.Background()
-	var client *http.Client
-	var jsonData []byte
-	var err error
+	var creds *google.Credentials
 
-	if keyDataTrimmed == "" {
-		// Try to use default credentials
-		creds, err := google.FindDefaultCredentials(ctx, "https://www.googleapis.com/auth/cloud-platform")
+	if authMode == GoogleAuthADC {
+		log.Printf("[Google STT] Using Application Default Credentials")
+		creds, err = google.FindDefaultCredentials(ctx, googleCloudPlatformScope)
 		if err != nil {
 			return nil, fmt.Errorf("failed to find default credentials: %w. Please set GOOGLE_STT_KEY_FILE", err)
 		}
-		client = oauth2.NewClient(ctx, creds.TokenSource)
 	} else {
-		// Check if keyData is a JSON string (starts with {) or a file path
-		if strings.HasPrefix(keyDataTrimmed, "{") {
-			// It's a JSON string
+		var jsonData []byte
+		// Check if keyData is a JSON string or a file path
+		if isJSONCredentials(keyDataTrimmed) {
 			log.Printf("[Google STT] Using JSON string from environment variable")
 			jsonData = []byte(keyDataTrimmed)
 		} else {
-			// It's a file path
 			log.Printf("[Google STT] Reading key file: %s", keyDataTrimmed)
 			jsonData, err = os.ReadFile(keyDataTrimmed)
 			if err != nil {
@@ -76,18 +161,29 @@ func NewGoogleProvider(projectID, keyData string) (*GoogleProvider, error) {
 		}
 
 		// Parse credentials from JSON
-		creds, err := google.CredentialsFromJSON(ctx, jsonData, "https://www.googleapis.com/auth/cloud-platform")
+		creds, err = google.CredentialsFromJSON(ctx, jsonData, googleCloudPlatformScope)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create credentials from JSON: %w", err)
 		}
-		client = oauth2.NewClient(ctx, creds.TokenSource)
 	}
 
+	if projectID == "" {
+		projectID = creds.ProjectID
+	}
+	if projectID == "" {
+		return nil, fmt.Errorf("GOOGLE_STT_PROJECT_ID environment variable is required when using %s authentication", authMode)
+	}
+
+	httpClient := oauth2.NewClient(ctx, creds.TokenSource)
+	httpClient.Timeout = timeout
+
 	return &GoogleProvider{
-		projectID:  projectID,
-		keyFile:    keyDataTrimmed,
-		httpClient: client,
-		useAPIKey:  false,
+		projectID:   projectID,
+		keyFile:     keyDataTrimmed,
+		httpClient:  httpClient,
+		useAPIKey:   false,
+		model:       GoogleModelLatestLong,
+		useEnhanced: true,
 	}, nil
 }
 
@@ -104,12 +200,23 @@ type GoogleSTTRequest struct {
 
 // GoogleSTTConfig represents recognition config
 type GoogleSTTConfig struct {
-	Encoding                   string `json:"encoding"`
-	SampleRateHertz            int    `json:"sampleRateHertz"`
-	LanguageCode               string `json:"languageCode"`
-	EnableAutomaticPunctuation bool   `json:"enableAutomaticPunctuation"`
-	Model                      string `json:"model,omitempty"`
-	UseEnhanced                bool   `json:"useEnhanced,omitempty"`
+	Encoding                   string                   `json:"encoding"`
+	SampleRateHertz            int                      `json:"sampleRateHertz"`
+	LanguageCode               string                   `json:"languageCode"`
+	EnableAutomaticPunctuation bool                     `json:"enableAutomaticPunctuation"`
+	Model                      string                   `json:"model,omitempty"`
+	UseEnhanced                bool                     `json:"useEnhanced,omitempty"`
+	AlternativeLanguageCodes   []string                 `json:"alternativeLanguageCodes,omitempty"`
+	DiarizationConfig          *GoogleDiarizationConfig `json:"diarizationConfig,omitempty"`
+	ProfanityFilter            bool                     `json:"profanityFilter,omitempty"`
+	EnableWordTimeOffsets      bool                     `json:"enableWordTimeOffsets,omitempty"`
+}
+
+// GoogleDiarizationConfig enables speaker diarization
+type GoogleDiarizationConfig struct {
+	EnableSpeakerDiarization bool `json:"enableSpeakerDiarization"`
+	MinSpeakerCount          int  `json:"minSpeakerCount,omitempty"`
+	MaxSpeakerCount          int  `json:"maxSpeakerCount,omitempty"`
 }
 
 // GoogleSTTAudio represents audio data
@@ -126,12 +233,22 @@ type GoogleSTTResponse struct {
 // GoogleSTTResult represents a recognition result
 type GoogleSTTResult struct {
 	Alternatives []GoogleSTTAlternative `json:"alternatives"`
+	LanguageCode string                 `json:"languageCode,omitempty"` // detected language when alternativeLanguageCodes is set
 }
 
 // GoogleSTTAlternative represents a transcript alternative
 type GoogleSTTAlternative struct {
-	Transcript string  `json:"transcript"`
-	Confidence float64 `json:"confidence"`
+	Transcript string          `json:"transcript"`
+	Confidence float64         `json:"confidence"`
+	Words      []GoogleSTTWord `json:"words,omitempty"`
+}
+
+// GoogleSTTWord is word-level info, returned with a speaker tag when diarization is enabled
+type GoogleSTTWord struct {
+	Word       string `json:"word"`
+	StartTime  string `json:"startTime"` // duration string, e.g. "1.500s"
+	EndTime    string `json:"endTime"`
+	SpeakerTag int    `json:"speakerTag"`
 }
 
 // GoogleSTTError represents an API error
@@ -141,79 +258,21 @@ type GoogleSTTError struct {
 	Status  string `json:"status"`
 }
 
-// convertM4AToWAV converts M4A file to WAV format using ffmpeg
-func convertM4AToWAV(inputPath string) (string, error) {
-	// Create temporary output file
-	outputPath := inputPath + ".converted.wav"
-
-	log.Printf("[Google STT] Converting M4A to WAV: %s -> %s", inputPath, outputPath)
-
-	// Run ffmpeg to convert M4A to WAV
-	// -i: input file
-	// -acodec pcm_s16le: PCM 16-bit little-endian (LINEAR16 format)
-	// -ar 44100: sample rate 44100 Hz
-	// -ac 1: mono channel (can be changed to 2 for stereo)
-	// -y: overwrite output file if exists
-	cmd := exec.Command("ffmpeg", "-i", inputPath, "-acodec", "pcm_s16le", "-ar", "44100", "-ac", "1", "-y", outputPath)
-
-	// Capture stderr for error messages
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
-	if err != nil {
-		errorMsg := stderr.String()
-		log.Printf("[Google STT] FFmpeg conversion failed: %v, stderr: %s", err, errorMsg)
-		return "", fmt.Errorf("failed to convert M4A to WAV: %w, ffmpeg error: %s", err, errorMsg)
-	}
-
-	// Verify output file exists and is not empty
-	info, err := os.Stat(outputPath)
-	if err != nil {
-		return "", fmt.Errorf("converted file not found: %w", err)
-	}
-	if info.Size() < 1000 {
-		os.Remove(outputPath)
-		return "", fmt.Errorf("converted file too small (%d bytes), conversion may have failed", info.Size())
-	}
-
-	log.Printf("[Google STT] Conversion successful: %s (%d bytes)", outputPath, info.Size())
-	return outputPath, nil
-}
-
 // Transcribe transcribes an audio file using Google Cloud Speech-to-Text REST API
-func (p *GoogleProvider) Transcribe(audioPath string) (*Result, error) {
+func (p *GoogleProvider) Transcribe(ctx context.Context, audioPath string) (*Result, error) {
 	startTime := time.Now()
 
 	// Log audio file info
 	fileExt := strings.ToLower(filepath.Ext(audioPath))
 	log.Printf("[Google STT] Processing audio file: %s, extension: %s", audioPath, fileExt)
 
-	// Check if file needs conversion (M4A or AAC from iPhone)
-	actualAudioPath := audioPath
-	needsCleanup := false
-
-	if fileExt == ".m4a" || fileExt == ".aac" {
-		log.Printf("[Google STT] Detected M4A/AAC file, converting to WAV for Google STT compatibility")
-		convertedPath, err := convertM4AToWAV(audioPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to convert M4A/AAC to WAV: %w", err)
-		}
-		actualAudioPath = convertedPath
-		needsCleanup = true
-		fileExt = ".wav" // Update extension for config
-	}
-
-	// Cleanup converted file after processing
-	defer func() {
-		if needsCleanup {
-			if err := os.Remove(actualAudioPath); err != nil {
-				log.Printf("[Google STT] Warning: failed to cleanup converted file %s: %v", actualAudioPath, err)
-			} else {
-				log.Printf("[Google STT] Cleaned up converted file: %s", actualAudioPath)
-			}
-		}
-	}()
+	// Formats Google cannot decode (M4A/AAC from iPhone, AMR/3GP from Android, ...) are converted to WAV
+	actualAudioPath, cleanup, err := prepareAudio(ctx, "[Google STT]", audioPath, googleNativeExts, googleFallbackExts)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	fileExt = strings.ToLower(filepath.Ext(actualAudioPath)) // .wav after conversion, unless sent as-is
 
 	// Read audio file (original or converted)
 	audioBytes, err := os.ReadFile(actualAudioPath)
@@ -224,8 +283,8 @@ func (p *GoogleProvider) Transcribe(audioPath string) (*Result, error) {
 	log.Printf("[Google STT] Audio file size: %d bytes", len(audioBytes))
 
 	// Check if audio file is too small
-	if len(audioBytes) < 1000 {
-		return nil, fmt.Errorf("audio file too small (%d bytes), may be empty or corrupted", len(audioBytes))
+	if err := audio.CheckSize(int64(len(audioBytes))); err != nil {
+		return nil, err
 	}
 
 	// Determine encoding and sample rate based on file extension (now WAV after conversion)
@@ -234,15 +293,44 @@ func (p *GoogleProvider) Transcribe(audioPath string) (*Result, error) {
 	// Base64 encode audio
 	audioBase64 := base64.StdEncoding.EncodeToString(audioBytes)
 
+	// With STT_LANGUAGE=auto, let Google pick between Vietnamese and the alternative languages
+	languageCode := p.language
+	var alternativeLanguageCodes []string
+	if languageCode == "" || languageCode == LanguageAuto {
+		if languageCode == LanguageAuto {
+			alternativeLanguageCodes = alternativeLanguages
+		}
+		languageCode = LanguageVietnamese
+	}
+
+	// Speaker diarization is opt-in per request since it costs more
+	opts := OptionsFromContext(ctx)
+	var diarization *GoogleDiarizationConfig
+	if opts.Diarization {
+		diarization = &GoogleDiarizationConfig{EnableSpeakerDiarization: true}
+		if opts.SpeakerCount > 0 {
+			diarization.MinSpeakerCount = opts.SpeakerCount
+			diarization.MaxSpeakerCount = opts.SpeakerCount
+		}
+		log.Printf("[Google STT] Speaker diarization enabled (speakers: %d)", opts.SpeakerCount)
+	}
+
+	model := p.recognitionModel(actualAudioPath)
+	log.Printf("[Google STT] Model: %s, enhanced: %v", model, p.useEnhanced)
+
 	// Prepare request
 	reqBody := GoogleSTTRequest{
 		Config: GoogleSTTConfig{
 			Encoding:                   encoding,
 			SampleRateHertz:            sampleRate,
-			LanguageCode:               "vi-VN",
+			LanguageCode:               languageCode,
 			EnableAutomaticPunctuation: true,
-			Model:                      "latest_long",
-			UseEnhanced:                true,
+			Model:                      model,
+			UseEnhanced:                p.useEnhanced,
+			AlternativeLanguageCodes:   alternativeLanguageCodes,
+			DiarizationConfig:          diarization,
+			ProfanityFilter:            opts.FilterProfanity, // masks all but the first letter, e.g. "f***"
+			EnableWordTimeOffsets:      true,                 // for sentence timestamps (see GroupSentences)
 		},
 		Audio: GoogleSTTAudio{
 			Content: audioBase64,
@@ -267,17 +355,19 @@ func (p *GoogleProvider) Transcribe(audioPath string) (*Result, error) {
 		log.Printf("[Google STT] Using service account authentication (endpoint: /v1/projects/%s:recognize)", p.projectID)
 	}
 
-	// Create HTTP request
-	req, err := http.NewRequest("POST", apiURL, bytes.NewReader(reqJSON))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	// Build HTTP request (rebuilt on every retry attempt)
+	newRequest := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewReader(reqJSON))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-
-	// Send request
+	// Send request, retrying transient errors
 	log.Printf("[Google STT] Calling Google Speech-to-Text API...")
-	resp, err := p.httpClient.Do(req)
+	resp, err := doWithRetry(ctx, p.httpClient, "[Google STT]", newRequest)
 	if err != nil {
 		log.Printf("[Google STT] HTTP error: %v", err)
 		return &Result{
@@ -357,7 +447,7 @@ func (p *GoogleProvider) Transcribe(audioPath string) (*Result, error) {
 	// Get the best alternative
 	alternative := result.Alternatives[0]
 	transcript := strings.TrimSpace(alternative.Transcript)
-	confidence := alternative.Confidence
+	confidence, _ := NormalizeConfidence(p.Name(), alternative.Confidence)
 
 	// Empty transcript is not valid
 	if transcript == "" {
@@ -368,18 +458,115 @@ func (p *GoogleProvider) Transcribe(audioPath string) (*Result, error) {
 		}, fmt.Errorf("empty transcript returned")
 	}
 
+	// Google reports the detected language (lowercase, e.g. "en-us") when alternatives were offered
+	detectedLanguage := languageCode
+	if result.LanguageCode != "" {
+		if lang := normalizeLanguageCode(result.LanguageCode); lang != "" {
+			detectedLanguage = lang
+		}
+	} else if len(alternativeLanguageCodes) > 0 {
+		// No detection reported: let ResolveLanguage fall back to the transcript text
+		detectedLanguage = ""
+	}
+
+	var segments []Segment
+	if diarization != nil {
+		segments = googleSpeakerSegments(sttResp.Results)
+		log.Printf("[Google STT] Diarization produced %d segments", len(segments))
+	}
+
 	duration := time.Since(startTime)
-	log.Printf("[Google STT] Transcription successful: confidence=%.2f, length=%d, duration=%v",
-		confidence, len(transcript), duration)
+	log.Printf("[Google STT] Transcription successful: confidence=%.2f, length=%d, language=%s, duration=%v",
+		confidence, len(transcript), detectedLanguage, duration)
 
 	return &Result{
-		Transcript:  transcript,
-		Confidence:  confidence,
-		Provider:    p.Name(),
-		RawResponse: string(body),
+		Transcript:    transcript,
+		Confidence:    confidence,
+		RawConfidence: alternative.Confidence,
+		Provider:      p.Name(),
+		RawResponse:   string(body),
+		Duration:      duration,
+		Language:      detectedLanguage,
+		Segments:      segments,
+		Words:         googleWords(alternative, sttResp.Results, diarization != nil),
+
+		ProfanityMasked: opts.FilterProfanity,
 	}, nil
 }
 
+// googleWords returns the word timings of the transcript. With diarization Google repeats every
+// word of the audio in the last result, so only that one is used.
+func googleWords(alternative GoogleSTTAlternative, results []GoogleSTTResult, diarized bool) []Word {
+	words := alternative.Words
+	if diarized && len(results[len(results)-1].Alternatives) > 0 {
+		words = results[len(results)-1].Alternatives[0].Words
+	}
+	if len(words) == 0 {
+		return nil
+	}
+
+	out := make([]Word, 0, len(words))
+	for _, w := range words {
+		out = append(out, Word{
+			Text:      w.Word,
+			StartTime: parseGoogleDuration(w.StartTime),
+			EndTime:   parseGoogleDuration(w.EndTime),
+		})
+	}
+	return out
+}
+
+// googleSpeakerSegments groups diarized words into speaker segments.
+// With diarization, Google repeats every word with its speakerTag in the last result.
+func googleSpeakerSegments(results []GoogleSTTResult) []Segment {
+	if len(results) == 0 || len(results[len(results)-1].Alternatives) == 0 {
+		return nil
+	}
+	words := results[len(results)-1].Alternatives[0].Words
+
+	var segments []Segment
+	var text []string
+	for _, w := range words {
+		if w.SpeakerTag == 0 {
+			continue
+		}
+		if len(segments) == 0 || segments[len(segments)-1].Speaker != w.SpeakerTag {
+			if len(segments) > 0 {
+				segments[len(segments)-1].Text = strings.Join(text, " ")
+			}
+			segments = append(segments, Segment{Speaker: w.SpeakerTag, StartTime: parseGoogleDuration(w.StartTime)})
+			text = text[:0]
+		}
+		text = append(text, w.Word)
+		segments[len(segments)-1].EndTime = parseGoogleDuration(w.EndTime)
+	}
+	if len(segments) > 0 {
+		segments[len(segments)-1].Text = strings.Join(text, " ")
+	}
+	return segments
+}
+
+// parseGoogleDuration parses durations like "1.500s" into seconds (0 if invalid)
+func parseGoogleDuration(s string) float64 {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0
+	}
+	return d.Seconds()
+}
+
+// googleNativeExts are sent to Google as-is; everything else is converted to WAV first
+var googleNativeExts = map[string]bool{
+	".wav": true, ".aiff": true, ".aif": true, ".mp3": true, ".ogg": true, ".flac": true,
+}
+
+// googleFallbackExts are converted to WAV when possible (more reliable), but sent as-is when ffmpeg
+// is not installed since Google has an encoding for them (see getGoogleAudioConfig).
+// M4A/AAC, 3GP, MP4, CAF and WMA have no Google v1 encoding and always need ffmpeg.
+var googleFallbackExts = map[string]bool{
+	".amr": true, ".webm": true, ".opus": true,
+}
+
 // getGoogleAudioConfig determines encoding and sample rate based on file extension
 // Note: Google Speech-to-Text API supports: LINEAR16, FLAC, MULAW, AMR, AMR_WB, OGG_OPUS, SPEEX_WITH_HEADER_BYTE, MP3
 // iPhone formats: M4A (AAC) - not directly supported, CAF/WAV/AIFF - use LINEAR16, MP3 - supported
@@ -396,6 +583,14 @@ func getGoogleAudioConfig(fileExt string) (string, int) {
 		// This case should not be reached as conversion happens in Transcribe()
 		// But kept for safety - will use LINEAR16 (WAV format)
 		return "LINEAR16", 44100
+	case ".amr":
+		// Only reached when ffmpeg is unavailable (see googleFallbackExts); AMR narrowband is always 8kHz
+		return "AMR", 8000
+	case ".webm":
+		return "WEBM_OPUS", 48000
+	case ".opus":
+		// .opus files are Opus in an Ogg container
+		return "OGG_OPUS", 48000
 	case ".caf":
 		// CAF (Core Audio Format) - Apple's native format, often contains uncompressed audio
 		// Try LINEAR16 (may need conversion in practice)
@@ -0,0 +1,45 @@
+package stt
+
+import (
+	"log"
+	"noteme/internal/audio"
+)
+
+// Google recognition models (GOOGLE_STT_MODEL, validated by config.Load)
+const (
+	GoogleModelLatestLong          = "latest_long"
+	GoogleModelLatestShort         = "latest_short"
+	GoogleModelPhoneCall           = "phone_call"
+	GoogleModelVideo               = "video"
+	GoogleModelCommandAndSearch    = "command_and_search"
+	GoogleModelDefault             = "default"
+	GoogleModelMedicalDictation    = "medical_dictation"
+	GoogleModelMedicalConversation = "medical_conversation"
+
+	// GoogleModelAuto picks latest_short or latest_long from the audio duration
+	GoogleModelAuto = "auto"
+)
+
+// googleShortAudioSeconds is the longest audio GoogleModelAuto sends to latest_short
+const googleShortAudioSeconds = 15
+
+// recognitionModel returns the model to request for an audio file, resolving GoogleModelAuto
+// from its duration. Audio whose duration cannot be read gets latest_long.
+func (p *GoogleProvider) recognitionModel(audioPath string) string {
+	if p.model == "" {
+		return GoogleModelLatestLong
+	}
+	if p.model != GoogleModelAuto {
+		return p.model
+	}
+
+	duration, err := audio.ProbeDuration(audioPath)
+	if err != nil {
+		log.Printf("[Google STT] Could not read duration for model selection, using %s: %v", GoogleModelLatestLong, err)
+		return GoogleModelLatestLong
+	}
+	if duration <= googleShortAudioSeconds {
+		return GoogleModelLatestShort
+	}
+	return GoogleModelLatestLong
+}
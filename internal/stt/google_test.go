@@ -0,0 +1,61 @@
+package stt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectGoogleAuthMode(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "service-account.json")
+	if err := os.WriteFile(keyFile, []byte(`{"type":"service_account"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	missingKeyFile := filepath.Join(t.TempDir(), "missing.json")
+	inlineJSON := `{"type":"service_account","project_id":"noteme"}`
+
+	tests := []struct {
+		name    string
+		keyData string
+		mode    string
+		want    string // "" when an error is expected
+	}{
+		// Auto-detection
+		{"empty key uses ADC", "", "", GoogleAuthADC},
+		{"blank key uses ADC", "  \n", "", GoogleAuthADC},
+		{"inline JSON", inlineJSON, "", GoogleAuthServiceAccount},
+		{"existing key file", keyFile, "", GoogleAuthServiceAccount},
+		{"missing .json file", missingKeyFile, "", ""},
+		{"invalid JSON is an API key", "{not json", "", GoogleAuthAPIKey},
+		{"API key", "AIzaSyExampleKey", "", GoogleAuthAPIKey},
+
+		// Explicit GOOGLE_STT_AUTH_MODE overrides detection
+		{"apikey with a key", "AIzaSyExampleKey", "apikey", GoogleAuthAPIKey},
+		{"apikey with a file path", keyFile, "apikey", GoogleAuthAPIKey},
+		{"apikey without a key", "", "apikey", ""},
+		{"service_account with inline JSON", inlineJSON, "service_account", GoogleAuthServiceAccount},
+		{"service_account with a key file", keyFile, "service_account", GoogleAuthServiceAccount},
+		{"service_account with an API key", "AIzaSyExampleKey", "service_account", ""},
+		{"service_account with a missing file", missingKeyFile, "service_account", ""},
+		{"adc ignores the key", "AIzaSyExampleKey", "adc", GoogleAuthADC},
+		{"mode is case and space insensitive", "", " ADC ", GoogleAuthADC},
+		{"unknown mode", "AIzaSyExampleKey", "oauth", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectGoogleAuthMode(tt.keyData, tt.mode)
+			if tt.want == "" {
+				// Every failure lists the supported modes
+				if !errors.Is(err, errGoogleCredentials) {
+					t.Fatalf("DetectGoogleAuthMode() = %q, %v; want an error wrapping errGoogleCredentials", got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("DetectGoogleAuthMode() = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package stt
 
+import "context"
+
 // Provider defines the interface for speech-to-text providers
 type Provider interface {
-	// Transcribe transcribes an audio file and returns the result
-	Transcribe(audioPath string) (*Result, error)
+	// Transcribe transcribes an audio file and returns the result.
+	// ctx carries the request deadline/cancellation and trace.
+	Transcribe(ctx context.Context, audioPath string) (*Result, error)
 
 	// Name returns the name of the provider (e.g., "fpt", "google")
 	Name() string
@@ -0,0 +1,128 @@
+package stt
+
+import (
+	"log"
+	"noteme/internal/config"
+	"strings"
+	"unicode"
+)
+
+// Transcription languages (BCP-47, as used by Google) and the STT_LANGUAGE=auto mode
+const (
+	LanguageVietnamese = "vi-VN"
+	LanguageEnglish    = "en-US"
+	LanguageAuto       = "auto"
+)
+
+// alternativeLanguages are the extra languages Google considers when STT_LANGUAGE=auto
+var alternativeLanguages = []string{LanguageEnglish}
+
+// ConfiguredLanguage returns STT_LANGUAGE (vi-VN | en-US | auto), defaulting to vi-VN
+func ConfiguredLanguage() string {
+	return configuredLanguage(conf)
+}
+
+// configuredLanguage normalizes cfg.STTLanguage, e.g. "en" to en-US
+func configuredLanguage(cfg *config.Config) string {
+	v := strings.TrimSpace(cfg.STTLanguage)
+	if v == "" {
+		return LanguageVietnamese
+	}
+	if strings.EqualFold(v, LanguageAuto) {
+		return LanguageAuto
+	}
+	if lang := normalizeLanguageCode(v); lang != "" {
+		return lang
+	}
+	log.Printf("[STT] Warning: unsupported STT_LANGUAGE=%q, using %s. Supported: vi-VN, en-US, auto", v, LanguageVietnamese)
+	return LanguageVietnamese
+}
+
+// normalizeLanguageCode maps codes like "en", "en-us" or "vi_VN" to a supported language, or "" if unsupported
+func normalizeLanguageCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "_", "-"))
+	switch {
+	case code == "vi" || strings.HasPrefix(code, "vi-"):
+		return LanguageVietnamese
+	case code == "en" || strings.HasPrefix(code, "en-"):
+		return LanguageEnglish
+	default:
+		return ""
+	}
+}
+
+// ResolveLanguage returns the language of a transcription: the language reported by the provider,
+// else the configured STT_LANGUAGE, else (auto) a guess from the transcript text.
+// Falls back to vi-VN when detection is uncertain.
+func ResolveLanguage(result *Result) string {
+	if result != nil {
+		if lang := normalizeLanguageCode(result.Language); lang != "" {
+			return lang
+		}
+	}
+
+	configured := ConfiguredLanguage()
+	if configured != LanguageAuto {
+		return configured
+	}
+
+	if result != nil {
+		if lang, ok := DetectTextLanguage(result.Transcript); ok {
+			return lang
+		}
+	}
+	return LanguageVietnamese
+}
+
+// englishStopwords are frequent English words that rarely appear in Vietnamese speech
+var englishStopwords = map[string]bool{
+	"the": true, "and": true, "is": true, "are": true, "to": true, "of": true, "a": true,
+	"in": true, "that": true, "it": true, "we": true, "you": true, "this": true, "for": true,
+	"on": true, "with": true, "was": true, "be": true, "have": true, "will": true, "so": true,
+}
+
+// DetectTextLanguage guesses vi-VN or en-US from a transcript sample using Vietnamese
+// diacritics and English stopwords. ok is false when the sample is too short or ambiguous.
+func DetectTextLanguage(text string) (string, bool) {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	// A short sample is enough; keep detection cheap on long transcripts
+	if len(words) > 200 {
+		words = words[:200]
+	}
+	if len(words) < 5 {
+		return "", false
+	}
+
+	vietnamese, english := 0, 0
+	for _, word := range words {
+		if hasVietnameseLetter(word) {
+			vietnamese++
+		}
+		if englishStopwords[word] {
+			english++
+		}
+	}
+
+	viRatio := float64(vietnamese) / float64(len(words))
+	enRatio := float64(english) / float64(len(words))
+	switch {
+	case viRatio >= 0.3:
+		return LanguageVietnamese, true
+	case viRatio < 0.05 && enRatio >= 0.15:
+		return LanguageEnglish, true
+	default:
+		return "", false
+	}
+}
+
+// hasVietnameseLetter reports whether word contains a letter with a Vietnamese diacritic
+func hasVietnameseLetter(word string) bool {
+	for _, r := range word {
+		if r > unicode.MaxASCII && unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
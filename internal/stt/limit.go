@@ -0,0 +1,71 @@
+package stt
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrBusy is returned when a transcription waited longer than STT_QUEUE_TIMEOUT for a free slot
+var ErrBusy = errors.New("too many transcriptions in progress, please retry later")
+
+var (
+	slots     chan struct{}
+	slotsOnce sync.Once
+)
+
+// transcriptionSlots returns the process-wide semaphore sized by STT_MAX_CONCURRENT
+// (default 4), or nil when the limit is disabled with 0
+func transcriptionSlots() chan struct{} {
+	slotsOnce.Do(func() {
+		limit := conf.STTMaxConcurrent
+		if limit == 0 {
+			log.Printf("[STT] Concurrent transcriptions unlimited (STT_MAX_CONCURRENT=0)")
+			return
+		}
+		log.Printf("[STT] At most %d concurrent transcriptions (STT_MAX_CONCURRENT)", limit)
+		slots = make(chan struct{}, limit)
+	})
+	return slots
+}
+
+// queueTimeout returns STT_QUEUE_TIMEOUT, how long a transcription may wait for a slot (default 30s)
+func queueTimeout() time.Duration {
+	return conf.STTQueueTimeout
+}
+
+// limitedProvider queues Transcribe calls so that at most STT_MAX_CONCURRENT run at once
+// across all providers, bounding provider rate limits and the memory used by audio reads
+type limitedProvider struct {
+	Provider
+}
+
+func (p limitedProvider) Transcribe(ctx context.Context, audioPath string) (*Result, error) {
+	sem := transcriptionSlots()
+	if sem == nil {
+		return p.Provider.Transcribe(ctx, audioPath)
+	}
+
+	select {
+	case sem <- struct{}{}:
+	default:
+		// All slots busy: wait in line
+		start := time.Now()
+		log.Printf("[STT] %s: all %d transcription slots busy, queueing %s", p.Name(), cap(sem), audioPath)
+		timer := time.NewTimer(queueTimeout())
+		defer timer.Stop()
+		select {
+		case sem <- struct{}{}:
+			log.Printf("[STT] %s: got a transcription slot after %v", p.Name(), time.Since(start).Round(time.Millisecond))
+		case <-timer.C:
+			return nil, ErrBusy
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	defer func() { <-sem }()
+
+	return p.Provider.Transcribe(ctx, audioPath)
+}
@@ -0,0 +1,67 @@
+package stt
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Metrics receives STT instrumentation; the default implementation does nothing
+// so this package does not depend on any metrics backend.
+type Metrics interface {
+	ObserveTranscription(provider string, duration time.Duration, err error)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveTranscription(string, time.Duration, error) {}
+
+var (
+	metrics   Metrics = noopMetrics{}
+	muMetrics sync.RWMutex
+)
+
+// SetMetrics installs the STT metrics sink
+func SetMetrics(m Metrics) {
+	muMetrics.Lock()
+	defer muMetrics.Unlock()
+	if m == nil {
+		m = noopMetrics{}
+	}
+	metrics = m
+}
+
+func currentMetrics() Metrics {
+	muMetrics.RLock()
+	defer muMetrics.RUnlock()
+	return metrics
+}
+
+var tracer = otel.Tracer("noteme/internal/stt")
+
+// instrumentedProvider traces every Transcribe call and reports its duration and outcome
+type instrumentedProvider struct {
+	Provider
+}
+
+func (p instrumentedProvider) Transcribe(ctx context.Context, audioPath string) (*Result, error) {
+	ctx, span := tracer.Start(ctx, "stt.Transcribe", trace.WithAttributes(attribute.String("stt.provider", p.Name())))
+	defer span.End()
+
+	start := time.Now()
+	result, err := p.Provider.Transcribe(ctx, audioPath)
+	currentMetrics().ObserveTranscription(p.Name(), time.Since(start), err)
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetAttributes(attribute.Float64("stt.confidence", result.Confidence))
+	}
+	return result, err
+}
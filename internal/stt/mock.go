@@ -0,0 +1,133 @@
+package stt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"noteme/internal/config"
+	"os"
+	"strings"
+	"time"
+)
+
+// MockFixture is the canned result of MockProvider, read from MOCK_STT_FIXTURE when set
+type MockFixture struct {
+	Transcript string  `json:"transcript"`
+	Confidence float64 `json:"confidence"` // 0-1, or -1 for an unscored transcript
+	Language   string  `json:"language"`   // e.g. "vi-VN", empty uses STT_LANGUAGE
+}
+
+// defaultMockFixture is used when MOCK_STT_FIXTURE is not set
+var defaultMockFixture = MockFixture{
+	Transcript: "Xin chào, hôm nay chúng ta họp về kế hoạch ra mắt NoteMe. Anh Minh cần hoàn thành API upload trước thứ sáu. " +
+		"Chị Lan sẽ kiểm tra giao diện trên iPhone. Cuối tuần này cả nhóm chốt danh sách tính năng cho bản beta.",
+	Confidence: 0.95,
+	Language:   LanguageVietnamese,
+}
+
+// MockProvider returns the same transcript for every file without calling any API.
+// Select it with STT_PROVIDER=mock for local development and handler tests.
+type MockProvider struct {
+	fixture MockFixture
+}
+
+// NewMockProvider creates a MockProvider returning fixture
+func NewMockProvider(fixture MockFixture) *MockProvider {
+	return &MockProvider{fixture: fixture}
+}
+
+// Name returns the provider name
+func (p *MockProvider) Name() string {
+	return "mock"
+}
+
+// Transcribe returns the fixture for any existing file
+func (p *MockProvider) Transcribe(ctx context.Context, audioPath string) (*Result, error) {
+	if _, err := os.Stat(audioPath); err != nil {
+		return nil, fmt.Errorf("failed to read audio file: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("[Mock STT] Returning fixture transcript for %s", audioPath)
+	return p.fixtureResult(p.fixture), nil
+}
+
+// mockCallbackDelay is how long the mock "works" on an async job before calling back
+const mockCallbackDelay = time.Second
+
+// Submit posts the fixture to callbackURL shortly after, like a real provider finishing a job.
+// It lets the async callback flow be exercised offline (STT_ASYNC_PROVIDERS=mock).
+func (p *MockProvider) Submit(ctx context.Context, audioPath, callbackURL string) (string, error) {
+	if _, err := os.Stat(audioPath); err != nil {
+		return "", fmt.Errorf("failed to read audio file: %w", err)
+	}
+	body, err := json.Marshal(p.fixture)
+	if err != nil {
+		return "", err
+	}
+
+	jobID := fmt.Sprintf("mock_%d", time.Now().UnixNano())
+	go func() {
+		time.Sleep(mockCallbackDelay)
+		client := &http.Client{Timeout: DefaultHTTPTimeout}
+		resp, err := client.Post(callbackURL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			log.Printf("[Mock STT] Callback for job %s failed: %v", jobID, err)
+			return
+		}
+		resp.Body.Close()
+		log.Printf("[Mock STT] Called back job %s: status %d", jobID, resp.StatusCode)
+	}()
+	return jobID, nil
+}
+
+// ParseCallback parses the MockFixture JSON posted by Submit
+func (p *MockProvider) ParseCallback(body []byte) (*Result, error) {
+	var fixture MockFixture
+	if err := json.Unmarshal(body, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid mock callback body: %w", err)
+	}
+	return p.fixtureResult(fixture), nil
+}
+
+func (p *MockProvider) fixtureResult(fixture MockFixture) *Result {
+	confidence, _ := NormalizeConfidence(p.Name(), fixture.Confidence)
+	return &Result{
+		Transcript:    fixture.Transcript,
+		Confidence:    confidence,
+		RawConfidence: fixture.Confidence,
+		Provider:      p.Name(),
+		Language:      fixture.Language,
+		Duration:      time.Millisecond,
+	}
+}
+
+// createMockProvider creates a MockProvider from MOCK_STT_FIXTURE (a JSON MockFixture file),
+// or the built-in fixture when it is not set
+func createMockProvider(cfg *config.Config) (Provider, error) {
+	path := cfg.MockSTTFixture
+	if path == "" {
+		log.Printf("[STT Factory] Creating mock STT provider with the built-in fixture")
+		return NewMockProvider(defaultMockFixture), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MOCK_STT_FIXTURE %q: %w", path, err)
+	}
+	var fixture MockFixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid MOCK_STT_FIXTURE %q: %w", path, err)
+	}
+	if strings.TrimSpace(fixture.Transcript) == "" {
+		return nil, fmt.Errorf("MOCK_STT_FIXTURE %q has an empty transcript", path)
+	}
+
+	log.Printf("[STT Factory] Creating mock STT provider with fixture %s", path)
+	return NewMockProvider(fixture), nil
+}
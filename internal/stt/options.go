@@ -0,0 +1,26 @@
+package stt
+
+import "context"
+
+// Options are per-request transcription settings. They travel in the context so the
+// Provider interface and its wrappers (instrumented, best-of) stay unchanged;
+// providers ignore options they do not support.
+type Options struct {
+	Diarization  bool // label words by speaker (Google only, billed higher)
+	SpeakerCount int  // expected number of speakers for diarization, 0 = let the provider decide
+
+	FilterProfanity bool // ask the provider to mask profanity (Google only, see Result.ProfanityMasked)
+}
+
+type optionsKey struct{}
+
+// WithOptions returns a context carrying transcription options
+func WithOptions(ctx context.Context, opts Options) context.Context {
+	return context.WithValue(ctx, optionsKey{}, opts)
+}
+
+// OptionsFromContext returns the transcription options in ctx (zero value if none)
+func OptionsFromContext(ctx context.Context) Options {
+	opts, _ := ctx.Value(optionsKey{}).(Options)
+	return opts
+}
@@ -0,0 +1,74 @@
+package stt
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// ParallelProvider runs several providers concurrently and returns the successful
+// result with the highest confidence. It fails only if every provider fails.
+type ParallelProvider struct {
+	providers []Provider
+}
+
+// NewParallelProvider creates a best-of provider over providers
+func NewParallelProvider(providers ...Provider) *ParallelProvider {
+	return &ParallelProvider{providers: providers}
+}
+
+// Name returns "best:" followed by the wrapped provider names (e.g. "best:fpt,google")
+func (p *ParallelProvider) Name() string {
+	names := make([]string, len(p.providers))
+	for i, provider := range p.providers {
+		names[i] = provider.Name()
+	}
+	return "best:" + strings.Join(names, ",")
+}
+
+type parallelOutcome struct {
+	provider string
+	result   *Result
+	err      error
+}
+
+// Transcribe fans out to all providers and waits for each to finish or for ctx to expire.
+// Results that arrive before the deadline are still considered if others time out.
+func (p *ParallelProvider) Transcribe(ctx context.Context, audioPath string) (*Result, error) {
+	outcomes := make(chan parallelOutcome, len(p.providers))
+	for _, provider := range p.providers {
+		go func(provider Provider) {
+			result, err := provider.Transcribe(ctx, audioPath)
+			outcomes <- parallelOutcome{provider: provider.Name(), result: result, err: err}
+		}(provider)
+	}
+
+	var best *Result
+	var errs []string
+	for received := 0; received < len(p.providers); received++ {
+		select {
+		case outcome := <-outcomes:
+			if outcome.err != nil {
+				log.Printf("[Parallel STT] %s failed: %v", outcome.provider, outcome.err)
+				errs = append(errs, fmt.Sprintf("%s: %v", outcome.provider, outcome.err))
+				continue
+			}
+			log.Printf("[Parallel STT] %s succeeded: confidence=%.2f, length=%d",
+				outcome.provider, outcome.result.Confidence, len(outcome.result.Transcript))
+			if best == nil || outcome.result.Confidence > best.Confidence {
+				best = outcome.result
+			}
+		case <-ctx.Done():
+			errs = append(errs, fmt.Sprintf("%d provider(s) did not finish: %v", len(p.providers)-received, ctx.Err()))
+			received = len(p.providers) // stop waiting
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("all STT providers failed: %s", strings.Join(errs, "; "))
+	}
+
+	log.Printf("[Parallel STT] Selected %s (confidence=%.2f)", best.Provider, best.Confidence)
+	return best, nil
+}
@@ -1,9 +1,18 @@
 package stt
 
+import "time"
+
 // Result represents the result of a speech-to-text transcription
 type Result struct {
-	Transcript  string  // The transcribed text
-	Confidence  float64 // Confidence score (0.0-1.0), may be 0 if not provided
-	Provider    string  // The provider used (e.g., "fpt", "google")
-	RawResponse string  // Raw response from the provider (for debugging/logging)
+	Transcript    string        // The transcribed text
+	Confidence    float64       // Normalized confidence (0.0-1.0), or ConfidenceUnavailable (-1) if not scored
+	RawConfidence float64       // Confidence as reported by the provider, on its own scale (see NormalizeConfidence)
+	Provider      string        // The provider used (e.g., "fpt", "google")
+	RawResponse   string        // Raw response from the provider (for debugging/logging)
+	Duration      time.Duration // Time spent transcribing, including conversion and retries
+	Language      string        // Language detected/used by the provider (e.g., "vi-VN"), empty if unknown
+	Segments      []Segment     // Speaker-labeled segments when diarization was requested, nil otherwise
+	Words         []Word        // Word timings when the provider reports them (Google), nil otherwise
+
+	ProfanityMasked bool // the provider masked profanity itself (Google), so Transcript is not the raw text
 }
@@ -0,0 +1,104 @@
+package stt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 10 * time.Second
+)
+
+// doWithRetry sends the request built by newRequest, retrying network errors, 429 and 5xx
+// with exponential backoff and jitter (honoring Retry-After). Other statuses, including
+// non-retryable 4xx, are returned immediately for the caller to handle.
+// newRequest is called for every attempt so the body can be re-sent.
+func doWithRetry(ctx context.Context, client *http.Client, logPrefix string, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	maxAttempts := retryMaxAttempts()
+
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		resp, err := client.Do(req)
+		if err == nil && !isRetryableStatus(resp.StatusCode) {
+			return resp, nil
+		}
+		if err != nil && ctx.Err() != nil {
+			// Deadline exceeded or caller went away: retrying cannot help
+			return nil, err
+		}
+		if attempt >= maxAttempts {
+			return resp, err
+		}
+
+		delay := backoffDelay(attempt)
+		if err != nil {
+			log.Printf("%s Attempt %d/%d failed: %v, retrying in %v", logPrefix, attempt, maxAttempts, err, delay)
+		} else {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = retryAfter
+			}
+			log.Printf("%s Attempt %d/%d returned status %d, retrying in %v", logPrefix, attempt, maxAttempts, resp.StatusCode, delay)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// isRetryableStatus reports whether a provider status is transient
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// backoffDelay returns base*2^(attempt-1) capped at retryMaxDelay, with ±25% jitter
+func backoffDelay(attempt int) time.Duration {
+	delay := retryBaseDelay << (attempt - 1)
+	if delay > retryMaxDelay || delay <= 0 {
+		delay = retryMaxDelay
+	}
+	jitter := time.Duration(rand.Int63n(int64(delay) / 2))
+	return delay - delay/4 + jitter
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if t, err := http.ParseTime(value); err == nil {
+		delay = time.Until(t)
+	} else {
+		return 0, false
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	return delay, true
+}
+
+// retryMaxAttempts returns STT_RETRY_MAX_ATTEMPTS (default 3, 1 disables retries)
+func retryMaxAttempts() int {
+	return conf.STTRetryMaxAttempts
+}
@@ -0,0 +1,23 @@
+package stt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Segment is a run of consecutive words spoken by the same speaker
+type Segment struct {
+	Speaker   int     `json:"speaker"` // 1-based speaker tag from diarization
+	Text      string  `json:"text"`
+	StartTime float64 `json:"start_time"` // seconds from the start of the audio
+	EndTime   float64 `json:"end_time"`
+}
+
+// LabeledTranscript renders segments as "Speaker N: text" lines
+func LabeledTranscript(segments []Segment) string {
+	lines := make([]string, 0, len(segments))
+	for _, seg := range segments {
+		lines = append(lines, fmt.Sprintf("Speaker %d: %s", seg.Speaker, seg.Text))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,74 @@
+package stt
+
+import (
+	"strings"
+)
+
+const (
+	// sentencePauseSeconds is the silence between words that also ends a sentence
+	sentencePauseSeconds = 0.8
+	// maxSentenceWords splits run-on speech without punctuation into highlightable chunks
+	maxSentenceWords = 40
+)
+
+// Word is a recognized word with its position in the audio
+type Word struct {
+	Text      string  `json:"text"`
+	StartTime float64 `json:"start_time"` // seconds from the start of the audio
+	EndTime   float64 `json:"end_time"`
+}
+
+// Sentence is a sentence of the transcript with its position in the audio, for highlighting
+// text during playback
+type Sentence struct {
+	Text      string  `json:"text"`
+	StartTime float64 `json:"start_time"` // seconds from the start of the audio
+	EndTime   float64 `json:"end_time"`
+}
+
+// GroupSentences groups timed words into sentences. A sentence ends after a word ending in
+// . ? ! or …, before a pause of sentencePauseSeconds, or after maxSentenceWords words.
+func GroupSentences(words []Word) []Sentence {
+	var sentences []Sentence
+	var text []string
+	for i, w := range words {
+		word := strings.TrimSpace(w.Text)
+		if word == "" {
+			continue
+		}
+		if len(text) == 0 {
+			sentences = append(sentences, Sentence{StartTime: w.StartTime})
+		}
+		text = append(text, word)
+		sentences[len(sentences)-1].EndTime = w.EndTime
+
+		end := endsSentence(word) || len(text) == maxSentenceWords
+		if i+1 < len(words) && words[i+1].StartTime-w.EndTime >= sentencePauseSeconds {
+			end = true
+		}
+		if end {
+			sentences[len(sentences)-1].Text = strings.Join(text, " ")
+			text = text[:0]
+		}
+	}
+	if len(text) > 0 {
+		sentences[len(sentences)-1].Text = strings.Join(text, " ")
+	}
+	return sentences
+}
+
+// WholeTranscript is the fallback for providers without word timings: the transcript as one
+// sentence spanning the audio. It returns nil for an empty transcript.
+func WholeTranscript(transcript string, durationSeconds float64) []Sentence {
+	transcript = strings.TrimSpace(transcript)
+	if transcript == "" {
+		return nil
+	}
+	return []Sentence{{Text: transcript, StartTime: 0, EndTime: durationSeconds}}
+}
+
+func endsSentence(word string) bool {
+	word = strings.TrimRight(word, `"')]»”’`)
+	return strings.HasSuffix(word, ".") || strings.HasSuffix(word, "?") ||
+		strings.HasSuffix(word, "!") || strings.HasSuffix(word, "…")
+}
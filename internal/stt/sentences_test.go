@@ -0,0 +1,96 @@
+package stt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupSentences(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []Word
+		want  []Sentence
+	}{
+		{
+			name: "punctuation ends sentences",
+			words: []Word{
+				{"Xin", 0, 0.3}, {"chào.", 0.3, 0.6},
+				{"Hôm", 0.7, 0.9}, {"nay", 0.9, 1.1}, {"họp?", 1.1, 1.4},
+				{"Được!", 1.5, 1.8},
+			},
+			want: []Sentence{
+				{"Xin chào.", 0, 0.6},
+				{"Hôm nay họp?", 0.7, 1.4},
+				{"Được!", 1.5, 1.8},
+			},
+		},
+		{
+			name:  "punctuation before a closing quote or ellipsis",
+			words: []Word{{"Anh", 0, 0.2}, {"nói", 0.2, 0.4}, {`"xong."`, 0.4, 0.8}, {"Vậy…", 0.9, 1.2}, {"thôi", 1.3, 1.5}},
+			want: []Sentence{
+				{`Anh nói "xong."`, 0, 0.8},
+				{"Vậy…", 0.9, 1.2},
+				{"thôi", 1.3, 1.5},
+			},
+		},
+		{
+			name:  "pause ends a sentence without punctuation",
+			words: []Word{{"một", 0, 0.4}, {"hai", 0.5, 0.9}, {"ba", 1.8, 2.0}, {"bốn", 2.1, 2.4}},
+			want: []Sentence{
+				{"một hai", 0, 0.9},
+				{"ba bốn", 1.8, 2.4},
+			},
+		},
+		{
+			name:  "gap just below the pause keeps the sentence",
+			words: []Word{{"một", 0, 0.4}, {"hai", 1.19, 1.5}},
+			want:  []Sentence{{"một hai", 0, 1.5}},
+		},
+		{
+			name:  "empty and blank words are skipped",
+			words: []Word{{"", 0, 0.1}, {"xin", 0.1, 0.4}, {"  ", 0.4, 0.5}, {"chào.", 0.5, 0.8}, {"", 0.8, 0.9}},
+			want:  []Sentence{{"xin chào.", 0.1, 0.8}},
+		},
+		{
+			name:  "no words",
+			words: nil,
+			want:  nil,
+		},
+		{
+			name:  "only empty words",
+			words: []Word{{"", 0, 0.1}, {" ", 0.1, 0.2}},
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GroupSentences(tt.words); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GroupSentences() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupSentencesSplitsRunOnSpeech(t *testing.T) {
+	words := make([]Word, maxSentenceWords+5)
+	for i := range words {
+		words[i] = Word{Text: "từ", StartTime: float64(i) * 0.3, EndTime: float64(i)*0.3 + 0.25}
+	}
+	got := GroupSentences(words)
+	if len(got) != 2 {
+		t.Fatalf("GroupSentences of %d words without punctuation = %d sentences, want 2", len(words), len(got))
+	}
+	if got[1].StartTime != words[maxSentenceWords].StartTime {
+		t.Errorf("second sentence starts at %v, want %v", got[1].StartTime, words[maxSentenceWords].StartTime)
+	}
+}
+
+func TestWholeTranscript(t *testing.T) {
+	if got := WholeTranscript("  Xin chào.  ", 12.5); !reflect.DeepEqual(got, []Sentence{{"Xin chào.", 0, 12.5}}) {
+		t.Errorf("WholeTranscript() = %+v", got)
+	}
+	if got := WholeTranscript("   ", 3); got != nil {
+		t.Errorf("WholeTranscript of a blank transcript = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,200 @@
+package stt
+
+import (
+	"context"
+	"net/http"
+	"noteme/internal/config"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// connectivityTimeout bounds the reachability probe of each provider
+	connectivityTimeout = 3 * time.Second
+	// probeCacheTTL is how long a probe result is reused, so a dashboard polling the provider list
+	// does not send a HEAD request to every provider each time
+	probeCacheTTL = time.Minute
+)
+
+// ProviderStatus describes whether a provider is configured and reachable.
+// It never contains secret values.
+type ProviderStatus struct {
+	Name        string          `json:"name"`
+	Active      bool            `json:"active"`     // selected by STT_PROVIDER
+	Configured  bool            `json:"configured"` // all required env vars present
+	Env         map[string]bool `json:"env"`        // env var name -> present
+	Reachable   *bool           `json:"reachable,omitempty"`
+	CheckedAt   *time.Time      `json:"checked_at,omitempty"` // when reachability was probed (cached for a minute)
+	CheckError  string          `json:"check_error,omitempty"`
+	AuthMode    string          `json:"auth_mode,omitempty"`
+	ConfigError string          `json:"config_error,omitempty"`
+}
+
+// providerSpec is what the factory needs to know about a provider.
+// requiredEnv and optionalEnv map env var names to whether the setting is present in the config.
+type providerSpec struct {
+	requiredEnv func(cfg *config.Config) map[string]bool
+	optionalEnv func(cfg *config.Config) map[string]bool
+	endpoint    func(cfg *config.Config) string // base URL used for the connectivity probe, nil for offline providers
+}
+
+var providerSpecs = map[string]providerSpec{
+	"fpt": {
+		requiredEnv: func(cfg *config.Config) map[string]bool {
+			return map[string]bool{"FPT_AI_API_KEY": cfg.FPTApiKey != ""}
+		},
+		optionalEnv: func(cfg *config.Config) map[string]bool {
+			return map[string]bool{
+				"FPT_AI_STT_URL":       cfg.FPTSTTURL != config.Default().FPTSTTURL,
+				"FPT_STT_HTTP_TIMEOUT": cfg.FPTSTTHTTPTimeout > 0,
+			}
+		},
+		endpoint: func(cfg *config.Config) string { return cfg.FPTSTTURL },
+	},
+	"google": {
+		requiredEnv: func(cfg *config.Config) map[string]bool {
+			return map[string]bool{"GOOGLE_STT_KEY_FILE": cfg.GoogleSTTKeyFile != ""}
+		},
+		optionalEnv: func(cfg *config.Config) map[string]bool {
+			return map[string]bool{
+				"GOOGLE_STT_PROJECT_ID":   cfg.GoogleSTTProjectID != "",
+				"GOOGLE_STT_AUTH_MODE":    cfg.GoogleSTTAuthMode != "",
+				"GOOGLE_STT_HTTP_TIMEOUT": cfg.GoogleSTTHTTPTimeout > 0,
+			}
+		},
+		endpoint: func(*config.Config) string { return "https://speech.googleapis.com/" },
+	},
+	"mock": {
+		requiredEnv: func(*config.Config) map[string]bool { return nil },
+		optionalEnv: func(cfg *config.Config) map[string]bool {
+			return map[string]bool{"MOCK_STT_FIXTURE": cfg.MockSTTFixture != ""}
+		},
+	},
+}
+
+// SupportedProviders lists the provider names known to the factory
+func SupportedProviders() []string {
+	return []string{"fpt", "google", "mock"}
+}
+
+// ProviderStatuses reports configuration (and optionally reachability) of every known provider
+func ProviderStatuses(cfg *config.Config, checkConnectivity bool) []ProviderStatus {
+	names := SupportedProviders()
+	statuses := make([]ProviderStatus, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		statuses[i] = providerConfigStatus(cfg, name)
+		if !checkConnectivity || providerSpecs[name].endpoint == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(status *ProviderStatus) {
+			defer wg.Done()
+			probe := cachedProbe(providerSpecs[status.Name].endpoint(cfg))
+			status.Reachable = &probe.reachable
+			status.CheckedAt = &probe.checkedAt
+			if probe.err != nil {
+				status.CheckError = probe.err.Error()
+			}
+		}(&statuses[i])
+	}
+	wg.Wait()
+
+	return statuses
+}
+
+// isActiveProvider reports whether name is selected by STT_PROVIDER, including best-of lists
+func isActiveProvider(name, active string) bool {
+	if names, ok := strings.CutPrefix(active, "best:"); ok {
+		for _, n := range strings.Split(names, ",") {
+			if strings.TrimSpace(n) == name {
+				return true
+			}
+		}
+		return false
+	}
+	return name == active
+}
+
+// providerConfigStatus checks env requirements without exposing values
+func providerConfigStatus(cfg *config.Config, name string) ProviderStatus {
+	spec := providerSpecs[name]
+	status := ProviderStatus{
+		Name:       name,
+		Active:     isActiveProvider(name, cfg.STTProvider),
+		Configured: true,
+		Env:        make(map[string]bool),
+	}
+
+	for key, present := range spec.requiredEnv(cfg) {
+		status.Env[key] = present
+		if !present {
+			status.Configured = false
+		}
+	}
+	for key, present := range spec.optionalEnv(cfg) {
+		status.Env[key] = present
+	}
+
+	if name == "google" {
+		mode, err := DetectGoogleAuthMode(cfg.GoogleSTTKeyFile, cfg.GoogleSTTAuthMode)
+		if err != nil {
+			status.Configured = false
+			status.ConfigError = err.Error()
+		} else {
+			status.AuthMode = mode
+			// ADC needs no key file
+			status.Configured = status.Configured || mode == GoogleAuthADC
+		}
+	}
+
+	return status
+}
+
+// probeResult is a cached outcome of probeEndpoint
+type probeResult struct {
+	reachable bool
+	err       error
+	checkedAt time.Time
+}
+
+var (
+	probeCache   = make(map[string]probeResult) // endpoint URL -> last probe
+	muProbeCache sync.Mutex
+)
+
+// cachedProbe returns the last probe of url when it is younger than probeCacheTTL, else probes again
+func cachedProbe(url string) probeResult {
+	muProbeCache.Lock()
+	cached, ok := probeCache[url]
+	muProbeCache.Unlock()
+	if ok && time.Since(cached.checkedAt) < probeCacheTTL {
+		return cached
+	}
+
+	reachable, err := probeEndpoint(url)
+	result := probeResult{reachable: reachable, err: err, checkedAt: time.Now()}
+	muProbeCache.Lock()
+	probeCache[url] = result
+	muProbeCache.Unlock()
+	return result
+}
+
+// probeEndpoint checks that the provider host answers HTTP at all (any status counts)
+func probeEndpoint(url string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectivityTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	return true, nil
+}
@@ -0,0 +1,47 @@
+package stt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"noteme/internal/audio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// convertedSampleRate is the sample rate of WAV files produced for providers
+const convertedSampleRate = 44100
+
+// prepareAudio returns a path the provider can read: the original file when its extension is in
+// nativeExts, else a WAV transcoded by ffmpeg. When ffmpeg is not installed, files whose extension is
+// in fallbackExts are still sent as-is. cleanup removes the converted file and is always safe to call.
+func prepareAudio(ctx context.Context, logPrefix string, audioPath string, nativeExts, fallbackExts map[string]bool) (string, func(), error) {
+	noop := func() {}
+	ext := strings.ToLower(filepath.Ext(audioPath))
+	if nativeExts[ext] {
+		return audioPath, noop, nil
+	}
+
+	log.Printf("%s Converting %s to WAV: %s", logPrefix, ext, audioPath)
+	converted, err := audio.ConvertToWAV(ctx, audioPath, convertedSampleRate)
+	if errors.Is(err, audio.ErrFFmpegUnavailable) && fallbackExts[ext] {
+		log.Printf("%s ffmpeg not available, sending %s without conversion: %s", logPrefix, ext, audioPath)
+		return audioPath, noop, nil
+	}
+	if errors.Is(err, audio.ErrFFmpegUnavailable) {
+		return "", noop, fmt.Errorf("%s audio cannot be sent without conversion: %w", ext, err)
+	}
+	if err != nil {
+		return "", noop, fmt.Errorf("failed to convert %s to WAV: %w", ext, err)
+	}
+	log.Printf("%s Conversion successful: %s", logPrefix, converted)
+
+	cleanup := func() {
+		if err := os.Remove(converted); err != nil {
+			log.Printf("%s Warning: failed to cleanup converted file %s: %v", logPrefix, converted, err)
+		}
+	}
+	return converted, cleanup, nil
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Responses use snake_case keys, matching the json tags of the models. Clients that
+// prefer camelCase can pass ?case=camel to have every key of the response converted.
+const (
+	caseParam = "case"
+	caseCamel = "camel"
+)
+
+// freeFormKeys hold user-owned maps (recording metadata, event data) whose keys are returned as
+// stored; renaming them would break clients that read back the keys they wrote
+var freeFormKeys = map[string]bool{
+	"metadata": true,
+	"data":     true,
+}
+
+// writeJSON writes body with snake_case keys, or camelCase ones when the request asked for them
+func writeJSON(c *gin.Context, status int, body gin.H) {
+	if c.Query(caseParam) != caseCamel {
+		c.JSON(status, body)
+		return
+	}
+
+	converted, err := camelCaseKeys(body)
+	if err != nil {
+		// Fall back to the canonical form rather than failing the request
+		c.JSON(status, body)
+		return
+	}
+	c.JSON(status, converted)
+}
+
+// camelCaseKeys round-trips v through JSON so struct tags are applied, then renames every object key
+// outside the free-form subtrees. The envelope's own "data" is the response payload and is converted.
+// Numbers are kept as json.Number so large integers are not rounded through float64.
+func camelCaseKeys(v interface{}) (interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	envelope, ok := generic.(map[string]interface{})
+	if !ok {
+		return renameKeys(generic), nil
+	}
+	out := make(map[string]interface{}, len(envelope))
+	for k, item := range envelope {
+		out[snakeToCamel(k)] = renameKeys(item)
+	}
+	return out, nil
+}
+
+func renameKeys(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			if freeFormKeys[k] {
+				out[snakeToCamel(k)] = item
+				continue
+			}
+			out[snakeToCamel(k)] = renameKeys(item)
+		}
+		return out
+	case []interface{}:
+		for i, item := range val {
+			val[i] = renameKeys(item)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// snakeToCamel converts "recording_id" to "recordingId"; keys without underscores are unchanged
+func snakeToCamel(key string) string {
+	if !strings.Contains(key, "_") {
+		return key
+	}
+	parts := strings.Split(key, "_")
+	var b strings.Builder
+	b.WriteString(parts[0])
+	for _, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
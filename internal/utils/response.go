@@ -2,16 +2,64 @@ package utils
 
 import "github.com/gin-gonic/gin"
 
+// ErrorCode is a machine-readable error identifier returned alongside the message
+type ErrorCode string
+
+// Error codes returned in error.code
+const (
+	CodeInvalidRequest         ErrorCode = "INVALID_REQUEST"
+	CodeInvalidID              ErrorCode = "INVALID_ID"
+	CodeUnsupportedAudioFormat ErrorCode = "UNSUPPORTED_AUDIO_FORMAT"
+	CodeAudioTooLarge          ErrorCode = "AUDIO_TOO_LARGE"
+	CodePayloadTooLarge        ErrorCode = "PAYLOAD_TOO_LARGE"
+	CodeInvalidAudio           ErrorCode = "INVALID_AUDIO"
+	CodeNoSpeechDetected       ErrorCode = "NO_SPEECH_DETECTED"
+	CodeRecordingNotFound      ErrorCode = "RECORDING_NOT_FOUND"
+	CodeAudioDeleted           ErrorCode = "AUDIO_DELETED"
+	CodeSTTRequestNotFound     ErrorCode = "STT_REQUEST_NOT_FOUND"
+	CodeAnalysisNotFound       ErrorCode = "ANALYSIS_NOT_FOUND"
+	CodeUploadNotFound         ErrorCode = "UPLOAD_NOT_FOUND"
+	CodeUploadOffsetMismatch   ErrorCode = "UPLOAD_OFFSET_MISMATCH"
+	CodeUploadIncomplete       ErrorCode = "UPLOAD_INCOMPLETE"
+	CodeNotFound               ErrorCode = "NOT_FOUND"
+	CodeAlreadyProcessing      ErrorCode = "ALREADY_PROCESSING"
+	CodeAlreadyExists          ErrorCode = "ALREADY_EXISTS"
+	CodeGlossaryFull           ErrorCode = "GLOSSARY_FULL"
+	CodeSTTProviderUnavailable ErrorCode = "STT_PROVIDER_UNAVAILABLE"
+	CodeSTTFailed              ErrorCode = "STT_FAILED"
+	CodeTranscriptNotAvailable ErrorCode = "TRANSCRIPT_NOT_AVAILABLE"
+	CodeLowConfidence          ErrorCode = "LOW_CONFIDENCE"
+	CodeNoAnalysisData         ErrorCode = "NO_ANALYSIS_DATA"
+	CodeAIFailed               ErrorCode = "AI_FAILED"
+	CodeAITimeout              ErrorCode = "AI_TIMEOUT"
+	CodeAINotConfigured        ErrorCode = "AI_NOT_CONFIGURED"
+	CodeRateLimited            ErrorCode = "RATE_LIMITED"
+	CodeUnauthorized           ErrorCode = "UNAUTHORIZED"
+	CodeForbidden              ErrorCode = "FORBIDDEN"
+	CodeInternal               ErrorCode = "INTERNAL_ERROR"
+)
+
 func Success(c *gin.Context, data gin.H) {
-	c.JSON(200, gin.H{
+	writeJSON(c, 200, gin.H{
 		"success": true,
 		"data":    data,
 	})
 }
 
-func Error(c *gin.Context, code int, msg string) {
-	c.JSON(code, gin.H{
+// Error writes {success:false, error:{code, message}} with the given HTTP status
+func Error(c *gin.Context, status int, code ErrorCode, msg string) {
+	writeJSON(c, status, gin.H{
 		"success": false,
-		"error":   msg,
+		"error": gin.H{
+			"code":    code,
+			"message": msg,
+		},
+	})
+}
+
+func SuccessWithStatus(c *gin.Context, code int, data gin.H) {
+	writeJSON(c, code, gin.H{
+		"success": true,
+		"data":    data,
 	})
 }
@@ -0,0 +1,3 @@
+-- Index tags stored in metadata for ?tag= filtering (JSONB containment @>)
+CREATE INDEX IF NOT EXISTS idx_stt_metadata_tags
+ON stt_requests USING GIN ((metadata->'tags'));
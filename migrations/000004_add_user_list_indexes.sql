@@ -0,0 +1,12 @@
+-- Composite index for history lists filtered by an explicit status, e.g. ?status=success:
+--   WHERE user_id = $1 AND status = $2 ORDER BY created_at DESC LIMIT n
+-- Expected plan: Index Scan using idx_stt_requests_user_created (no Sort node)
+CREATE INDEX IF NOT EXISTS idx_stt_requests_user_created
+ON stt_requests (user_id, status, created_at DESC);
+
+-- Partial index for the default soft-delete predicate used by ListByUser / Search:
+--   WHERE user_id = $1 AND status != 'deleted' ORDER BY created_at DESC LIMIT n
+-- Expected plan: Index Scan using idx_stt_requests_user_active (no Sort node)
+CREATE INDEX IF NOT EXISTS idx_stt_requests_user_active
+ON stt_requests (user_id, created_at DESC)
+WHERE status != 'deleted';
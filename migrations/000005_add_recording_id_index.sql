@@ -0,0 +1,20 @@
+-- One live row per in-memory recording ID, so syncToDatabase stays idempotent across restarts.
+-- Older versions could create duplicates after a restart; keep the newest live row and soft-delete the rest.
+UPDATE stt_requests
+SET status = 'deleted'
+WHERE id IN (
+  SELECT id FROM (
+    SELECT id, ROW_NUMBER() OVER (
+      PARTITION BY metadata->>'recording_id'
+      ORDER BY created_at DESC, id
+    ) AS rn
+    FROM stt_requests
+    WHERE status != 'deleted' AND metadata ? 'recording_id'
+  ) ranked
+  WHERE rn > 1
+);
+
+-- Soft-delete-aware unique index: deleted rows don't block re-syncing the same recording
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stt_requests_recording_id
+ON stt_requests ((metadata->>'recording_id'))
+WHERE status != 'deleted' AND metadata ? 'recording_id';
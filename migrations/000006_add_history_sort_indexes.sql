@@ -0,0 +1,15 @@
+-- Partial indexes for the history sort options (?sort=duration|confidence|title), e.g.:
+--   WHERE user_id = $1 AND status != 'deleted' ORDER BY confidence DESC NULLS LAST, created_at DESC LIMIT n
+-- Expected plan: Index Scan (or Incremental Sort on the created_at tie-breaker) instead of a full Sort.
+-- Each index matches the usual direction: longest / most confident first, titles A-Z.
+CREATE INDEX IF NOT EXISTS idx_stt_requests_user_duration
+ON stt_requests (user_id, audio_duration_ms DESC NULLS LAST, created_at DESC)
+WHERE status != 'deleted';
+
+CREATE INDEX IF NOT EXISTS idx_stt_requests_user_confidence
+ON stt_requests (user_id, confidence DESC NULLS LAST, created_at DESC)
+WHERE status != 'deleted';
+
+CREATE INDEX IF NOT EXISTS idx_stt_requests_user_title
+ON stt_requests (user_id, title ASC NULLS LAST, created_at DESC)
+WHERE status != 'deleted';
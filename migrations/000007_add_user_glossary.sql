@@ -0,0 +1,19 @@
+-- Per-user glossary fed into the transcript cleaning prompt (GET/POST/PUT/DELETE /api/v1/glossary).
+-- A row is either a preserved term (wrong IS NULL: keep "term" as spelled) or a correction
+-- of a known misrecognition ("wrong" -> "term").
+CREATE TABLE IF NOT EXISTS user_glossary (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id UUID NOT NULL,
+  term TEXT NOT NULL,
+  wrong TEXT,
+  created_at TIMESTAMPTZ DEFAULT now()
+);
+
+-- One preserved term and one correction per spelling, case-insensitively; also serves the per-user listing
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_glossary_term
+ON user_glossary (user_id, lower(term))
+WHERE wrong IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_glossary_wrong
+ON user_glossary (user_id, lower(wrong))
+WHERE wrong IS NOT NULL;
@@ -0,0 +1,10 @@
+-- Upload dedupe lookups by the keys syncToDatabase stores in metadata, scoped per user, e.g.:
+--   WHERE user_id = $1 AND metadata->>'idempotency_key' = $2 AND status != 'deleted'
+-- They let an Idempotency-Key retry (or ?dedupe=true) find the original recording after a restart.
+CREATE INDEX IF NOT EXISTS idx_stt_requests_user_idempotency_key
+ON stt_requests (user_id, (metadata->>'idempotency_key'))
+WHERE status != 'deleted' AND metadata ? 'idempotency_key';
+
+CREATE INDEX IF NOT EXISTS idx_stt_requests_user_content_sha256
+ON stt_requests (user_id, (metadata->>'content_sha256'))
+WHERE status != 'deleted' AND metadata ? 'content_sha256';
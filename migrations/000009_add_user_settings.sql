@@ -0,0 +1,23 @@
+-- Per-user settings that outlive the user's recordings. retention_days overrides RETENTION_DAYS
+-- for the user (0 = keep forever); a user without a row uses the default.
+CREATE TABLE IF NOT EXISTS user_settings (
+  user_id UUID PRIMARY KEY,
+  retention_days INT CHECK (retention_days >= 0),
+  updated_at TIMESTAMPTZ DEFAULT now()
+);
+
+-- Carry over the overrides older versions stored in metadata.retention_days of the user's rows
+INSERT INTO user_settings (user_id, retention_days)
+SELECT user_id, MAX((metadata->>'retention_days')::int)
+FROM stt_requests
+WHERE metadata->>'retention_days' ~ '^[0-9]+$'
+GROUP BY user_id
+ON CONFLICT (user_id) DO NOTHING;
+
+UPDATE stt_requests
+SET metadata = metadata - 'retention_days'
+WHERE metadata ? 'retention_days';
+
+-- The retention job walks rows oldest first: ORDER BY created_at LIMIT n
+CREATE INDEX IF NOT EXISTS idx_stt_requests_created_at
+ON stt_requests (created_at);
@@ -0,0 +1,9 @@
+// Package migrations embeds the ordered SQL schema migrations applied by db.Migrate at startup
+package migrations
+
+import "embed"
+
+// FS holds the *.sql migrations; files are applied in lexical order of their version prefix
+//
+//go:embed *.sql
+var FS embed.FS